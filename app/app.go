@@ -92,6 +92,16 @@ func (a *App) Start(config config.Config) error {
 	return nil
 }
 
+//Done returns a channel that is closed once all finite inputs (e.g stdin) have no more jobs, nil if there is none.
+func (a *App) Done() <-chan struct{} {
+	return a.components.InputsDone()
+}
+
+//Err returns why jobs of finite inputs failed, nil if all were acknowledged. must be called after Done is closed.
+func (a *App) Err() error {
+	return a.components.InputsErr()
+}
+
 //Stop Stop all components gracefully, will stop in the following sequence
 // 		1- Input Components.
 // 		2- Pipelines.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/sherifabdlnaby/prism/app/config"
+	"github.com/sherifabdlnaby/prism/pkg/component/input"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
 	"github.com/sherifabdlnaby/prism/pkg/job"
 	"go.uber.org/zap"
@@ -290,3 +291,38 @@ func (m *Manager) InputsReceiveChans() []<-chan job.Input {
 	}
 	return chans
 }
+
+//InputsErr returns the first error of finite inputs, must be called after InputsDone is closed.
+func (m *Manager) InputsErr() error {
+	for _, in := range m.registry.inputs {
+		if finite, ok := in.Input.(input.Finite); ok && finite.Err() != nil {
+			return finite.Err()
+		}
+	}
+	return nil
+}
+
+//InputsDone returns a channel that is closed once all finite inputs (e.g stdin) are done, returns nil if there is no
+// finite inputs, so it blocks forever.
+func (m *Manager) InputsDone() <-chan struct{} {
+	finites := make([]input.Finite, 0)
+	for _, in := range m.registry.inputs {
+		if finite, ok := in.Input.(input.Finite); ok {
+			finites = append(finites, finite)
+		}
+	}
+
+	if len(finites) == 0 {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for _, finite := range finites {
+			<-finite.Done()
+		}
+		close(done)
+	}()
+
+	return done
+}
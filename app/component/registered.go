@@ -3,11 +3,24 @@ package component
 import (
 	dummyinput "github.com/sherifabdlnaby/prism/internal/input/dummy"
 	"github.com/sherifabdlnaby/prism/internal/input/http"
+	"github.com/sherifabdlnaby/prism/internal/input/stdin"
 	s3 "github.com/sherifabdlnaby/prism/internal/output/amazon-s3"
+	"github.com/sherifabdlnaby/prism/internal/output/archive"
 	"github.com/sherifabdlnaby/prism/internal/output/disk"
+	httpoutput "github.com/sherifabdlnaby/prism/internal/output/http"
 	"github.com/sherifabdlnaby/prism/internal/output/mysql"
+	"github.com/sherifabdlnaby/prism/internal/output/stdout"
+	"github.com/sherifabdlnaby/prism/internal/processor/barcode"
+	"github.com/sherifabdlnaby/prism/internal/processor/classifier"
+	"github.com/sherifabdlnaby/prism/internal/processor/colors"
 	dummyprocessor "github.com/sherifabdlnaby/prism/internal/processor/dummy"
+	"github.com/sherifabdlnaby/prism/internal/processor/goimage"
+	"github.com/sherifabdlnaby/prism/internal/processor/metadata"
 	"github.com/sherifabdlnaby/prism/internal/processor/nude"
+	"github.com/sherifabdlnaby/prism/internal/processor/phash"
+	"github.com/sherifabdlnaby/prism/internal/processor/quality"
+	"github.com/sherifabdlnaby/prism/internal/processor/script"
+	"github.com/sherifabdlnaby/prism/internal/processor/transform"
 	"github.com/sherifabdlnaby/prism/internal/processor/validator"
 	"github.com/sherifabdlnaby/prism/internal/processor/vips"
 	"github.com/sherifabdlnaby/prism/pkg/component"
@@ -18,11 +31,24 @@ var registered = map[string]func() component.Base{
 	"dummy_processor": dummyprocessor.NewComponent,
 	"dummy_input":     dummyinput.NewComponent,
 	"http":            http.NewComponent,
+	"stdin":           stdin.NewComponent,
 	"disk":            disk.NewComponent,
 	"s3":              s3.NewComponent,
 	"mysql":           mysql.NewComponent,
+	"http_output":     httpoutput.NewComponent,
+	"archive":         archive.NewComponent,
+	"stdout":          stdout.NewComponent,
 	"vips":            vips.NewComponent,
 	"validator":       validator.NewComponent,
 	"nude_detector":   nude.NewDetector,
 	"nude_censor":     nude.NewCensor,
+	"metadata":        metadata.NewComponent,
+	"phash":           phash.NewComponent,
+	"colors":          colors.NewComponent,
+	"goimage":         goimage.NewComponent,
+	"classifier":      classifier.NewComponent,
+	"transform":       transform.NewComponent,
+	"script":          script.NewComponent,
+	"barcode":         barcode.NewComponent,
+	"quality":         quality.NewComponent,
 }
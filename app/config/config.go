@@ -81,8 +81,9 @@ type Outputs struct {
 
 // Node is a single node in a pipeline
 type Node struct {
-	Async bool             `yaml:"async"`
-	Next  map[string]*Node `yaml:",inline"`
+	Async   bool             `yaml:"async"`
+	Collect string           `yaml:"collect"`
+	Next    map[string]*Node `yaml:",inline"`
 }
 
 // Pipelines used for YAML decoding
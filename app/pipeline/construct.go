@@ -82,6 +82,24 @@ func (p *pipeline) getNodeNexts(next map[string]*config.Node, registry component
 			return nil, err
 		}
 
+		// create the output node that receives collected outputs of this node's nexts
+		if n.Collect != "" {
+			if len(n.Next) == 0 {
+				return nil, fmt.Errorf("plugin [%s] has no nexts(s) to collect", name)
+			}
+
+			err = uniqueOutputs(name, n.Next, registry, make(map[string]bool))
+			if err != nil {
+				return nil, err
+			}
+
+			collect, err := p.createCollectNode(name, n.Collect, registry)
+			if err != nil {
+				return nil, err
+			}
+			currNode.SetCollect(collect)
+		}
+
 		// append to nodeNexts
 		nexts = append(nexts, node.Next{
 			Node:    currNode,
@@ -131,7 +149,7 @@ func (p *pipeline) createNode(ID node.ID, componentName string, async bool, regi
 		if nextsCount > 0 {
 			return nil, fmt.Errorf("plugin [%s] has nexts(s), output plugins must not have nexts(s)", ID)
 		}
-		Node = node.NewOutput(ID, Component, async, nexts, p.convertToAsync, jobChan, p.logger)
+		Node = node.NewOutput(ID, componentName, Component, async, nexts, p.convertToAsync, jobChan, p.logger)
 	case *component.Input:
 		return nil, fmt.Errorf("plugin [%s] is an input plugin", ID)
 	default:
@@ -149,6 +167,52 @@ func (p *pipeline) createNode(ID node.ID, componentName string, async bool, regi
 	return Node, nil
 }
 
+func (p *pipeline) createCollectNode(name, componentName string, registry component.Registry) (node.Next, error) {
+	if _, ok := registry.Component(componentName).(*component.Output); !ok {
+		return node.Next{}, fmt.Errorf("plugin [%s] collect [%s] must be an output plugin", name, componentName)
+	}
+
+	jobChan := make(chan job.Job)
+	collectNode, err := p.createNode(p.getUniqueNodeID(componentName), componentName, false, registry, nil, jobChan, 0)
+	if err != nil {
+		return node.Next{}, err
+	}
+
+	return node.Next{
+		Node:    collectNode,
+		JobChan: jobChan,
+	}, nil
+}
+
+//uniqueOutputs checks that outputs collected by a node are used once, as their Data is collected under their name.
+// outputs under a nested collecting node are collected by it, only its collect output is.
+func uniqueOutputs(name string, next map[string]*config.Node, registry component.Registry, seen map[string]bool) error {
+	for nextName, n := range next {
+		outputs := make([]string, 0, 2)
+		if _, ok := registry.Component(nextName).(*component.Output); ok {
+			outputs = append(outputs, nextName)
+		}
+		if n.Collect != "" {
+			outputs = append(outputs, n.Collect)
+		}
+
+		for _, output := range outputs {
+			if seen[output] {
+				return fmt.Errorf("plugin [%s] collects output [%s] more than once", name, output)
+			}
+			seen[output] = true
+		}
+
+		if n.Collect == "" {
+			err := uniqueOutputs(name, n.Next, registry, seen)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func evaluateAsync(async, forceSync bool) (bool, bool) {
 	if forceSync {
 		async = false
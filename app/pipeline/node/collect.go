@@ -0,0 +1,77 @@
+package node
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+)
+
+type collectorKey struct{}
+
+//collector gathers the Data of every output that acked under a collecting node.
+type collector struct {
+	mx      sync.Mutex
+	outputs map[string]interface{}
+}
+
+func newCollector() *collector {
+	return &collector{outputs: make(map[string]interface{})}
+}
+
+func (c *collector) add(name string, data payload.Data) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	// plain map so it can be evaluated by selectors, e.g `@{_outputs.thumbnail._location}`
+	c.outputs[name] = map[string]interface{}(data)
+}
+
+//collectorFrom returns the nearest collector in ctx, nil if there is none.
+func collectorFrom(ctx context.Context) *collector {
+	c, _ := ctx.Value(collectorKey{}).(*collector)
+	return c
+}
+
+//SetCollect sets an output node that receives a single combined Data record once all nexts of this node are done.
+func (n *Node) SetCollect(next Next) {
+	n.collect = &next
+}
+
+//processCollect process the job with a collector in its context, then sends collected outputs to the collect node.
+// outputs under an async node aren't collected as they respond before they are done.
+func (n *Node) processCollect(j job.Job) {
+	c := newCollector()
+	responseChan := make(chan response.Response, 1)
+
+	n.processPayload(job.Job{
+		Payload:      j.Payload,
+		Data:         j.Data,
+		Context:      context.WithValue(j.Context, collectorKey{}, c),
+		ResponseChan: responseChan,
+	})
+
+	Response := <-responseChan
+	if !Response.Ack {
+		j.ResponseChan <- Response
+		return
+	}
+
+	record := make(payload.Data, len(j.Data)+1)
+	for key := range j.Data {
+		record[key] = j.Data[key]
+	}
+	record[payload.OutputsField] = c.outputs
+
+	collectResponseChan := make(chan response.Response)
+	n.collect.JobChan <- job.Job{
+		Payload:      payload.Bytes{},
+		Data:         record,
+		Context:      j.Context,
+		ResponseChan: collectResponseChan,
+	}
+
+	j.ResponseChan <- <-collectResponseChan
+}
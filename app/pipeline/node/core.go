@@ -29,7 +29,7 @@ func newBase(id ID, core core, async bool, nexts []Next,
 //NewReadOnly Construct a new ReadOnly node
 func NewReadOnly(ID ID, processor *component.ProcessorReadOnly, async bool, nexts []Next,
 	createAsync createAsyncFunc, jobChan <-chan job.Job, logger zap.SugaredLogger) *Node {
-	core := &readOnly{processor: processor}
+	core := &readOnly{processor: processor, completer: completerOf(processor.ReadOnly)}
 	base := newBase(ID, core, async, nexts, createAsync, jobChan, &processor.Resource, logger)
 	core.Node = base
 	return core.Node
@@ -53,10 +53,10 @@ func NewReadWriteStream(ID ID, processor *component.ProcessorReadWriteStream, as
 	return core.Node
 }
 
-//NewOutput Construct a new Output Node
-func NewOutput(ID ID, out *component.Output, async bool, nexts []Next,
+//NewOutput Construct a new Output Node, name is the output's config name its Data is collected under.
+func NewOutput(ID ID, name string, out *component.Output, async bool, nexts []Next,
 	createAsync createAsyncFunc, jobChan <-chan job.Job, logger zap.SugaredLogger) *Node {
-	core := &output{output: out}
+	core := &output{output: out, name: name}
 	base := newBase(ID, core, async, nexts, createAsync, jobChan, &out.Resource, logger)
 	core.Node = base
 	return core.Node
@@ -18,6 +18,7 @@ type Node struct {
 	ID             ID
 	async          bool
 	nexts          []Next
+	collect        *Next
 	core           core
 	createAsyncJob createAsyncFunc
 	resource       *component.Resource
@@ -39,6 +40,13 @@ func (n *Node) Start() error {
 		}
 	}
 
+	if n.collect != nil {
+		err := n.collect.Start()
+		if err != nil {
+			return err
+		}
+	}
+
 	go n.serve()
 
 	return nil
@@ -60,6 +68,14 @@ func (n *Node) Stop() error {
 		}
 	}
 
+	if n.collect != nil {
+		close(n.collect.JobChan)
+		err := n.collect.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -96,8 +112,18 @@ func (n *Node) HandleJob(j job.Job) {
 	n.activeJobs.Done()
 }
 
-// process process according to its type stream/bytes
+// process process job, and collect its nexts outputs if this node has a collect node.
 func (n *Node) process(j job.Job) {
+	if n.collect != nil {
+		n.processCollect(j)
+		return
+	}
+
+	n.processPayload(j)
+}
+
+// processPayload process according to its type stream/bytes
+func (n *Node) processPayload(j job.Job) {
 	// Start Job according to process payload core
 	switch j.Payload.(type) {
 	case payload.Bytes:
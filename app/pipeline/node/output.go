@@ -9,6 +9,7 @@ import (
 //output Wraps an output core
 type output struct {
 	output *component.Output
+	name   string
 	*Node
 }
 
@@ -29,7 +30,12 @@ func (n *output) process(j job.Job) {
 		Context:      j.Context,
 	}
 
-	j.ResponseChan <- <-responseChan
+	Response := <-responseChan
+	if c := collectorFrom(j.Context); c != nil && Response.Ack {
+		c.add(n.name, j.Data)
+	}
+
+	j.ResponseChan <- Response
 
 	n.resource.Release()
 }
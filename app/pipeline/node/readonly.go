@@ -14,9 +14,23 @@ import (
 //readOnly Wraps a readOnly core
 type readOnly struct {
 	processor processor.ReadOnly
+	completer processor.Completer
 	*Node
 }
 
+//completerOf return the processor plugin as a Completer, nil if it isn't one
+func completerOf(plugin processor.ReadOnly) processor.Completer {
+	completer, _ := plugin.(processor.Completer)
+	return completer
+}
+
+//complete notifies the processor of the final response of the job
+func (n *readOnly) complete(data payload.Data, Response response.Response) {
+	if n.completer != nil {
+		n.completer.Complete(data, Response)
+	}
+}
+
 //process process process by calling Decode-> process-> Encode->
 func (n *readOnly) process(j job.Job) {
 
@@ -57,6 +71,7 @@ func (n *readOnly) process(j job.Job) {
 
 	// Await Responses
 	Response = n.waitResponses(responseChan)
+	n.complete(j.Data, Response)
 
 	// Send Response back.
 	j.ResponseChan <- Response
@@ -110,6 +125,7 @@ func (n *readOnly) processStream(j job.Job) {
 
 	// Await Responses
 	Response = n.waitResponses(responseChan)
+	n.complete(j.Data, Response)
 
 	// Send Response back.
 	j.ResponseChan <- Response
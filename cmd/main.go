@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	_ "net/http/pprof"
 	"os"
@@ -20,12 +21,25 @@ func main() {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 
+	// `prism run --pipeline <name>` reads images from stdin instead of the configured inputs
+	var run *runFlags
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		run = parseRunFlags(os.Args[2:])
+	}
+
 	// Parse configuration from yaml files
 	config, err := bootstrap()
 	if err != nil {
 		panic(err)
 	}
 
+	if run != nil {
+		err = runFromStdin(&config, *run)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	// Create new app instance
 	app, err := app.NewApp(config)
 	if err != nil {
@@ -38,19 +52,73 @@ func main() {
 		panic(err)
 	}
 
-	// Defer Closing the app.
-	defer func() {
-		err = app.Stop()
-		if err != nil {
-			panic(err)
-		}
-	}()
-
 	// Termination
-	sig := <-signalChan
+	var failed error
+	select {
+	case sig := <-signalChan:
+		config.Logger.Infof("Received %s signal, the service is closing...", sig.String())
+	case <-app.Done():
+		config.Logger.Info("all inputs are done, the service is closing...")
+		failed = app.Err()
+	}
+
+	err = app.Stop()
+	if err != nil {
+		panic(err)
+	}
+
+	// exit with a non-zero code so scripts running `prism run` can tell images failed
+	if failed != nil {
+		config.Logger.Error(failed.Error())
+		_ = config.Logger.Sync()
+		os.Exit(1)
+	}
+}
+
+// runFlags are the flags of `prism run`
+type runFlags struct {
+	pipeline string
+	framing  string
+}
+
+// parseRunFlags parses `prism run` flags, printing usage and exiting if they're invalid or help is requested.
+func parseRunFlags(args []string) *runFlags {
+	run := &runFlags{}
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.StringVar(&run.pipeline, "pipeline", "", "pipeline to run images read from stdin through")
+	flags.StringVar(&run.framing, "framing", "single", "how images are framed in stdin, either single, length, or tar")
+
+	_ = flags.Parse(args)
+
+	if run.pipeline == "" {
+		fmt.Fprintln(flags.Output(), "run: -pipeline is required")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return run
+}
+
+// runFromStdin replaces configured inputs with a single stdin input that sends images to the pipeline given in run.
+func runFromStdin(c *config.Config, run runFlags) error {
+	input := &config.Input{
+		Component: config.Component{
+			Plugin: "stdin",
+			Config: map[string]interface{}{
+				"pipeline": run.pipeline,
+				"framing":  run.framing,
+			},
+		},
+	}
+
+	err := input.ApplyDefault()
+	if err != nil {
+		return err
+	}
 
-	config.Logger.Infof("Received %s signal, the service is closing...", sig.String())
+	c.Components.Inputs.Inputs = map[string]*config.Input{"stdin": input}
 
+	return nil
 }
 
 // PARSE STUFF
@@ -166,7 +234,8 @@ func bootLogger(env string) (*zap.SugaredLogger, error) {
 }
 
 func printLogo() {
-	fmt.Print(`
+	// stderr, so it doesn't get mixed with images written to stdout
+	fmt.Fprint(os.Stderr, `
 
  ________    ________      ___      ________       _____ ______      
 |\   __  \  |\   __  \    |\  \    |\   ____\     |\   _ \  _   \    
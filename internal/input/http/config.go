@@ -11,6 +11,15 @@ type config struct {
 	LogRequest string          `mapstructure:"log_request" validate:"oneof=all debug none"`
 	LogErrors  bool            `mapstructure:"log_errors"`
 	RateLimit  float64         `mapstructure:"rate_limit"`
+
+	// Messages replace messages of rejection reasons by their code (e.g TOO_WIDE), and can use the reason values
+	// (e.g "width must be at most @{max_width}px").
+	Messages map[string]string `mapstructure:"messages"`
+	messages map[string]cfg.Selector
+
+	// Statuses replace HTTP statuses of rejection reasons by their code (e.g DUPLICATE: 200).
+	Statuses map[string]int `mapstructure:"statuses" validate:"dive,min=200,max=599"`
+	statuses map[string]int
 }
 
 type path struct {
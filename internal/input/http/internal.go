@@ -146,6 +146,9 @@ func (w *Webserver) handle(rw http.ResponseWriter, r *http.Request) {
 		filename := part.FileName()
 		data["_filename"] = filename[0 : len(filename)-len(filepath.Ext(filename))]
 
+		// Add Accept header for format negotiation
+		data["_accept"] = r.Header.Get("Accept")
+
 		responseChan := make(chan responseT.Response)
 		w.jobs <- job.Input{
 			Job: job.Job{
@@ -163,7 +166,7 @@ func (w *Webserver) handle(rw http.ResponseWriter, r *http.Request) {
 		if !response.Ack {
 			// check if responseT is simply refused, or an internal responseT occurred
 			if response.AckErr != nil {
-				w.respondError(r, rw, *newNoAck(response.AckErr))
+				w.respondError(r, rw, *w.newNoAck(response.AckErr))
 			} else if response.Error != nil {
 				w.respondError(r, rw, *newError(response.Error))
 			}
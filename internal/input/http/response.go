@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	responseT "github.com/sherifabdlnaby/prism/pkg/response"
 )
 
 var (
@@ -16,17 +18,61 @@ var (
 	resSuccess          = response{Code: http.StatusOK, Message: "Request Successful"}
 )
 
+// statuses of rejection reasons by their code, other reasons are unprocessable entities.
+var statuses = map[string]int{
+	"FILE_TOO_LARGE":      http.StatusRequestEntityTooLarge,
+	"TOO_LARGE_TO_DECODE": http.StatusRequestEntityTooLarge,
+	"INVALID_IMAGE":       http.StatusBadRequest,
+	"UNSUPPORTED_FORMAT":  http.StatusUnsupportedMediaType,
+	"FORMAT_NOT_ALLOWED":  http.StatusUnsupportedMediaType,
+	"DUPLICATE":           http.StatusConflict,
+}
+
 type response struct {
-	Code    int    `json:"code"`
-	Message string `json:"message,omitempty"`
+	Code    int               `json:"code"`
+	Message string            `json:"message,omitempty"`
+	Reason  *responseT.Reason `json:"reason,omitempty"`
+}
+
+// newNoAck return the response of a dropped request, machine-readable reasons are included with their configured
+// message if any.
+func (w *Webserver) newNoAck(noAck error) *response {
+	reason, ok := noAck.(*responseT.Reason)
+	if !ok {
+		return &response{Code: http.StatusBadRequest, Message: fmt.Sprintf("request was dropped, reason: %s", noAck.Error())}
+	}
+
+	// copy, the reason is shared with the rest of the pipeline
+	reply := *reason
+	if selector, ok := w.config.messages[reason.Code]; ok {
+		message, err := selector.Evaluate(reason.Values)
+		if err == nil {
+			reply.Message = message
+		}
+	}
+
+	return &response{
+		Code:    w.status(reason.Code),
+		Message: fmt.Sprintf("request was dropped, reason: %s", reply.Message),
+		Reason:  &reply,
+	}
 }
 
-func newNoAck(noAck error) *response {
-	return &response{http.StatusBadRequest, fmt.Sprintf("request was dropped, reason: %s", noAck.Error())}
+// status return the HTTP status of a rejection reason, configured statuses take precedence.
+func (w *Webserver) status(code string) int {
+	if status, ok := w.config.statuses[code]; ok {
+		return status
+	}
+
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+
+	return http.StatusUnprocessableEntity
 }
 
 func newError(err error) *response {
-	return &response{http.StatusBadRequest, fmt.Sprintf("error while processing, reason: %s", err.Error())}
+	return &response{Code: http.StatusBadRequest, Message: fmt.Sprintf("error while processing, reason: %s", err.Error())}
 }
 
 func (w *Webserver) respondError(r *http.Request, wr http.ResponseWriter, reply response) {
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sherifabdlnaby/prism/internal/processor/phash"
+	"github.com/sherifabdlnaby/prism/internal/processor/quality"
+	"github.com/sherifabdlnaby/prism/internal/processor/validator"
+	responseT "github.com/sherifabdlnaby/prism/pkg/response"
+)
+
+func TestNoAckStatus(t *testing.T) {
+	w := &Webserver{config: config{statuses: map[string]int{phash.CodeDuplicate: http.StatusOK}}}
+
+	tests := []struct {
+		name  string
+		noAck error
+		want  int
+	}{
+		{name: "without reason", noAck: errors.New("dropped"), want: http.StatusBadRequest},
+		{name: "file too large", noAck: reason(validator.CodeFileTooLarge), want: http.StatusRequestEntityTooLarge},
+		{name: "too large to decode", noAck: reason(validator.CodeTooLargeToDecode), want: http.StatusRequestEntityTooLarge},
+		{name: "invalid image", noAck: reason(validator.CodeInvalidImage), want: http.StatusBadRequest},
+		{name: "unsupported format", noAck: reason(validator.CodeUnsupportedFormat), want: http.StatusUnsupportedMediaType},
+		{name: "format not allowed", noAck: reason(validator.CodeFormatNotAllowed), want: http.StatusUnsupportedMediaType},
+		{name: "too wide", noAck: reason(validator.CodeTooWide), want: http.StatusUnprocessableEntity},
+		{name: "blurry", noAck: reason(quality.CodeBlurry), want: http.StatusUnprocessableEntity},
+		{name: "configured", noAck: reason(phash.CodeDuplicate), want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.newNoAck(tt.noAck).Code; got != tt.want {
+				t.Errorf("newNoAck().Code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	delete(w.config.statuses, phash.CodeDuplicate)
+	if got := w.newNoAck(reason(phash.CodeDuplicate)).Code; got != http.StatusConflict {
+		t.Errorf("newNoAck().Code of duplicates = %d, want %d", got, http.StatusConflict)
+	}
+}
+
+func reason(code string) error {
+	r, _ := responseT.Reject(code, code, nil).Reason()
+	return r
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
@@ -50,6 +51,20 @@ func (w *Webserver) Init(config cfg.Config, logger zap.SugaredLogger) error {
 		}
 		w.config.Paths[key] = value
 	}
+
+	// keys are upper-cased as config keys may be lower-cased when loaded
+	w.config.messages = make(map[string]cfg.Selector, len(w.config.Messages))
+	for code, message := range w.config.Messages {
+		w.config.messages[strings.ToUpper(code)], err = config.NewSelector(message)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.config.statuses = make(map[string]int, len(w.config.Statuses))
+	for code, status := range w.config.Statuses {
+		w.config.statuses[strings.ToUpper(code)] = status
+	}
 	w.jobs = make(chan job.Input)
 	w.logger = logger
 
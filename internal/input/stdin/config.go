@@ -0,0 +1,14 @@
+package stdin
+
+// config struct used to decode YAML into
+type config struct {
+	Pipeline string `mapstructure:"pipeline" validate:"required"`
+	Framing  string `mapstructure:"framing" validate:"oneof=single length tar"`
+	Timeout  int    `mapstructure:"timeout" validate:"min=0"`
+}
+
+func defaultConfig() *config {
+	return &config{
+		Framing: "single",
+	}
+}
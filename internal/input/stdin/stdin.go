@@ -0,0 +1,210 @@
+package stdin
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Stdin Input that reads images from the process standard input, either a single image, a stream of images each
+// prefixed by its length (4 bytes, big endian), or a tar stream. jobs are sent one at a time so outputs receive them
+// in the same order they were read.
+type Stdin struct {
+	config   config
+	reader   io.Reader
+	jobs     chan job.Input
+	stopChan chan struct{}
+	done     chan struct{}
+	failed   int
+	rejected int
+	err      error
+	logger   zap.SugaredLogger
+	wg       sync.WaitGroup
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Stdin{}
+}
+
+// JobChan Return Job Chan used to send job to this Base
+func (s *Stdin) JobChan() <-chan job.Input {
+	return s.jobs
+}
+
+// Done is closed once standard input is exhausted and all read jobs are done.
+func (s *Stdin) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns an error if reading standard input failed, or some images failed or weren't acknowledged.
+func (s *Stdin) Err() error {
+	switch {
+	case s.err != nil:
+		return fmt.Errorf("failed to read from standard input: %s", s.err.Error())
+	case s.failed > 0 || s.rejected > 0:
+		return fmt.Errorf("%d image(s) failed and %d image(s) were not acknowledged", s.failed, s.rejected)
+	}
+	return nil
+}
+
+// Init Initializes Plugin
+func (s *Stdin) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	s.config = *defaultConfig()
+	err := config.Populate(&s.config)
+	if err != nil {
+		return err
+	}
+
+	s.reader = os.Stdin
+	s.jobs = make(chan job.Input)
+	s.stopChan = make(chan struct{})
+	s.done = make(chan struct{})
+	s.logger = logger
+	return nil
+}
+
+// Start Starts Plugin
+func (s *Stdin) Start() error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(s.done)
+
+		var err error
+		switch s.config.Framing {
+		case "single":
+			err = s.readSingle()
+		case "length":
+			err = s.readLengthPrefixed()
+		case "tar":
+			err = s.readTar()
+		}
+
+		if err != nil {
+			s.logger.Errorw("failed to read from standard input", "error", err.Error())
+			s.err = err
+		}
+	}()
+
+	return nil
+}
+
+// Stop closes the plugin gracefully
+func (s *Stdin) Stop() error {
+	close(s.stopChan)
+	s.wg.Wait()
+	close(s.jobs)
+	return nil
+}
+
+func (s *Stdin) readSingle() error {
+	s.send(payload.Stream(s.reader), payload.Data{})
+	return nil
+}
+
+func (s *Stdin) readLengthPrefixed() error {
+	var length uint32
+	for {
+		err := binary.Read(s.reader, binary.BigEndian, &length)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		buffer := make([]byte, length)
+		_, err = io.ReadFull(s.reader, buffer)
+		if err != nil {
+			return err
+		}
+
+		if !s.send(payload.Bytes(buffer), payload.Data{}) {
+			return nil
+		}
+	}
+}
+
+func (s *Stdin) readTar() error {
+	reader := tar.NewReader(s.reader)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		buffer, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		base := filepath.Base(header.Name)
+		data := payload.Data{
+			"_filename": base[0 : len(base)-len(filepath.Ext(base))],
+		}
+
+		if !s.send(payload.Bytes(buffer), data) {
+			return nil
+		}
+	}
+}
+
+// send sends a job and waits for its response, returns false if plugin is stopping.
+func (s *Stdin) send(Payload payload.Payload, data payload.Data) bool {
+	ctx := context.Background()
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.config.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	responseChan := make(chan response.Response, 1)
+
+	select {
+	case <-s.stopChan:
+		return false
+	case s.jobs <- job.Input{
+		Job: job.Job{
+			Payload:      Payload,
+			Data:         data,
+			ResponseChan: responseChan,
+			Context:      ctx,
+		},
+		PipelineTag: s.config.Pipeline,
+	}:
+	}
+
+	Response := <-responseChan
+	switch {
+	case Response.Error != nil:
+		s.logger.Errorw("failed to process image", "error", Response.Error.Error())
+		s.failed++
+	case !Response.Ack:
+		s.logger.Warnw("image was not acknowledged", "reason", Response.AckErr)
+		s.rejected++
+	}
+
+	return true
+}
@@ -7,11 +7,13 @@ import (
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/digest"
 	"github.com/sherifabdlnaby/prism/pkg/job"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
@@ -99,12 +101,27 @@ func (s *S3) writeOnS3(svc *s3.S3, Job job.Job) {
 	switch Payload := Job.Payload.(type) {
 	case payload.Bytes:
 		buffer = Payload
+		if s.config.ContentAddressable {
+			Job.Data[digest.Field] = digest.Bytes(Payload)
+		}
 	case payload.Stream:
-		buffer, err = ioutil.ReadAll(Payload)
+		if !s.config.ContentAddressable {
+			buffer, err = ioutil.ReadAll(Payload)
+			if err != nil {
+				Job.ResponseChan <- response.Error(err)
+				return
+			}
+			break
+		}
+
+		// hash while reading
+		reader := digest.NewReader(Payload)
+		buffer, err = ioutil.ReadAll(reader)
 		if err != nil {
 			Job.ResponseChan <- response.Error(err)
 			return
 		}
+		Job.Data[digest.Field] = reader.Sum()
 	}
 
 	filePath, err := s.config.filepath.Evaluate(Job.Data)
@@ -113,6 +130,21 @@ func (s *S3) writeOnS3(svc *s3.S3, Job job.Job) {
 		return
 	}
 
+	Job.Data["_location"] = "s3://" + s.config.S3Bucket + "/" + filePath
+
+	// same content is already stored
+	if s.config.ContentAddressable {
+		exists, err := objectExists(s.config.S3Bucket, filePath, svc)
+		if err != nil {
+			Job.ResponseChan <- response.Error(err)
+			return
+		}
+		if exists {
+			Job.ResponseChan <- response.Ack()
+			return
+		}
+	}
+
 	size := int64(len(buffer))
 	_, err = svc.PutObject(&s3.PutObjectInput{
 		Bucket:               aws.String(s.config.S3Bucket),
@@ -149,6 +181,24 @@ func pingBucket(bucket string, client *s3.S3) error {
 	return err
 }
 
+func objectExists(bucket, key string, client *s3.S3) (bool, error) {
+	_, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+
+	if err == nil {
+		return true, nil
+	}
+
+	// HeadObject has no body, so a missing key is reported as a plain 404 rather than s3.ErrCodeNoSuchKey
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+		return false, nil
+	}
+
+	return false, err
+}
+
 func getClient(region string, creds *credentials.Credentials) (*s3.S3, error) {
 	s3Config := aws.NewConfig().WithRegion(region).WithCredentials(creds)
 	sess, err := session.NewSession(s3Config)
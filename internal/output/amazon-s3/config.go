@@ -14,6 +14,7 @@ type config struct {
 	Encoding                      string `mapstructure:"encoding" validate:"oneof=none gzip"`
 	ServerSideEncryptionAlgorithm string `mapstructure:"server_side_encryption_algorithm" validate:"oneof=AES256 aws:kms"`
 	StorageClass                  string `mapstructure:"storage_class" validate:"oneof=STANDARD REDUCED_REDUNDANCY STANDARD_IA"`
+	ContentAddressable            bool   `mapstructure:"content_addressable"`
 
 	filepath cfg.Selector
 }
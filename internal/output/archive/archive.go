@@ -0,0 +1,234 @@
+package archive
+
+import (
+	"io/ioutil"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/h2non/filetype"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+//Archive output plugin that appends images into rolling tar/zip archives, rotated by count, size, or age.
+type Archive struct {
+	config   config
+	jobsChan <-chan job.Job
+	stopChan chan struct{}
+	logger   zap.SugaredLogger
+	wg       sync.WaitGroup
+
+	// guards current and sequence
+	mx       sync.Mutex
+	current  *archive
+	sequence int
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Archive{}
+}
+
+//SetJobChan set Job chan that this plugin will use to receive jobs
+func (a *Archive) SetJobChan(t <-chan job.Job) {
+	a.jobsChan = t
+}
+
+//Init func Initialize the archive output plugin
+func (a *Archive) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	a.config = *defaultConfig()
+	err = config.Populate(&a.config)
+	if err != nil {
+		return err
+	}
+
+	a.config.filename, err = config.NewSelector(a.config.FileName)
+	if err != nil {
+		return err
+	}
+
+	a.stopChan = make(chan struct{})
+	a.logger = logger
+
+	return nil
+}
+
+// Start the plugin and be ready for taking jobs
+func (a *Archive) Start() error {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		for Job := range a.jobsChan {
+			a.wg.Add(1)
+			go a.writeToArchive(Job)
+		}
+	}()
+
+	// rotate by age even if no new jobs are received
+	if a.config.MaxAge > 0 {
+		go a.rotateOnAge()
+	}
+
+	return nil
+}
+
+//Stop waits for in-flight jobs, then flushes and closes the current archive.
+func (a *Archive) Stop() error {
+	a.wg.Wait()
+	close(a.stopChan)
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	return a.rotate()
+}
+
+//writeToArchive reads the job payload and appends it to the current archive
+func (a *Archive) writeToArchive(Job job.Job) {
+	defer a.wg.Done()
+
+	var buffer []byte
+	var err error
+
+	switch Payload := Job.Payload.(type) {
+	case payload.Bytes:
+		buffer = Payload
+	case payload.Stream:
+		buffer, err = ioutil.ReadAll(Payload)
+		if err != nil {
+			Job.ResponseChan <- response.Error(err)
+			return
+		}
+	}
+
+	name, err := a.config.filename.Evaluate(Job.Data)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	if path.Ext(name) == "" {
+		name += extension(Job.Data, buffer)
+	}
+
+	location, err := a.add(name, buffer, Job.Data)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	// the archive the image is stored in, and its entry name in the archive
+	Job.Data["_location"] = location
+	Job.Data["_entry"] = name
+
+	Job.ResponseChan <- response.Ack()
+}
+
+//extension returns the file extension of the image format reported in data, or detected from content.
+func extension(data payload.Data, content []byte) string {
+	if format, ok := data["_format"].(string); ok && format != "" {
+		return "." + format
+	}
+
+	kind, err := filetype.Match(content)
+	if err != nil || kind == filetype.Unknown {
+		return ""
+	}
+
+	return "." + kind.Extension
+}
+
+//add adds an entry to the current archive, and returns the path the archive will have once closed.
+func (a *Archive) add(name string, content []byte, data payload.Data) (string, error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if a.current != nil && a.expired(a.current) {
+		err := a.rotate()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if a.current == nil {
+		current, err := newArchive(&a.config, a.sequence)
+		if err != nil {
+			return "", err
+		}
+		a.current = current
+		a.sequence++
+	}
+
+	current := a.current
+	err := current.add(name, content, data)
+	if err != nil {
+		// a partially written entry can't be undone, later entries go to a new archive.
+		if current.broken {
+			_ = a.rotate()
+		}
+		return "", err
+	}
+
+	if a.full(current) {
+		err = a.rotate()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return current.path, nil
+}
+
+//rotate closes current archive (if any), next added entry will open a new one. must be called while holding mx.
+func (a *Archive) rotate() error {
+	if a.current == nil {
+		return nil
+	}
+
+	current := a.current
+	a.current = nil
+
+	err := current.Close()
+	if err != nil {
+		a.logger.Errorw("failed to close archive", "archive", current.path, "error", err.Error())
+		return err
+	}
+
+	a.logger.Debugw("archive closed", "archive", current.path, "entries", current.count())
+	return nil
+}
+
+func (a *Archive) full(current *archive) bool {
+	return (a.config.MaxCount > 0 && current.count() >= a.config.MaxCount) ||
+		(a.config.MaxSize > 0 && current.size >= a.config.MaxSize)
+}
+
+func (a *Archive) expired(current *archive) bool {
+	return a.config.MaxAge > 0 && time.Since(current.created) >= time.Duration(a.config.MaxAge)*time.Second
+}
+
+func (a *Archive) rotateOnAge() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopChan:
+			return
+		case <-ticker.C:
+			a.mx.Lock()
+			if a.current != nil && a.expired(a.current) {
+				_ = a.rotate()
+			}
+			a.mx.Unlock()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"go.uber.org/zap"
+)
+
+// failingWriter fails entries named fail after writing part of them
+type failingWriter struct {
+	entryWriter
+}
+
+func (f failingWriter) add(name string, content []byte, modTime time.Time) error {
+	if name == "fail" {
+		_ = f.entryWriter.add(name, content[:len(content)/2], modTime)
+		return errors.New("disk full")
+	}
+	return f.entryWriter.add(name, content, modTime)
+}
+
+func TestBrokenEntry(t *testing.T) {
+	dir := t.TempDir()
+	a := &Archive{config: *defaultConfig(), logger: *zap.NewNop().Sugar()}
+	a.config.Directory = dir
+
+	first, err := a.add("first", []byte("image"), payload.Data{})
+	if err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	broken := a.current
+	broken.writer = failingWriter{broken.writer}
+
+	if _, err := a.add("fail", []byte("image"), payload.Data{}); err == nil {
+		t.Fatalf("add() of a failing entry error = nil")
+	}
+	if a.current != nil {
+		t.Fatalf("broken archive is still current")
+	}
+	if err := broken.add("later", []byte("image"), payload.Data{}); err == nil {
+		t.Errorf("add() to a broken archive error = nil")
+	}
+
+	// later entries go to a new archive that is completed
+	second, err := a.add("second", []byte("image"), payload.Data{})
+	if err != nil {
+		t.Fatalf("add() after a broken archive error = %v", err)
+	}
+	if second == first {
+		t.Errorf("add() after a broken archive = %s, want a new archive", second)
+	}
+	if err := a.rotate(); err != nil {
+		t.Fatalf("rotate() error = %v", err)
+	}
+
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("broken archive %s was completed", first)
+	}
+	if _, err := os.Stat(first + ".part"); err != nil {
+		t.Errorf("broken archive isn't left as a .part file: %v", err)
+	}
+
+	file, err := os.Open(second)
+	if err != nil {
+		t.Fatalf("archive isn't completed: %v", err)
+	}
+	defer file.Close()
+
+	var names []string
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading %s: %v", filepath.Base(second), err)
+		}
+		names = append(names, header.Name)
+	}
+	if len(names) != 2 || names[0] != "second" || names[1] != manifestName {
+		t.Errorf("archive entries = %v, want [second %s]", names, manifestName)
+	}
+}
@@ -0,0 +1,31 @@
+package archive
+
+import (
+	"os"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+)
+
+//config struct
+type config struct {
+	Directory  string      `mapstructure:"directory" validate:"required"`
+	Prefix     string      `mapstructure:"prefix" validate:"required"`
+	Format     string      `mapstructure:"format" validate:"oneof=tar zip"`
+	FileName   string      `mapstructure:"filename" validate:"required"`
+	MaxCount   int         `mapstructure:"max_count" validate:"min=0"`
+	MaxSize    int64       `mapstructure:"max_size" validate:"min=0"`
+	MaxAge     int         `mapstructure:"max_age" validate:"min=0"`
+	Permission os.FileMode `mapstructure:"permission"`
+	filename   cfg.Selector
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Prefix:     "archive",
+		Format:     "tar",
+		FileName:   "@{_id}",
+		MaxCount:   1000,
+		Permission: 0777,
+	}
+}
@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+const manifestName = "manifest.json"
+
+// entryWriter writes entries into a single archive file
+type entryWriter interface {
+	add(name string, content []byte, modTime time.Time) error
+	Close() error
+}
+
+type tarWriter struct {
+	*tar.Writer
+	permission os.FileMode
+}
+
+func (t *tarWriter) add(name string, content []byte, modTime time.Time) error {
+	err := t.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(t.permission),
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = t.Write(content)
+	return err
+}
+
+type zipWriter struct {
+	*zip.Writer
+}
+
+func (z *zipWriter) add(name string, content []byte, modTime time.Time) error {
+	header := &zip.FileHeader{
+		Name: name,
+		// images are already compressed
+		Method: zip.Store,
+	}
+	header.SetModTime(modTime)
+
+	w, err := z.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
+
+// manifestEntry describes a single archived image
+type manifestEntry struct {
+	Name string       `json:"name"`
+	Size int          `json:"size"`
+	Data payload.Data `json:"data"`
+}
+
+// archive is a single archive file that is being written, it's written to a ".part" file and renamed on close so
+// that only complete archives appear in the output directory.
+type archive struct {
+	file     *os.File
+	writer   entryWriter
+	path     string
+	created  time.Time
+	size     int64
+	manifest []json.RawMessage
+
+	// broken is set when an entry failed partway, the archive can't be written to or completed anymore.
+	broken bool
+}
+
+func newArchive(c *config, sequence int) (*archive, error) {
+	err := os.MkdirAll(c.Directory, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Now()
+	name := fmt.Sprintf("%s-%s-%d.%s", c.Prefix, created.Format("20060102-150405"), sequence, c.Format)
+	path := filepath.Join(c.Directory, name)
+
+	file, err := os.OpenFile(path+".part", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, c.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archive{
+		file:     file,
+		path:     path,
+		created:  created,
+		manifest: make([]json.RawMessage, 0),
+	}
+
+	switch c.Format {
+	case "zip":
+		a.writer = &zipWriter{Writer: zip.NewWriter(file)}
+	default:
+		a.writer = &tarWriter{Writer: tar.NewWriter(file), permission: c.Permission}
+	}
+
+	return a, nil
+}
+
+//add writes an entry and its manifest record, data is marshaled first so a value that can't be marshaled only fails
+//its own entry, and later changes to data aren't reflected in the manifest.
+func (a *archive) add(name string, content []byte, data payload.Data) error {
+	entry, err := json.Marshal(manifestEntry{
+		Name: name,
+		Size: len(content),
+		Data: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add [%s] to archive manifest: %s", name, err.Error())
+	}
+
+	if a.broken {
+		return fmt.Errorf("archive [%s] is broken by a failed entry", a.path)
+	}
+
+	err = a.writer.add(name, content, time.Now())
+	if err != nil {
+		a.broken = true
+		return err
+	}
+
+	a.size += int64(len(content))
+	a.manifest = append(a.manifest, entry)
+
+	return nil
+}
+
+func (a *archive) count() int {
+	return len(a.manifest)
+}
+
+// Close writes the manifest, flushes and closes the archive, and moves it to its final name.
+func (a *archive) Close() error {
+	if a.broken {
+		return a.abandon()
+	}
+
+	manifest, err := json.Marshal(a.manifest)
+	if err != nil {
+		_ = a.file.Close()
+		return err
+	}
+
+	err = a.writer.add(manifestName, manifest, time.Now())
+	if err != nil {
+		_ = a.file.Close()
+		return err
+	}
+
+	err = a.writer.Close()
+	if err != nil {
+		_ = a.file.Close()
+		return err
+	}
+
+	err = a.file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(a.file.Name(), a.path)
+}
+
+// abandon closes a broken archive without completing it, it's left as a ".part" file so its entries can be recovered.
+func (a *archive) abandon() error {
+	err := a.file.Close()
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("archive [%s] is broken by a failed entry, left incomplete at [%s]", a.path, a.file.Name())
+}
@@ -8,9 +8,11 @@ import (
 
 //config struct
 type config struct {
-	Permission os.FileMode `mapstructure:"permission"`
-	FilePath   string      `mapstructure:"filepath"`
-	filepath   cfg.Selector
+	Permission         os.FileMode `mapstructure:"permission"`
+	FilePath           string      `mapstructure:"filepath"`
+	ContentAddressable bool        `mapstructure:"content_addressable"`
+	filepath           cfg.Selector
+	dir                string
 }
 
 //defaultConfig returns the default configs
@@ -1,15 +1,18 @@
 package disk
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/digest"
 	"github.com/sherifabdlnaby/prism/pkg/job"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
@@ -49,6 +52,7 @@ func (d *Disk) Init(config cfg.Config, logger zap.SugaredLogger) error {
 	if err != nil {
 		return err
 	}
+	d.config.dir = staticDir(d.config.FilePath)
 
 	d.stopChan = make(chan struct{})
 	d.logger = logger
@@ -81,18 +85,18 @@ func (d *Disk) Stop() error {
 func (d *Disk) writeOnDisk(Job job.Job) {
 	defer d.wg.Done()
 
+	if d.config.ContentAddressable {
+		d.writeContentAddressable(Job)
+		return
+	}
+
 	filePath, err := d.config.filepath.Evaluate(Job.Data)
 	if err != nil {
 		Job.ResponseChan <- response.Error(err)
 		return
 	}
 
-	dir := filepath.Dir(filePath)
-
-	if _, err = os.Stat(dir); os.IsNotExist(err) {
-		err = os.MkdirAll(dir, os.ModePerm)
-	}
-
+	err = makeDir(filePath)
 	if err != nil {
 		Job.ResponseChan <- response.Error(err)
 		return
@@ -111,10 +115,112 @@ func (d *Disk) writeOnDisk(Job job.Job) {
 		return
 	}
 
+	Job.Data["_location"] = filePath
+
 	// send response
 	Job.ResponseChan <- response.Ack()
 }
 
+//writeContentAddressable streams the payload to a temporary file while hashing it, so the digest can be used in the
+//file path, then renames it to the evaluated path. Renaming is atomic, concurrent jobs of the same content replace
+//the file with identical bytes.
+func (d *Disk) writeContentAddressable(Job job.Job) {
+	var reader io.Reader
+	switch Payload := Job.Payload.(type) {
+	case payload.Bytes:
+		reader = bytes.NewReader(Payload)
+	case payload.Stream:
+		reader = Payload
+	}
+
+	// temp file is created in the static part of the path so it is renamed within the same file system.
+	temp, err := createTemp(d.config.dir, d.config.Permission)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+	defer os.Remove(temp.Name())
+
+	hasher := digest.NewReader(reader)
+	_, err = io.Copy(temp, hasher)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	Job.Data[digest.Field] = hasher.Sum()
+
+	filePath, err := d.config.filepath.Evaluate(Job.Data)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	// objects are addressed by their content, an existing object is the same image.
+	_, err = os.Stat(filePath)
+	if os.IsNotExist(err) {
+		err = makeDir(filePath)
+		if err == nil {
+			err = os.Rename(temp.Name(), filePath)
+		}
+	}
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	Job.Data["_location"] = filePath
+
+	Job.ResponseChan <- response.Ack()
+}
+
+//createTemp creates a new hidden file in dir
+func createTemp(dir string, perm os.FileMode) (*os.File, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile(dir, ".prism-")
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.Chmod(perm)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return f, nil
+}
+
+//staticDir return the directory of the part of path before its first dynamic field
+func staticDir(path string) string {
+	if i := strings.Index(path, "@{"); i >= 0 {
+		path = path[:i]
+		// path ends with a separator or a partial file name
+		return filepath.Dir(path + "_")
+	}
+	return filepath.Dir(path)
+}
+
+//makeDir creates the parent directory of filePath if it doesn't exist
+func makeDir(filePath string) error {
+	dir := filepath.Dir(filePath)
+
+	_, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, os.ModePerm)
+	}
+
+	return err
+}
+
 func writeFileFromStream(filename string, reader io.Reader, perm os.FileMode) error {
 
 	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
@@ -0,0 +1,39 @@
+package http
+
+import cfg "github.com/sherifabdlnaby/prism/pkg/config"
+
+//config struct
+type config struct {
+	URL                string            `mapstructure:"url" validate:"required"`
+	Method             string            `mapstructure:"method" validate:"required"`
+	Headers            map[string]string `mapstructure:"headers"`
+	Body               string            `mapstructure:"body" validate:"oneof=raw multipart"`
+	FormField          string            `mapstructure:"form_field"`
+	FileName           string            `mapstructure:"filename"`
+	Fields             map[string]string `mapstructure:"fields"`
+	Timeout            int               `mapstructure:"timeout" validate:"min=0"`
+	Retries            int               `mapstructure:"retries" validate:"min=0"`
+	RetryBackoff       int               `mapstructure:"retry_backoff" validate:"min=0"`
+	CertFile           string            `mapstructure:"cert_file"`
+	KeyFile            string            `mapstructure:"key_file"`
+	CAFile             string            `mapstructure:"ca_file"`
+	InsecureSkipVerify bool              `mapstructure:"insecure_skip_verify"`
+
+	url      cfg.Selector
+	method   cfg.Selector
+	filename cfg.Selector
+	headers  map[string]cfg.Selector
+	fields   map[string]cfg.Selector
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Method:       "POST",
+		Body:         "raw",
+		FormField:    "image",
+		FileName:     "@{_id}",
+		Timeout:      30000,
+		RetryBackoff: 1000,
+	}
+}
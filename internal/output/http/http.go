@@ -0,0 +1,354 @@
+package http
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+//HTTP output plugin that sends images to a remote HTTP endpoint (e.g. a webhook)
+type HTTP struct {
+	config   config
+	client   *http.Client
+	jobsChan <-chan job.Job
+	logger   zap.SugaredLogger
+	wg       sync.WaitGroup
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &HTTP{}
+}
+
+//SetJobChan set Job chan that this plugin will use to receive jobs
+func (h *HTTP) SetJobChan(t <-chan job.Job) {
+	h.jobsChan = t
+}
+
+//Init func Initialize the http output plugin
+func (h *HTTP) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	h.config = *defaultConfig()
+	err = config.Populate(&h.config)
+	if err != nil {
+		return err
+	}
+
+	h.config.url, err = config.NewSelector(h.config.URL)
+	if err != nil {
+		return err
+	}
+
+	h.config.method, err = config.NewSelector(h.config.Method)
+	if err != nil {
+		return err
+	}
+
+	h.config.filename, err = config.NewSelector(h.config.FileName)
+	if err != nil {
+		return err
+	}
+
+	h.config.headers, err = newSelectors(config, h.config.Headers)
+	if err != nil {
+		return err
+	}
+
+	h.config.fields, err = newSelectors(config, h.config.Fields)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := h.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	h.client = &http.Client{
+		Timeout: time.Duration(h.config.Timeout) * time.Millisecond,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	h.logger = logger
+
+	return nil
+}
+
+// Start the plugin and be ready for taking jobs
+func (h *HTTP) Start() error {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		for Job := range h.jobsChan {
+			h.wg.Add(1)
+			go h.send(Job)
+		}
+	}()
+	return nil
+}
+
+//Stop func waits for in-flight requests to finish
+func (h *HTTP) Stop() error {
+	h.wg.Wait()
+	return nil
+}
+
+//maxBackoff caps the delay between retries
+const maxBackoff = time.Minute
+
+//send sends the job's payload to the configured url and maps the response status into a response.Response, failed
+//sends (connection errors, 5xx and 429) are retried with an exponential backoff.
+func (h *HTTP) send(Job job.Job) {
+	defer h.wg.Done()
+
+	body, err := h.body(Job.Payload)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	var Response response.Response
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		Response, retry = h.sendOnce(Job, body())
+		if !retry || attempt >= h.config.Retries {
+			break
+		}
+
+		h.logger.Debugw("retrying failed http send", "attempt", attempt+1, "error", Response.Error)
+
+		select {
+		case <-time.After(h.backoff(attempt)):
+		case <-done(Job):
+			Job.ResponseChan <- Response
+			return
+		}
+	}
+
+	Job.ResponseChan <- Response
+}
+
+//sendOnce sends the body once, and returns whether a failure may be retried.
+func (h *HTTP) sendOnce(Job job.Job, body io.Reader) (response.Response, bool) {
+	request, err := h.newRequest(Job, body)
+	if err != nil {
+		return response.Error(err), false
+	}
+
+	resp, err := h.client.Do(request)
+	if err != nil {
+		return response.Error(err), request.Context().Err() == nil
+	}
+
+	// drain body so connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		// prefer where the remote server says it stored the image
+		Job.Data["_location"] = request.URL.String()
+		if location := resp.Header.Get("Location"); location != "" {
+			Job.Data["_location"] = location
+		}
+		return response.Ack(), false
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return response.Error(fmt.Errorf("remote server failed to receive image, status: %s", resp.Status)), true
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return response.NoAck(fmt.Errorf("remote server refused image, status: %s", resp.Status)), false
+	default:
+		// informational and redirects that weren't followed
+		return response.Error(fmt.Errorf("unexpected status from remote server: %s", resp.Status)), false
+	}
+}
+
+//backoff returns the delay before retrying after attempt, doubled every attempt up to maxBackoff.
+func (h *HTTP) backoff(attempt int) time.Duration {
+	delay := time.Duration(h.config.RetryBackoff) * time.Millisecond
+	for i := 0; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+//body returns a func that returns a reader of the payload for every attempt, streams are only buffered when sends
+//are retried.
+func (h *HTTP) body(Payload payload.Payload) (func() io.Reader, error) {
+	switch Payload := Payload.(type) {
+	case payload.Bytes:
+		return func() io.Reader { return bytes.NewReader(Payload) }, nil
+	case payload.Stream:
+		if h.config.Retries == 0 {
+			return func() io.Reader { return Payload }, nil
+		}
+		buffer, err := ioutil.ReadAll(Payload)
+		if err != nil {
+			return nil, err
+		}
+		return func() io.Reader { return bytes.NewReader(buffer) }, nil
+	default:
+		return nil, fmt.Errorf("invalid job Payload type, must be Payload.Bytes or Payload.Stream")
+	}
+}
+
+//newRequest builds the http request with evaluated url, method, and headers. body is streamed from the payload.
+func (h *HTTP) newRequest(Job job.Job, body io.Reader) (*http.Request, error) {
+	url, err := h.config.url.Evaluate(Job.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := h.config.method.Evaluate(Job.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "application/octet-stream"
+
+	if h.config.Body == "multipart" {
+		body, contentType, err = h.multipartBody(body, Job.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	request, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if Job.Context != nil {
+		request = request.WithContext(Job.Context)
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	for key, value := range h.config.headers {
+		header, err := value.Evaluate(Job.Data)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set(key, header)
+	}
+
+	return request, nil
+}
+
+//multipartBody returns a reader that streams a multipart form containing the image and the configured fields.
+func (h *HTTP) multipartBody(image io.Reader, data payload.Data) (io.Reader, string, error) {
+	filename, err := h.config.filename.Evaluate(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// evaluate before streaming, so a missing field fails the job before sending anything.
+	fields := make(map[string]string, len(h.config.fields))
+	for key, value := range h.config.fields {
+		fields[key], err = value.Evaluate(data)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	reader, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+
+	go func() {
+		for key, value := range fields {
+			err := form.WriteField(key, value)
+			if err != nil {
+				_ = writer.CloseWithError(err)
+				return
+			}
+		}
+
+		part, err := form.CreateFormFile(h.config.FormField, filename)
+		if err != nil {
+			_ = writer.CloseWithError(err)
+			return
+		}
+
+		_, err = io.Copy(part, image)
+		if err != nil {
+			_ = writer.CloseWithError(err)
+			return
+		}
+
+		_ = writer.CloseWithError(form.Close())
+	}()
+
+	return reader, form.FormDataContentType(), nil
+}
+
+//tlsConfig loads client certificates and CA used to verify the server, returns nil if not configured.
+func (h *HTTP) tlsConfig() (*tls.Config, error) {
+	if h.config.CertFile == "" && h.config.CAFile == "" && !h.config.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: h.config.InsecureSkipVerify,
+	}
+
+	if h.config.CertFile != "" || h.config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(h.config.CertFile, h.config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if h.config.CAFile != "" {
+		caCert, err := ioutil.ReadFile(h.config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in [%s]", h.config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+//done returns the done channel of the job's context, nil (blocks forever) if it has none.
+func done(Job job.Job) <-chan struct{} {
+	if Job.Context == nil {
+		return nil
+	}
+	return Job.Context.Done()
+}
+
+func newSelectors(config cfg.Config, values map[string]string) (map[string]cfg.Selector, error) {
+	selectors := make(map[string]cfg.Selector, len(values))
+	for key, value := range values {
+		selector, err := config.NewSelector(value)
+		if err != nil {
+			return nil, err
+		}
+		selectors[key] = selector
+	}
+	return selectors, nil
+}
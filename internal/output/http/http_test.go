@@ -0,0 +1,371 @@
+package http
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// newOutput returns a started http output with config
+func newOutput(t *testing.T, config map[string]interface{}) (*HTTP, chan<- job.Job) {
+	t.Helper()
+
+	h := NewComponent().(*HTTP)
+	err := h.Init(*cfg.NewConfig(config), *zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	jobs := make(chan job.Job)
+	h.SetJobChan(jobs)
+	_ = h.Start()
+
+	t.Cleanup(func() {
+		close(jobs)
+		_ = h.Stop()
+	})
+
+	return h, jobs
+}
+
+// send sends Payload to the output and returns its response
+func send(jobs chan<- job.Job, Payload payload.Payload, data payload.Data) response.Response {
+	responseChan := make(chan response.Response, 1)
+	jobs <- job.Job{Payload: Payload, Data: data, ResponseChan: responseChan}
+	return <-responseChan
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		ack    bool
+		err    bool
+	}{
+		{name: "ok", status: http.StatusOK, ack: true},
+		{name: "created", status: http.StatusCreated, ack: true},
+		{name: "bad request", status: http.StatusBadRequest},
+		{name: "too large", status: http.StatusRequestEntityTooLarge},
+		{name: "internal error", status: http.StatusInternalServerError, err: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			_, jobs := newOutput(t, map[string]interface{}{"url": server.URL})
+
+			data := payload.Data{}
+			Response := send(jobs, payload.Bytes("image"), data)
+			if Response.Ack != tt.ack || (Response.Error != nil) != tt.err {
+				t.Errorf("status %d got Ack = %v, Error = %v", tt.status, Response.Ack, Response.Error)
+			}
+			if tt.ack && data["_location"] != server.URL {
+				t.Errorf("_location = %v, want %s", data["_location"], server.URL)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	var method, header, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, header, contentType = r.Method, r.Header.Get("X-Image-Id"), r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Location", "https://images.example.com/42")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	_, jobs := newOutput(t, map[string]interface{}{
+		"url":     server.URL + "/images/@{_id}",
+		"method":  "PUT",
+		"headers": map[string]interface{}{"X-Image-Id": "@{_id}"},
+	})
+
+	data := payload.Data{"_id": "42"}
+	Response := send(jobs, payload.Stream(bytes.NewReader([]byte("image"))), data)
+	if !Response.Ack {
+		t.Fatalf("Ack = false, Error = %v", Response.Error)
+	}
+
+	if method != "PUT" || header != "42" || contentType != "application/octet-stream" || string(body) != "image" {
+		t.Errorf("got method %s, header %s, content type %s, body %q", method, header, contentType, body)
+	}
+	if data["_location"] != "https://images.example.com/42" {
+		t.Errorf("_location = %v, want the Location header", data["_location"])
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	var field, filename string
+	var image []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("upload")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		image, _ = ioutil.ReadAll(file)
+		field, filename = r.FormValue("owner"), header.Filename
+	}))
+	defer server.Close()
+
+	_, jobs := newOutput(t, map[string]interface{}{
+		"url":        server.URL,
+		"body":       "multipart",
+		"form_field": "upload",
+		"filename":   "@{_id}.jpg",
+		"fields":     map[string]interface{}{"owner": "@{owner}"},
+	})
+
+	Response := send(jobs, payload.Bytes("image"), payload.Data{"_id": "42", "owner": "prism"})
+	if !Response.Ack {
+		t.Fatalf("Ack = false, Error = %v", Response.Error)
+	}
+
+	if field != "prism" || filename != "42.jpg" || string(image) != "image" {
+		t.Errorf("got field %s, filename %s, image %q", field, filename, image)
+	}
+}
+
+func TestMissingSelector(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	_, jobs := newOutput(t, map[string]interface{}{
+		"url":     server.URL,
+		"headers": map[string]interface{}{"X-Owner": "@{owner}"},
+		"retries": 2,
+	})
+
+	Response := send(jobs, payload.Bytes("image"), payload.Data{})
+	if Response.Error == nil {
+		t.Error("Error = nil, want missing field error")
+	}
+	if requests != 0 {
+		t.Errorf("sent %d requests, want none", requests)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		failures int32
+		status   int
+		ack      bool
+		requests int32
+	}{
+		{name: "no retries", retries: 0, failures: 1, status: http.StatusBadGateway, requests: 1},
+		{name: "recovers", retries: 2, failures: 2, status: http.StatusBadGateway, ack: true, requests: 3},
+		{name: "exhausted", retries: 2, failures: 5, status: http.StatusBadGateway, requests: 3},
+		{name: "refused isn't retried", retries: 2, failures: 5, status: http.StatusBadRequest, requests: 1},
+		{name: "too many requests", retries: 2, failures: 1, status: http.StatusTooManyRequests, ack: true, requests: 2},
+		{name: "not modified isn't retried", retries: 2, failures: 5, status: http.StatusNotModified, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			var bodies [][]byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, body)
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					w.WriteHeader(tt.status)
+				}
+			}))
+			defer server.Close()
+
+			_, jobs := newOutput(t, map[string]interface{}{
+				"url":           server.URL,
+				"retries":       tt.retries,
+				"retry_backoff": 1,
+			})
+
+			Response := send(jobs, payload.Stream(bytes.NewReader([]byte("image"))), payload.Data{})
+			if Response.Ack != tt.ack {
+				t.Errorf("Ack = %v, want %v, Error = %v", Response.Ack, tt.ack, Response.Error)
+			}
+			if requests != tt.requests {
+				t.Errorf("sent %d requests, want %d", requests, tt.requests)
+			}
+			// streams are replayed on every attempt
+			for i, body := range bodies {
+				if string(body) != "image" {
+					t.Errorf("request %d body = %q, want %q", i, body, "image")
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	h := &HTTP{config: config{RetryBackoff: 1000}}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 3, want: 8 * time.Second},
+		{attempt: 6, want: maxBackoff},
+		{attempt: 200, want: maxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := h.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, jobs := newOutput(t, map[string]interface{}{"url": server.URL, "timeout": 20})
+
+	Response := send(jobs, payload.Bytes("image"), payload.Data{})
+	if Response.Error == nil {
+		t.Error("Error = nil, want timeout error")
+	}
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prism-http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := writeKeyPair(t, dir, "client")
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		ack    bool
+		err    bool
+	}{
+		{name: "unknown authority", config: map[string]interface{}{}, err: true},
+		{name: "no client certificate", config: map[string]interface{}{"ca_file": caFile}},
+		{name: "client certificate", config: map[string]interface{}{
+			"ca_file":   caFile,
+			"cert_file": client[0],
+			"key_file":  client[1],
+		}, ack: true},
+		{name: "skip verify", config: map[string]interface{}{
+			"insecure_skip_verify": true,
+			"cert_file":            client[0],
+			"key_file":             client[1],
+		}, ack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["url"] = server.URL
+			_, jobs := newOutput(t, tt.config)
+
+			Response := send(jobs, payload.Bytes("image"), payload.Data{})
+			if Response.Ack != tt.ack || (Response.Error != nil) != tt.err {
+				t.Errorf("got Ack = %v, Error = %v", Response.Ack, Response.Error)
+			}
+		})
+	}
+}
+
+func TestInvalidTLS(t *testing.T) {
+	h := NewComponent()
+	err := h.Init(*cfg.NewConfig(map[string]interface{}{
+		"url":     "https://localhost",
+		"ca_file": "does-not-exist.pem",
+	}), *zap.NewNop().Sugar())
+	if err == nil {
+		t.Error("Init() error = nil, want missing CA file error")
+	}
+}
+
+// writeKeyPair writes a self signed certificate and its key, and returns their paths
+func writeKeyPair(t *testing.T, dir, name string) [2]string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := [2]string{filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")}
+	writePEM(t, paths[0], "CERTIFICATE", cert)
+	writePEM(t, paths[1], "EC PRIVATE KEY", der)
+
+	return paths
+}
+
+func writePEM(t *testing.T, path, kind string, der []byte) {
+	t.Helper()
+
+	err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,12 +2,17 @@ package mysql
 
 import (
 	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"sync"
 
 	_ "github.com/go-sql-driver/mysql" ///go-sql-driver for mysql
 	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/digest"
 	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
 	"go.uber.org/zap"
 )
@@ -55,6 +60,33 @@ func (m *Mysql) Init(config cfg.Config, logger zap.SugaredLogger) error {
 func (m *Mysql) writeOnMysql(db *sql.DB, job job.Job) {
 	defer m.wg.Done()
 
+	// reuse the digest reported upstream, e.g by collected content addressable outputs, only hashing the payload if
+	// there's none.
+	if m.config.ContentAddressable {
+		sum, ok := digest.From(job.Data)
+		switch Payload := job.Payload.(type) {
+		case payload.Bytes:
+			if !ok && len(Payload) == 0 {
+				job.ResponseChan <- response.Error(fmt.Errorf("no digest to reuse in data, and payload is empty"))
+				return
+			}
+			if !ok {
+				sum = digest.Bytes(Payload)
+			}
+		case payload.Stream:
+			if !ok {
+				reader := digest.NewReader(Payload)
+				_, err := io.Copy(ioutil.Discard, reader)
+				if err != nil {
+					job.ResponseChan <- response.Error(err)
+					return
+				}
+				sum = reader.Sum()
+			}
+		}
+		job.Data[digest.Field] = sum
+	}
+
 	query, err := m.config.query.Evaluate(job.Data)
 	if err != nil {
 		job.ResponseChan <- response.Error(err)
@@ -0,0 +1,24 @@
+package stdout
+
+import (
+	"os"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+)
+
+//config struct
+type config struct {
+	Framing    string      `mapstructure:"framing" validate:"oneof=single length tar"`
+	FileName   string      `mapstructure:"filename" validate:"required"`
+	Permission os.FileMode `mapstructure:"permission"`
+	filename   cfg.Selector
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Framing:    "single",
+		FileName:   "@{_id}",
+		Permission: 0644,
+	}
+}
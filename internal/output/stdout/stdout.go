@@ -0,0 +1,204 @@
+package stdout
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+//Stdout output plugin that writes images to the process standard output, either raw, each prefixed by its length
+// (4 bytes, big endian), or as entries of a tar stream.
+type Stdout struct {
+	config   config
+	jobsChan <-chan job.Job
+	logger   zap.SugaredLogger
+	wg       sync.WaitGroup
+
+	// guards writes to stdout
+	mx     sync.Mutex
+	writer *bufio.Writer
+	tar    *tar.Writer
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Stdout{}
+}
+
+//SetJobChan set Job chan that this plugin will use to receive jobs
+func (s *Stdout) SetJobChan(t <-chan job.Job) {
+	s.jobsChan = t
+}
+
+//Init func Initialize the stdout output plugin
+func (s *Stdout) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	s.config = *defaultConfig()
+	err = config.Populate(&s.config)
+	if err != nil {
+		return err
+	}
+
+	s.config.filename, err = config.NewSelector(s.config.FileName)
+	if err != nil {
+		return err
+	}
+
+	s.writer = bufio.NewWriter(os.Stdout)
+	if s.config.Framing == "tar" {
+		s.tar = tar.NewWriter(s.writer)
+	}
+
+	s.logger = logger
+
+	return nil
+}
+
+// Start the plugin and be ready for taking jobs
+func (s *Stdout) Start() error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for Job := range s.jobsChan {
+			s.wg.Add(1)
+			go s.writeToStdout(Job)
+		}
+	}()
+	return nil
+}
+
+//Stop waits for in-flight jobs, then flushes standard output.
+func (s *Stdout) Stop() error {
+	s.wg.Wait()
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.tar != nil {
+		err := s.tar.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.writer.Flush()
+}
+
+//writeToStdout copies the job payload to standard output, framed streams are spooled to a temp file first as their
+//size is written before them.
+func (s *Stdout) writeToStdout(Job job.Job) {
+	defer s.wg.Done()
+
+	var reader io.Reader
+	var size int64
+
+	switch Payload := Job.Payload.(type) {
+	case payload.Bytes:
+		reader, size = bytes.NewReader(Payload), int64(len(Payload))
+	case payload.Stream:
+		reader, size = Payload, -1
+		if s.config.Framing != "single" {
+			spooled, err := spool(Payload)
+			if err != nil {
+				Job.ResponseChan <- response.Error(err)
+				return
+			}
+			defer os.Remove(spooled.Name())
+			defer spooled.Close()
+
+			size, err = spooled.Seek(0, io.SeekCurrent)
+			if err == nil {
+				_, err = spooled.Seek(0, io.SeekStart)
+			}
+			if err != nil {
+				Job.ResponseChan <- response.Error(err)
+				return
+			}
+			reader = spooled
+		}
+	}
+
+	name, err := s.config.filename.Evaluate(Job.Data)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	err = s.write(name, reader, size)
+	if err != nil {
+		Job.ResponseChan <- response.Error(err)
+		return
+	}
+
+	Job.ResponseChan <- response.Ack()
+}
+
+//spool copies reader to a new temp file
+func spool(reader io.Reader) (*os.File, error) {
+	file, err := ioutil.TempFile("", "prism-stdout-")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return file, nil
+}
+
+//write copies reader to stdout with the configured framing, size is only needed for length and tar framing.
+func (s *Stdout) write(name string, reader io.Reader, size int64) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var err error
+	switch s.config.Framing {
+	case "length":
+		err = binary.Write(s.writer, binary.BigEndian, uint32(size))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(s.writer, reader)
+	case "tar":
+		err = s.tar.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    int64(s.config.Permission),
+			Size:    size,
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(s.tar, reader)
+		if err != nil {
+			return err
+		}
+		err = s.tar.Flush()
+	default:
+		_, err = io.Copy(s.writer, reader)
+	}
+	if err != nil {
+		return err
+	}
+
+	// flush so the next command in the shell pipeline receives images as soon as they're written.
+	return s.writer.Flush()
+}
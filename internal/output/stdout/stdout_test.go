@@ -0,0 +1,66 @@
+package stdout
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/job"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+func TestFraming(t *testing.T) {
+	tests := []struct {
+		framing string
+		want    string
+	}{
+		{framing: "single", want: "image"},
+		{framing: "length", want: "\x00\x00\x00\x05image"},
+		{framing: "tar", want: "image"},
+	}
+
+	for _, tt := range tests {
+		for _, stream := range []bool{false, true} {
+			var Payload payload.Payload = payload.Bytes("image")
+			if stream {
+				Payload = payload.Stream(bytes.NewReader([]byte("image")))
+			}
+
+			output := &bytes.Buffer{}
+			s := &Stdout{config: *defaultConfig(), logger: *zap.NewNop().Sugar(), writer: bufio.NewWriter(output)}
+			s.config.Framing = tt.framing
+			s.config.filename, _ = cfg.NewSelector(s.config.FileName)
+			if tt.framing == "tar" {
+				s.tar = tar.NewWriter(s.writer)
+			}
+
+			responseChan := make(chan response.Response, 1)
+			s.wg.Add(1)
+			s.writeToStdout(job.Job{Payload: Payload, Data: payload.Data{"_id": "a"}, ResponseChan: responseChan})
+			if Response := <-responseChan; !Response.Ack {
+				t.Fatalf("%s framing, stream %v: Ack = false, Error = %v", tt.framing, stream, Response.Error)
+			}
+
+			var got []byte
+			if tt.framing == "tar" {
+				reader := tar.NewReader(output)
+				header, err := reader.Next()
+				if err != nil || header.Name != "a" || header.Size != 5 {
+					t.Fatalf("%s framing, stream %v: header = %+v, error = %v", tt.framing, stream, header, err)
+				}
+				got, _ = ioutil.ReadAll(reader)
+			} else {
+				got = output.Bytes()
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("%s framing, stream %v: wrote %q, want %q", tt.framing, stream, got, tt.want)
+			}
+		}
+	}
+}
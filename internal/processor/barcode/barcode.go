@@ -0,0 +1,108 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/sherifabdlnaby/prism/pkg/barcode"
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Barcode is a read-only plugin that decodes QR codes and 1D barcodes (EAN-13, UPC-A, EAN-8, Code 128 and Code 39)
+// of images, it adds their text and position to payload.Data and can NoAck images with or without codes.
+type Barcode struct {
+	logger zap.SugaredLogger
+	config config
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Barcode{}
+}
+
+// Init barcode plugin
+func (b *Barcode) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	b.config = *defaultConfig()
+	err = config.Populate(&b.config)
+	if err != nil {
+		return err
+	}
+
+	b.logger = logger
+	return nil
+}
+
+// Start barcode plugin
+func (b *Barcode) Start() error {
+	return nil
+}
+
+// Stop barcode plugin
+func (b *Barcode) Stop() error {
+	return nil
+}
+
+// Decode decodes the image
+func (b *Barcode) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return b.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image
+func (b *Barcode) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return decode.Image(in)
+}
+
+// Process scans the image for codes
+func (b *Barcode) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	codes := barcode.Scan(in.(image.Image), b.formats()...)
+
+	list := make([]map[string]interface{}, len(codes))
+	formats := make([]string, len(codes))
+	for i, code := range codes {
+		points := make([][]int, len(code.Points))
+		for j, point := range code.Points {
+			points[j] = []int{point.X, point.Y}
+		}
+
+		bounds := code.Bounds()
+		list[i] = map[string]interface{}{
+			"format": code.Format,
+			"text":   code.Text,
+			"x":      bounds.Min.X,
+			"y":      bounds.Min.Y,
+			"width":  bounds.Dx(),
+			"height": bounds.Dy(),
+			"points": points,
+		}
+		formats[i] = code.Format
+	}
+	data[b.config.Field] = list
+
+	switch {
+	case b.config.Drop == "with_codes" && len(codes) > 0:
+		return response.Reject(CodeBarcodeFound, fmt.Sprintf("image has codes (%s)", strings.Join(formats, ", ")),
+			map[string]interface{}{"count": len(codes), "formats": formats})
+	case b.config.Drop == "without_codes" && len(codes) == 0:
+		return response.Reject(CodeBarcodeMissing, "image has no codes",
+			map[string]interface{}{"formats": b.formats()})
+	}
+
+	return response.Ack()
+}
+
+// formats return the formats scanned for
+func (b *Barcode) formats() []string {
+	if len(b.config.Formats) == 0 {
+		return barcode.Formats
+	}
+	return b.config.Formats
+}
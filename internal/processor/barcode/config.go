@@ -0,0 +1,20 @@
+package barcode
+
+type config struct {
+	// Formats scanned for, qr, ean13, upca, ean8, code128 and code39, all formats are scanned if empty.
+	Formats []string `validate:"dive,oneof=qr ean13 upca ean8 code128 code39"`
+
+	// Field is the payload.Data key of the list of codes found.
+	Field string `validate:"required"`
+
+	// Drop NoAcks images with_codes or without_codes, none acks all images.
+	Drop string `validate:"oneof=none with_codes without_codes"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Field: "_barcodes",
+		Drop:  "none",
+	}
+}
@@ -0,0 +1,7 @@
+package barcode
+
+// Codes of rejection reasons, codes found are reported with their formats.
+const (
+	CodeBarcodeFound   = "BARCODE_FOUND"
+	CodeBarcodeMissing = "BARCODE_MISSING"
+)
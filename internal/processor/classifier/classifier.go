@@ -0,0 +1,177 @@
+package classifier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/onnx"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Classifier is a read-only plugin that runs an image classification model (ONNX) on the CPU, it adds the best labels
+// and their scores to payload.Data and can NoAck images by label score, so models (e.g NSFW, quality or category) can
+// be swapped without code changes.
+type Classifier struct {
+	logger zap.SugaredLogger
+	config config
+	model  *onnx.Model
+	input  input
+	output string
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Classifier{}
+}
+
+// Init classifier plugin, loads the model and its labels
+func (c *Classifier) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	c.config = *defaultConfig()
+	err = config.Populate(&c.config)
+	if err != nil {
+		return err
+	}
+
+	buffer, err := ioutil.ReadFile(c.config.Model)
+	if err != nil {
+		return err
+	}
+
+	c.model, err = onnx.Load(buffer)
+	if err != nil {
+		return fmt.Errorf("failed to load model %s: %s", c.config.Model, err.Error())
+	}
+
+	if c.config.LabelsFile != "" {
+		c.config.Labels, err = readLabels(c.config.LabelsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.input, err = newInput(c.model, c.config)
+	if err != nil {
+		return err
+	}
+
+	c.output, err = find(c.model.Outputs(), c.config.Output)
+	if err != nil {
+		return err
+	}
+
+	// labels of thresholds must be labels of the model, otherwise they're silently never matched
+	labels, dynamic := c.labels()
+	c.config.Reject, err = resolve("reject", c.config.Reject, labels, dynamic)
+	if err != nil {
+		return err
+	}
+	c.config.Require, err = resolve("require", c.config.Require, labels, dynamic)
+	if err != nil {
+		return err
+	}
+
+	c.logger = logger
+	return nil
+}
+
+// Start classifier plugin
+func (c *Classifier) Start() error {
+	return nil
+}
+
+// Stop classifier plugin
+func (c *Classifier) Stop() error {
+	return nil
+}
+
+// Decode decodes the image
+func (c *Classifier) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return c.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image
+func (c *Classifier) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return decode.Image(in)
+}
+
+// Process classifies the image
+func (c *Classifier) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	outputs, err := c.model.Run(map[string]*onnx.Tensor{c.input.name: c.input.tensor(in.(image.Image))})
+	if err != nil {
+		return response.Error(err)
+	}
+
+	scores := activate(outputs[c.output].Data, c.config.Activation)
+	if len(scores) == 0 {
+		return response.Error(fmt.Errorf("model output is empty"))
+	}
+
+	result := c.classify(scores)
+	data[c.config.Field] = result.data(c.config.Top)
+
+	for label, threshold := range c.config.Reject {
+		score, ok := result.score(label)
+		if ok && score >= threshold {
+			return response.Reject(CodeLabelRejected,
+				fmt.Sprintf("image is classified as %s with score %.2f, maximum is %.2f", label, score, threshold),
+				map[string]interface{}{"label": label, "score": score, "threshold": threshold})
+		}
+	}
+
+	for label, threshold := range c.config.Require {
+		score, _ := result.score(label)
+		if score < threshold {
+			return response.Reject(CodeLabelRequired,
+				fmt.Sprintf("image is classified as %s with score %.2f, minimum is %.2f", label, score, threshold),
+				map[string]interface{}{"label": label, "score": score, "threshold": threshold})
+		}
+	}
+
+	return response.Ack()
+}
+
+// find return the name of the value with the given name, or the first value if name is empty.
+func find(values []onnx.Value, name string) (string, error) {
+	if name == "" {
+		return values[0].Name, nil
+	}
+
+	for _, value := range values {
+		if value.Name == name {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("model has no input or output named %s", name)
+}
+
+// readLabels reads a labels file, one label per line
+func readLabels(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	labels := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if label := strings.TrimSpace(scanner.Text()); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	return labels, scanner.Err()
+}
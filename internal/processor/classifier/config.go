@@ -0,0 +1,49 @@
+package classifier
+
+type config struct {
+	// Model is the path of an ONNX model file, Labels or LabelsFile (one label per line) name its output classes.
+	Model      string `validate:"required"`
+	Labels     []string
+	LabelsFile string `mapstructure:"labels_file"`
+
+	// Input and Output are the names of the model input and output, defaults to the first ones.
+	Input  string
+	Output string
+
+	// Width and Height the image is resized to, required if the model input size is dynamic.
+	Width  int `validate:"min=0"`
+	Height int `validate:"min=0"`
+	// Layout of the model input, nchw or nhwc, inferred from the model input shape if empty.
+	Layout string `validate:"omitempty,oneof=nchw nhwc"`
+	// BGR orders channels blue, green, red.
+	BGR bool
+
+	// Pixels (0-255) are multiplied by Scale, then normalized per channel with Mean and Std.
+	Scale float64
+	Mean  []float64 `validate:"max=3"`
+	Std   []float64 `validate:"max=3,dive,gt=0"`
+
+	// Activation applied to the model output, none if the model already outputs probabilities.
+	Activation string `validate:"oneof=none softmax sigmoid"`
+
+	// Field is the payload.Data key of the classification, Top is the number of best scores added to it.
+	Field string `validate:"required"`
+	Top   int    `validate:"min=1"`
+
+	// Reject NoAcks images having a label score >= its threshold, Require NoAcks images having a label score < its
+	// threshold. their labels must be labels of the model, or indexes of its unlabeled classes.
+	Reject  map[string]float64 `validate:"dive,min=0"`
+	Require map[string]float64 `validate:"dive,min=0"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Scale:      1.0 / 255,
+		Mean:       []float64{0, 0, 0},
+		Std:        []float64{1, 1, 1},
+		Activation: "none",
+		Field:      "_classification",
+		Top:        5,
+	}
+}
@@ -0,0 +1,137 @@
+package classifier
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/onnx"
+)
+
+// input converts images to the model input tensor
+type input struct {
+	name                    string
+	nhwc                    bool
+	width, height, channels int
+	bgr                     bool
+	scale                   float64
+	mean, std               []float64
+}
+
+// newInput resolves the input layout and size from the model input shape and the config.
+func newInput(model *onnx.Model, config config) (input, error) {
+	name, err := find(model.Inputs(), config.Input)
+	if err != nil {
+		return input{}, err
+	}
+
+	var shape []int
+	for _, value := range model.Inputs() {
+		if value.Name == name {
+			shape = value.Shape
+		}
+	}
+
+	if len(shape) != 4 {
+		return input{}, fmt.Errorf("model input %s must be an image batch (4 dimensions), got %v", name, shape)
+	}
+
+	in := input{name: name, bgr: config.BGR, scale: config.Scale, mean: config.Mean, std: config.Std}
+
+	switch {
+	case config.Layout != "":
+		in.nhwc = config.Layout == "nhwc"
+	case shape[1] == 1 || shape[1] == 3:
+	case shape[3] == 1 || shape[3] == 3:
+		in.nhwc = true
+	default:
+		return input{}, fmt.Errorf("can't infer the layout of model input %v, set layout", shape)
+	}
+
+	in.channels, in.height, in.width = shape[1], shape[2], shape[3]
+	if in.nhwc {
+		in.height, in.width, in.channels = shape[1], shape[2], shape[3]
+	}
+
+	if in.channels != 1 && in.channels != 3 {
+		return input{}, fmt.Errorf("model input must have 1 or 3 channels, got %d", in.channels)
+	}
+
+	// dynamic dimensions are set by config
+	in.width, err = dimension("width", in.width, config.Width)
+	if err != nil {
+		return input{}, err
+	}
+	in.height, err = dimension("height", in.height, config.Height)
+	if err != nil {
+		return input{}, err
+	}
+
+	if len(in.mean) != 1 && len(in.mean) != in.channels {
+		return input{}, fmt.Errorf("mean must have 1 or %d values", in.channels)
+	}
+	if len(in.std) != 1 && len(in.std) != in.channels {
+		return input{}, fmt.Errorf("std must have 1 or %d values", in.channels)
+	}
+
+	return in, nil
+}
+
+func dimension(name string, model, config int) (int, error) {
+	switch {
+	case model > 0 && config > 0 && model != config:
+		return 0, fmt.Errorf("model input %s is %d, got %d", name, model, config)
+	case model > 0:
+		return model, nil
+	case config > 0:
+		return config, nil
+	}
+	return 0, fmt.Errorf("model input %s is dynamic, %s must be set", name, name)
+}
+
+// tensor resizes the image to the input size and normalizes its pixels
+func (in input) tensor(img image.Image) *onnx.Tensor {
+	rgba := imaging.Resize(img, in.width, in.height)
+
+	var t *onnx.Tensor
+	if in.nhwc {
+		t = onnx.NewTensor(1, in.height, in.width, in.channels)
+	} else {
+		t = onnx.NewTensor(1, in.channels, in.height, in.width)
+	}
+
+	plane := in.width * in.height
+	for y := 0; y < in.height; y++ {
+		for x := 0; x < in.width; x++ {
+			offset := rgba.PixOffset(x, y)
+			r, g, b := float64(rgba.Pix[offset]), float64(rgba.Pix[offset+1]), float64(rgba.Pix[offset+2])
+
+			values := []float64{r, g, b}
+			switch {
+			case in.channels == 1:
+				values = []float64{0.299*r + 0.587*g + 0.114*b}
+			case in.bgr:
+				values = []float64{b, g, r}
+			}
+
+			for c, value := range values {
+				mean, std := in.mean[0], in.std[0]
+				if len(in.mean) > 1 {
+					mean = in.mean[c]
+				}
+				if len(in.std) > 1 {
+					std = in.std[c]
+				}
+
+				normalized := float32((value*in.scale - mean) / std)
+				if in.nhwc {
+					t.Data[(y*in.width+x)*in.channels+c] = normalized
+				} else {
+					t.Data[c*plane+y*in.width+x] = normalized
+				}
+			}
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,7 @@
+package classifier
+
+// Codes of rejection reasons, reported with the label, its score and threshold.
+const (
+	CodeLabelRejected = "LABEL_REJECTED"
+	CodeLabelRequired = "LABEL_REQUIRED"
+)
@@ -0,0 +1,158 @@
+package classifier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// result is the labels of the model output ordered by score
+type result struct {
+	labels []string
+	scores []float64
+}
+
+// classify labels the scores and orders them, outputs without a label are named by their index.
+func (c *Classifier) classify(scores []float32) result {
+	r := result{labels: make([]string, len(scores)), scores: make([]float64, len(scores))}
+	for i, score := range scores {
+		r.labels[i] = strconv.Itoa(i)
+		if i < len(c.config.Labels) {
+			r.labels[i] = c.config.Labels[i]
+		}
+		r.scores[i] = float64(score)
+	}
+
+	sort.Stable(r)
+	return r
+}
+
+// labels return the labels of the model output classes, classes without a label are named by their index. dynamic is
+// true if the number of classes is unknown, then only configured labels are returned.
+func (c *Classifier) labels() ([]string, bool) {
+	classes := 0
+	for _, value := range c.model.Outputs() {
+		if value.Name == c.output && len(value.Shape) > 0 {
+			classes = value.Shape[len(value.Shape)-1]
+		}
+	}
+
+	if classes <= 0 {
+		return c.config.Labels, true
+	}
+
+	labels := make([]string, classes)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i)
+		if i < len(c.config.Labels) {
+			labels[i] = c.config.Labels[i]
+		}
+	}
+
+	return labels, false
+}
+
+// resolve maps labels of thresholds to labels of the model, case insensitive as config keys may be lower-cased when
+// loaded. indexes of unlabeled classes are accepted if the number of classes is unknown.
+func resolve(field string, thresholds map[string]float64, labels []string, dynamic bool) (map[string]float64, error) {
+	resolved := make(map[string]float64, len(thresholds))
+
+	for name, threshold := range thresholds {
+		label, ok := "", false
+		for _, l := range labels {
+			if strings.EqualFold(l, name) {
+				label, ok = l, true
+				break
+			}
+		}
+
+		if index, err := strconv.Atoi(name); !ok && dynamic && err == nil && index >= len(labels) {
+			label, ok = name, true
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("%s label %s is not a label of the model, labels are: %s", field, name,
+				strings.Join(labels, ", "))
+		}
+
+		resolved[label] = threshold
+	}
+
+	return resolved, nil
+}
+
+func (r result) Len() int {
+	return len(r.scores)
+}
+
+func (r result) Less(i, j int) bool {
+	return r.scores[i] > r.scores[j]
+}
+
+func (r result) Swap(i, j int) {
+	r.labels[i], r.labels[j] = r.labels[j], r.labels[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
+
+// score return the score of a label, ok is false if the model has no such label.
+func (r result) score(label string) (float64, bool) {
+	for i := range r.labels {
+		if r.labels[i] == label {
+			return r.scores[i], true
+		}
+	}
+	return 0, false
+}
+
+// data return the best label and the top scores keyed by label
+func (r result) data(top int) map[string]interface{} {
+	if top > len(r.scores) {
+		top = len(r.scores)
+	}
+
+	scores := make(map[string]interface{}, top)
+	for i := 0; i < top; i++ {
+		scores[r.labels[i]] = r.scores[i]
+	}
+
+	return map[string]interface{}{
+		"label":  r.labels[0],
+		"score":  r.scores[0],
+		"scores": scores,
+	}
+}
+
+// activate applies the activation function to the model output
+func activate(output []float32, activation string) []float32 {
+	scores := make([]float32, len(output))
+	switch activation {
+	case "softmax":
+		max := float32(-math.MaxFloat32)
+		for _, value := range output {
+			if value > max {
+				max = value
+			}
+		}
+
+		sum := 0.0
+		for i, value := range output {
+			exp := math.Exp(float64(value - max))
+			scores[i] = float32(exp)
+			sum += exp
+		}
+
+		for i := range scores {
+			scores[i] = float32(float64(scores[i]) / sum)
+		}
+	case "sigmoid":
+		for i, value := range output {
+			scores[i] = float32(1 / (1 + math.Exp(-float64(value))))
+		}
+	default:
+		copy(scores, output)
+	}
+
+	return scores
+}
@@ -0,0 +1,40 @@
+package classifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	labels := []string{"Drawing", "Neutral", "Porn"}
+
+	tests := []struct {
+		name       string
+		thresholds map[string]float64
+		labels     []string
+		dynamic    bool
+		want       map[string]float64
+		ok         bool
+	}{
+		{name: "labels", thresholds: map[string]float64{"Porn": 0.8}, labels: labels, want: map[string]float64{"Porn": 0.8}, ok: true},
+		{name: "lower-cased", thresholds: map[string]float64{"porn": 0.8}, labels: labels, want: map[string]float64{"Porn": 0.8}, ok: true},
+		{name: "unknown label", thresholds: map[string]float64{"hentai": 0.8}, labels: labels},
+		{name: "index of unlabeled class", thresholds: map[string]float64{"3": 0.5}, labels: []string{"a", "b", "c", "3"}, want: map[string]float64{"3": 0.5}, ok: true},
+		{name: "index out of classes", thresholds: map[string]float64{"4": 0.5}, labels: labels},
+		{name: "index of dynamic classes", thresholds: map[string]float64{"4": 0.5}, labels: labels, dynamic: true, want: map[string]float64{"4": 0.5}, ok: true},
+		{name: "labeled index of dynamic classes", thresholds: map[string]float64{"1": 0.5}, labels: labels, dynamic: true},
+		{name: "none", labels: labels, want: map[string]float64{}, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve("reject", tt.thresholds, tt.labels, tt.dynamic)
+			if (err == nil) != tt.ok {
+				t.Fatalf("resolve() error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
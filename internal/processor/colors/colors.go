@@ -0,0 +1,95 @@
+package colors
+
+import (
+	"bytes"
+	"image"
+
+	"github.com/sherifabdlnaby/prism/pkg/colors"
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Fields are the payload.Data keys of extracted colours, colours are hex (#rrggbb).
+const (
+	DominantField = "_dominant_color"
+	PaletteField  = "_palette"
+	BlurhashField = "_blurhash"
+)
+
+// Colors is a read-only plugin that adds the dominant colour, a palette ordered by usage and a BlurHash placeholder of
+// the image to payload.Data, so they can be stored by outputs and used as placeholders while the image loads.
+type Colors struct {
+	logger zap.SugaredLogger
+	config config
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Colors{}
+}
+
+// Init colors plugin
+func (c *Colors) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	c.config = *defaultConfig()
+	err = config.Populate(&c.config)
+	if err != nil {
+		return err
+	}
+
+	c.logger = logger
+	return nil
+}
+
+// Start colors plugin
+func (c *Colors) Start() error {
+	return nil
+}
+
+// Stop colors plugin
+func (c *Colors) Stop() error {
+	return nil
+}
+
+// Decode decodes the image
+func (c *Colors) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return c.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image
+func (c *Colors) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return decode.Image(in)
+}
+
+// Process extracts the image colours
+func (c *Colors) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	img := in.(image.Image)
+
+	if dominant, ok := colors.Dominant(img); ok {
+		data[DominantField] = colors.Hex(dominant)
+	}
+
+	if c.config.Palette > 0 {
+		palette := colors.Palette(img, c.config.Palette)
+		hex := make([]string, len(palette))
+		for i, color := range palette {
+			hex[i] = colors.Hex(color)
+		}
+		data[PaletteField] = hex
+	}
+
+	if c.config.Blurhash {
+		hash, err := colors.BlurHash(img, c.config.ComponentsX, c.config.ComponentsY)
+		if err != nil {
+			return response.Error(err)
+		}
+		data[BlurhashField] = hash
+	}
+
+	return response.Ack()
+}
@@ -0,0 +1,21 @@
+package colors
+
+type config struct {
+	// Palette is the number of palette colours, 0 disables the palette.
+	Palette int `validate:"min=0,max=16"`
+
+	// Blurhash placeholder with ComponentsX by ComponentsY components, more components preserve more detail.
+	Blurhash    bool
+	ComponentsX int `mapstructure:"components_x" validate:"min=1,max=9"`
+	ComponentsY int `mapstructure:"components_y" validate:"min=1,max=9"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Palette:     5,
+		Blurhash:    true,
+		ComponentsX: 4,
+		ComponentsY: 3,
+	}
+}
@@ -0,0 +1,95 @@
+package goimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+)
+
+// animated is a decoded animated GIF, every frame is composed on the full canvas so operations apply to all of them
+// the same way.
+type animated struct {
+	frames    []image.Image
+	palettes  []color.Palette
+	delay     []int
+	loopCount int
+}
+
+// decodeAnimated decodes every frame of a GIF, composing each frame over the previous ones according to its disposal.
+func decodeAnimated(in []byte) (*animated, error) {
+	decoded, err := gif.DecodeAll(bytes.NewReader(in))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height)
+	for _, frame := range decoded.Image {
+		bounds = bounds.Union(frame.Bounds())
+	}
+
+	result := &animated{
+		frames:    make([]image.Image, 0, len(decoded.Image)),
+		palettes:  make([]color.Palette, 0, len(decoded.Image)),
+		delay:     decoded.Delay,
+		loopCount: decoded.LoopCount,
+	}
+
+	canvas := image.NewNRGBA(bounds)
+	previous := image.NewNRGBA(bounds)
+	for i, frame := range decoded.Image {
+		disposal := byte(0)
+		if i < len(decoded.Disposal) {
+			disposal = decoded.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		composed := image.NewNRGBA(bounds)
+		copy(composed.Pix, canvas.Pix)
+		result.frames = append(result.frames, composed)
+		result.palettes = append(result.palettes, frame.Palette)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+
+	return result, nil
+}
+
+// encode encodes frames as a GIF, every frame is mapped to its original palette and clears the canvas when done as
+// it covers all of it.
+func (a *animated) encode() ([]byte, error) {
+	output := &gif.GIF{
+		Image:     make([]*image.Paletted, len(a.frames)),
+		Delay:     a.delay,
+		Disposal:  make([]byte, len(a.frames)),
+		LoopCount: a.loopCount,
+	}
+
+	for i, frame := range a.frames {
+		bounds := frame.Bounds().Sub(frame.Bounds().Min)
+		paletted := image.NewPaletted(bounds, a.palettes[i])
+		draw.FloydSteinberg.Draw(paletted, bounds, frame, frame.Bounds().Min)
+
+		output.Image[i] = paletted
+		output.Disposal[i] = gif.DisposalBackground
+	}
+
+	buffer := &bytes.Buffer{}
+	err := gif.EncodeAll(buffer, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,106 @@
+package goimage
+
+import "github.com/sherifabdlnaby/prism/pkg/focus"
+
+// config struct used to decode YAML into, it's the same as the vips plugin for the operations it supports.
+type config struct {
+	// Operations is either a map of operations applied in a single stage, or an ordered list of operations where every
+	// operation is applied in its own stage.
+	Operations interface{}
+	Export     export
+
+	// Animated is what to do with animated GIF/WebP, "all" processes every frame of animated GIFs and needs gif export,
+	// content aware anchors are detected on the first frame and used for every frame. Go has no animated WebP decoder so they're always rejected.
+	Animated string `validate:"oneof=first_frame reject all"`
+
+	// AutoRotate rotates the image according to its EXIF orientation before any operation.
+	AutoRotate bool `mapstructure:"auto_rotate"`
+
+	// Cascade is the path of a pigo cascade file used to detect faces for the face anchor instead of the built-in one,
+	// faces the cascade doesn't detect are located by skin tone.
+	Cascade string
+	cascade *focus.Cascade
+
+	stages []*operations
+}
+
+// defaultConfig return default configuration for goimage plugin configuration
+func defaultConfig() *config {
+	return &config{
+		Animated:   "first_frame",
+		AutoRotate: true,
+		Export: export{
+			Raw: *exportDefaults(),
+		},
+	}
+}
+
+// operationsDefaults return default configuration for a single stage of operations
+func operationsDefaults() *operations {
+	return &operations{
+		Resize: resize{
+			Raw: *resizeDefaults(),
+		},
+		Flip: flip{
+			Raw: *flipDefaults(),
+		},
+		Blur: blur{
+			Raw: *blurDefaults(),
+		},
+		Rotate: rotate{
+			Raw: *rotateDefaults(),
+		},
+		Crop: crop{
+			Raw: *cropDefaults(),
+		},
+	}
+}
+
+// resizeDefaults return default configuration for resize operation configuration
+func resizeDefaults() *resizeRawConfig {
+	return &resizeRawConfig{
+		Width:    "",
+		Height:   "",
+		Strategy: "embed",
+		Anchor:   "center",
+	}
+}
+
+// flipDefaults return default configuration for flip operation configuration
+func flipDefaults() *flipRawConfig {
+	return &flipRawConfig{
+		Direction: "",
+	}
+}
+
+// blurDefaults return default configuration for blur operation configuration
+func blurDefaults() *blurRawConfig {
+	return &blurRawConfig{
+		Sigma: "",
+	}
+}
+
+// rotateDefaults return default configuration for rotate operation configuration
+func rotateDefaults() *rotateRawConfig {
+	return &rotateRawConfig{
+		Angle: "",
+	}
+}
+
+// cropDefaults return default configuration for crop operation configuration
+func cropDefaults() *cropRawConfig {
+	return &cropRawConfig{
+		Width:  "",
+		Height: "",
+		Anchor: "center",
+	}
+}
+
+// exportDefaults return default exporting configuration for goimage plugin
+func exportDefaults() *exportRawConfig {
+	return &exportRawConfig{
+		Format:      "jpeg",
+		Quality:     85,
+		Compression: 6,
+	}
+}
@@ -0,0 +1,174 @@
+package goimage
+
+import (
+	"fmt"
+	"image"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// detection runs on a thumbnail no larger than this
+const detectionSize = 512
+
+type crop struct {
+	Raw     cropRawConfig `mapstructure:",squash"`
+	width   cfg.Selector
+	height  cfg.Selector
+	anchor  cfg.Selector
+	cascade *focus.Cascade
+}
+
+type cropRawConfig struct {
+	Width  string
+	Height string
+	Anchor string
+}
+
+func (o *crop) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *cropDefaults() {
+		return false, nil
+	}
+
+	o.width, err = cfg.NewSelector(o.Raw.Width)
+	if err != nil {
+		return false, err
+	}
+
+	o.height, err = cfg.NewSelector(o.Raw.Height)
+	if err != nil {
+		return false, err
+	}
+
+	o.anchor, err = cfg.NewSelector(o.Raw.Anchor)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Apply crops the image to width x height, like libvips the image is scaled to cover the size first unless it's
+// smaller in both dimensions, and a missing dimension is kept as is.
+func (o *crop) Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+	width, err := o.width.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := o.height.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor, err := o.anchor.Evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// // // // // // //
+
+	bounds := img.Bounds()
+	inWidth, inHeight := bounds.Dx(), bounds.Dy()
+	w, h := int(width), int(height)
+
+	if (w <= 0 && h <= 0) || (w >= inWidth && h >= inHeight) {
+		return img, nil
+	}
+
+	point, err := anchors.point(o, img, anchor, data, o.cascade)
+	if err != nil {
+		return nil, err
+	}
+
+	if w <= 0 || h <= 0 {
+		if w <= 0 || w > inWidth {
+			w = inWidth
+		}
+		if h <= 0 || h > inHeight {
+			h = inHeight
+		}
+		return imaging.Crop(img, focus.Region(inWidth, inHeight, w, h, point)), nil
+	}
+
+	return imaging.Resize(imaging.Crop(img, focus.Region(inWidth, inHeight, w, h, point)), w, h), nil
+}
+
+// anchors caches the anchor point of every operation, so frames of an animation are cropped around the same point
+// and don't jitter, the point is detected on the first frame.
+type anchors map[operation]focus.Point
+
+// point returns the cached anchor point of op, or detects it on img.
+func (a anchors) point(op operation, img image.Image, anchor string, data payload.Data, cascade *focus.Cascade) (focus.Point, error) {
+	if point, ok := a[op]; ok {
+		return point, nil
+	}
+
+	point, err := anchorPoint(img, anchor, data, cascade)
+	if err != nil {
+		return focus.Point{}, err
+	}
+
+	a[op] = point
+
+	return point, nil
+}
+
+// anchorPoint return the point to crop around, edge anchors are points on the edges as the crop region is kept inside
+// the image. A focal point supplied by the client takes precedence for focal, face and entropy anchors.
+func anchorPoint(img image.Image, anchor string, data payload.Data, cascade *focus.Cascade) (focus.Point, error) {
+	switch anchor {
+	case "center":
+		return focus.Center, nil
+	case "north":
+		return focus.Point{X: 0.5, Y: 0}, nil
+	case "east":
+		return focus.Point{X: 1, Y: 0.5}, nil
+	case "south":
+		return focus.Point{X: 0.5, Y: 1}, nil
+	case "west":
+		return focus.Point{X: 0, Y: 0.5}, nil
+	case "focal", "face", "entropy", "smart":
+	default:
+		return focus.Point{}, fmt.Errorf("invalid value for field [anchor], got: %s", anchor)
+	}
+
+	if point, ok := focus.FromData(data); ok && anchor != "smart" {
+		return point, nil
+	}
+
+	if anchor == "focal" {
+		return focus.Center, nil
+	}
+
+	// detection on a thumbnail, points are relative so they apply to the full image
+	bounds := img.Bounds()
+	scale := float64(detectionSize) / float64(bounds.Dx())
+	if bounds.Dy() > bounds.Dx() {
+		scale = float64(detectionSize) / float64(bounds.Dy())
+	}
+	if scale < 1 {
+		img = imaging.Resize(img, round(float64(bounds.Dx())*scale), round(float64(bounds.Dy())*scale))
+	}
+	gray := focus.Gray(img)
+
+	// faces are located by skin tone if the cascade doesn't detect any
+	if anchor == "face" {
+		point, ok := focus.Faces(cascade.Detect(gray), gray.Bounds().Dx(), gray.Bounds().Dy())
+		if ok {
+			return point, nil
+		}
+
+		point, ok = focus.Skin(img)
+		if ok {
+			return point, nil
+		}
+	}
+
+	// libvips smart crop is attention based, entropy is the closest.
+	return focus.Entropy(gray), nil
+}
@@ -0,0 +1,59 @@
+package goimage
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/tiff"
+)
+
+type export struct {
+	Raw exportRawConfig `mapstructure:",squash"`
+}
+
+// exportRawConfig is the subset of the vips plugin export config that Go encoders support, other fields are ignored,
+// Go encoders never write metadata so it's always stripped.
+type exportRawConfig struct {
+	Format      string `validate:"oneof=jpg jpeg png gif tiff"`
+	Quality     int    `validate:"min=1,max=100"`
+	Compression int    `validate:"min=1,max=9"`
+}
+
+// Encode encodes the image in the export format
+func (o *export) Encode(img image.Image) ([]byte, error) {
+	var err error
+	buffer := &bytes.Buffer{}
+
+	switch o.Raw.Format {
+	case "jpeg", "jpg":
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: o.Raw.Quality})
+	case "png":
+		encoder := png.Encoder{CompressionLevel: o.compressionLevel()}
+		err = encoder.Encode(buffer, img)
+	case "gif":
+		err = gif.Encode(buffer, img, nil)
+	case "tiff":
+		err = tiff.Encode(buffer, img, &tiff.Options{Compression: tiff.Deflate})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// compressionLevel maps zlib compression levels (1-9) to the levels of the Go png encoder
+func (o *export) compressionLevel() png.CompressionLevel {
+	switch {
+	case o.Raw.Compression <= 3:
+		return png.BestSpeed
+	case o.Raw.Compression >= 8:
+		return png.BestCompression
+	default:
+		return png.DefaultCompression
+	}
+}
@@ -0,0 +1,60 @@
+package goimage
+
+import (
+	"fmt"
+	"image"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type flip struct {
+	Raw       flipRawConfig `mapstructure:",squash"`
+	direction cfg.Selector
+}
+
+type flipRawConfig struct {
+	Direction string
+}
+
+func (o *flip) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *flipDefaults() {
+		return false, nil
+	}
+
+	o.direction, err = cfg.NewSelector(o.Raw.Direction)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Apply flips the image, directions are the axis flipped around as in the vips plugin (horizontal is top to bottom).
+func (o *flip) Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+
+	// --------------------------------------------------------------------
+
+	direction, err := o.direction.Evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// --------------------------------------------------------------------
+
+	switch direction {
+	case "horizontal":
+		return imaging.Flip(img), nil
+	case "vertical":
+		return imaging.Flop(img), nil
+	case "both":
+		return imaging.Flop(imaging.Flip(img)), nil
+	case "none":
+		return img, nil
+	default:
+		return nil, fmt.Errorf("invalid value for field [direction], got: %s", direction)
+	}
+}
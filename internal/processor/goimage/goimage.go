@@ -0,0 +1,230 @@
+package goimage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io/ioutil"
+
+	"github.com/sherifabdlnaby/prism/pkg/animation"
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/metadata"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// GoImage is a processing plugin that resize, crop, flip, rotate and blur images in pure Go, it accepts the same
+// operations config as the vips plugin, so pipelines can switch between them where libvips isn't available.
+type GoImage struct {
+	logger zap.SugaredLogger
+	config config
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &GoImage{}
+}
+
+//Init Initialize Plugin based on parsed operations
+func (g *GoImage) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	g.config = *defaultConfig()
+	err = config.Populate(&g.config)
+	if err != nil {
+		return err
+	}
+
+	if g.config.Animated == "all" && g.config.Export.Raw.Format != "gif" {
+		return fmt.Errorf("animated [all] requires gif export format")
+	}
+
+	// load face detection cascade
+	g.config.cascade = focus.DefaultCascade()
+	if g.config.Cascade != "" {
+		buffer, err := ioutil.ReadFile(g.config.Cascade)
+		if err != nil {
+			return err
+		}
+
+		g.config.cascade, err = focus.LoadCascade(buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	// init operations
+	g.config.stages, err = newStages(g.config.Operations, g.config.cascade)
+	if err != nil {
+		return err
+	}
+
+	g.logger = logger
+	return nil
+}
+
+//Start Start the plugin to begin receiving input
+func (g *GoImage) Start() error {
+	return nil
+}
+
+//Stop Stop plugin gracefully
+func (g *GoImage) Stop() error {
+	return nil
+}
+
+//Decode Decode input Bytes into an image, oriented according to its EXIF orientation.
+func (g *GoImage) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	// Go decoders load only the first frame of animated images, so they're either reduced to it, rejected, or
+	// every frame of GIFs is decoded.
+	if g.config.Animated != "first_frame" {
+		info, err := animation.Inspect(in)
+		if err != nil {
+			return nil, response.NoAck(err)
+		}
+
+		if info.Animated && g.config.Animated == "all" && bytes.HasPrefix(in, []byte("GIF")) {
+			img, err := decodeAnimated(in)
+			if err != nil {
+				return nil, response.NoAck(err)
+			}
+			return img, response.ACK
+		}
+
+		if info.Animated {
+			return nil, response.NoAck(fmt.Errorf("animated images are not supported"))
+		}
+	}
+
+	img, Response := decode.Image(bytes.NewReader(in))
+	if !Response.Ack {
+		return nil, Response
+	}
+
+	// images with broken metadata are processed as is.
+	if g.config.AutoRotate {
+		segments, err := metadata.Read(in)
+		if err == nil && segments.EXIF != nil {
+			img = orient(img, orientation(segments.EXIF))
+		}
+	}
+
+	return img, response.ACK
+}
+
+//DecodeStream Decode input Stream into an image
+func (g *GoImage) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	buff, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, response.Error(err)
+	}
+
+	return g.Decode(buff, data)
+}
+
+//orientation return EXIF orientation, 0 if it has none.
+func orientation(exif []byte) int {
+	parsed, err := metadata.ParseExif(exif)
+	if err != nil {
+		return 0
+	}
+
+	return parsed.Orientation
+}
+
+//orient transforms the image to normal orientation from an EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.Flop(img)
+	case 3:
+		return imaging.Rotate(img, 180)
+	case 4:
+		return imaging.Flip(img)
+	case 5:
+		return imaging.Flop(imaging.Rotate(img, 90))
+	case 6:
+		return imaging.Rotate(img, 90)
+	case 7:
+		return imaging.Flop(imaging.Rotate(img, 270))
+	case 8:
+		return imaging.Rotate(img, 270)
+	default:
+		return img
+	}
+}
+
+//Process applies every stage of operations to the image
+func (g *GoImage) Process(in payload.DecodedImage, data payload.Data) (payload.DecodedImage, response.Response) {
+	if in, ok := in.(*animated); ok {
+		frames := make([]image.Image, len(in.frames))
+		anchors := make(anchors)
+		for i, frame := range in.frames {
+			img, err := g.apply(frame, data, anchors)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+			frames[i] = img
+		}
+
+		return &animated{frames: frames, palettes: in.palettes, delay: in.delay, loopCount: in.loopCount}, response.ACK
+	}
+
+	img, err := g.apply(in.(image.Image), data, make(anchors))
+	if err != nil {
+		return nil, response.Error(err)
+	}
+
+	return img, response.ACK
+}
+
+//apply applies every stage of operations to img
+func (g *GoImage) apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+	var err error
+	for _, stage := range g.config.stages {
+		img, err = stage.Apply(img, data, anchors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return img, nil
+}
+
+//Encode Encodes the image according to export config and returns it as a byte buffer
+func (g *GoImage) Encode(in payload.DecodedImage, data payload.Data) (payload.Bytes, response.Response) {
+	if in, ok := in.(*animated); ok {
+		bytes, err := in.encode()
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		bounds := in.frames[0].Bounds()
+		data["_width"], data["_height"] = bounds.Dx(), bounds.Dy()
+		data["_format"] = "gif"
+		data[animation.AnimatedField] = true
+		data[animation.FramesField] = len(in.frames)
+
+		return bytes, response.ACK
+	}
+
+	img := in.(image.Image)
+
+	bytes, err := g.config.Export.Encode(img)
+	if err != nil {
+		return nil, response.Error(err)
+	}
+
+	bounds := img.Bounds()
+	data["_width"], data["_height"] = bounds.Dx(), bounds.Dy()
+	data["_format"] = g.config.Export.Raw.Format
+	data[animation.AnimatedField] = false
+	data[animation.FramesField] = 1
+
+	return bytes, response.ACK
+}
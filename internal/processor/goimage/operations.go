@@ -0,0 +1,133 @@
+package goimage
+
+import (
+	"fmt"
+	"image"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// supported operations, other operations of the vips plugin are rejected instead of silently ignored.
+var supported = map[string]bool{
+	"resize": true,
+	"flip":   true,
+	"blur":   true,
+	"rotate": true,
+	"crop":   true,
+}
+
+// operations used to decode the operations: field in YAML file, operations of a stage are applied in the same order
+// as libvips (rotate, flip, resize, crop then blur), so both plugins give the same result.
+type operations struct {
+	Resize resize
+	Flip   flip
+	Blur   blur
+	Rotate rotate
+	Crop   crop
+
+	// for internal use
+	operations []operation
+}
+
+// newStages decode operations config into stages, a map of operations is a single stage applied in a fixed order,
+// and a list of operations is applied in order where every operation is in its own stage.
+func newStages(raw interface{}, cascade *focus.Cascade) ([]*operations, error) {
+	switch raw := raw.(type) {
+	case nil:
+		stage := operationsDefaults()
+		return []*operations{stage}, stage.Init(cascade)
+	case map[string]interface{}:
+		stage, err := newStage(raw, cascade)
+		if err != nil {
+			return nil, err
+		}
+		return []*operations{stage}, nil
+	case []interface{}:
+		stages := make([]*operations, 0, len(raw))
+		for i, item := range raw {
+			op, ok := item.(map[string]interface{})
+			if !ok || len(op) != 1 {
+				return nil, fmt.Errorf("operations[%d] must be a single operation", i)
+			}
+
+			stage, err := newStage(op, cascade)
+			if err != nil {
+				return nil, fmt.Errorf("operations[%d]: %s", i, err.Error())
+			}
+
+			if len(stage.operations) == 0 {
+				for name := range op {
+					return nil, fmt.Errorf("operations[%d]: empty operation [%s]", i, name)
+				}
+			}
+
+			stages = append(stages, stage)
+		}
+		return stages, nil
+	default:
+		return nil, fmt.Errorf("operations must be either a map or a list of operations")
+	}
+}
+
+func newStage(raw map[string]interface{}, cascade *focus.Cascade) (*operations, error) {
+	for name := range raw {
+		if !supported[name] {
+			return nil, fmt.Errorf("operation [%s] is not supported by goimage, use vips", name)
+		}
+	}
+
+	stage := operationsDefaults()
+
+	err := cfg.NewConfig(raw).Populate(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stage.Init(cascade)
+	if err != nil {
+		return nil, err
+	}
+
+	return stage, nil
+}
+
+// operation represent a single operation applied to the image
+type operation interface {
+	Init() (bool, error)
+	Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error)
+}
+
+// Init each operation and validate decoded config
+func (o *operations) Init(cascade *focus.Cascade) error {
+	o.Resize.cascade = cascade
+	o.Crop.cascade = cascade
+
+	for _, op := range []operation{&o.Rotate, &o.Flip, &o.Resize, &o.Crop, &o.Blur} {
+		// Init every operation and add them if they're active.
+		ok, err := op.Init()
+		if err != nil {
+			return err
+		}
+		if ok {
+			o.operations = append(o.operations, op)
+		}
+	}
+
+	return nil
+}
+
+// Apply applies operations to the image
+func (o *operations) Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+	var err error
+
+	for _, op := range o.operations {
+		img, err = op.Apply(img, data, anchors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return img, nil
+}
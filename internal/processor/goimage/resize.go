@@ -0,0 +1,196 @@
+package goimage
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type resize struct {
+	Raw       resizeRawConfig `mapstructure:",squash"`
+	width     cfg.Selector
+	height    cfg.Selector
+	maxHeight cfg.Selector
+	maxWidth  cfg.Selector
+	minHeight cfg.Selector
+	minWidth  cfg.Selector
+	strategy  cfg.Selector
+	anchor    cfg.Selector
+	cascade   *focus.Cascade
+}
+
+type resizeRawConfig struct {
+	Width     string
+	Height    string
+	MaxHeight string `mapstructure:"max_height"`
+	MaxWidth  string `mapstructure:"max_width"`
+	MinHeight string `mapstructure:"min_height"`
+	MinWidth  string `mapstructure:"min_width"`
+	Strategy  string
+	Anchor    string
+}
+
+func (o *resize) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *resizeDefaults() {
+		return false, nil
+	}
+
+	o.width, err = cfg.NewSelector(o.Raw.Width)
+	if err != nil {
+		return false, err
+	}
+
+	o.height, err = cfg.NewSelector(o.Raw.Height)
+	if err != nil {
+		return false, err
+	}
+
+	o.maxHeight, err = cfg.NewSelector(o.Raw.MaxHeight)
+	if err != nil {
+		return false, err
+	}
+
+	o.maxWidth, err = cfg.NewSelector(o.Raw.MaxWidth)
+	if err != nil {
+		return false, err
+	}
+
+	o.minHeight, err = cfg.NewSelector(o.Raw.MinHeight)
+	if err != nil {
+		return false, err
+	}
+
+	o.minWidth, err = cfg.NewSelector(o.Raw.MinWidth)
+	if err != nil {
+		return false, err
+	}
+
+	o.strategy, err = cfg.NewSelector(o.Raw.Strategy)
+	if err != nil {
+		return false, err
+	}
+
+	o.anchor, err = cfg.NewSelector(o.Raw.Anchor)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *resize) Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+
+	// --------------------------------------------------------------------
+
+	width, err := o.width.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := o.height.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	maxWidth, err := o.maxWidth.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	maxHeight, err := o.maxHeight.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	minWidth, err := o.minWidth.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	minHeight, err := o.minHeight.EvaluateInt64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := o.strategy.Evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// --------------------------------------------------------------------
+
+	bounds := img.Bounds()
+	inWidth, inHeight := bounds.Dx(), bounds.Dy()
+
+	w := limit(int(width), int(maxWidth), int(minWidth), inWidth)
+	h := limit(int(height), int(maxHeight), int(minHeight), inHeight)
+
+	// --------------------------------------------------------------------
+
+	switch strategy {
+	case "embed":
+		if w > 0 && h > 0 {
+			factor := math.Max(float64(inWidth)/float64(w), float64(inHeight)/float64(h))
+			scaled := imaging.Resize(img, round(float64(inWidth)/factor), round(float64(inHeight)/factor))
+			return imaging.Embed(scaled, w, h), nil
+		}
+	case "crop":
+		if w > 0 && h > 0 {
+			anchor, err := o.anchor.Evaluate(data)
+			if err != nil {
+				return nil, err
+			}
+
+			point, err := anchors.point(o, img, anchor, data, o.cascade)
+			if err != nil {
+				return nil, err
+			}
+
+			return imaging.Resize(imaging.Crop(img, focus.Region(inWidth, inHeight, w, h, point)), w, h), nil
+		}
+	case "stretch":
+	default:
+		return nil, fmt.Errorf("invalid value for field [strategy], got: %s", strategy)
+	}
+
+	if w == 0 && h == 0 {
+		return img, nil
+	}
+
+	// missing dimension keeps the aspect ratio
+	if w == 0 {
+		w = round(float64(inWidth) * float64(h) / float64(inHeight))
+	}
+	if h == 0 {
+		h = round(float64(inHeight) * float64(w) / float64(inWidth))
+	}
+
+	return imaging.Resize(img, w, h), nil
+}
+
+// limit applies max and min limits to a dimension like libvips, limits apply only if the input size exceeds them.
+func limit(size, max, min, input int) int {
+	if max > 0 && input > max && (size == 0 || size > max) {
+		size = max
+	}
+
+	if min > 0 && input < min && (size == 0 || size < min) {
+		size = min
+	}
+
+	return size
+}
+
+func round(value float64) int {
+	if value < 1 {
+		return 1
+	}
+	return int(math.Floor(value + 0.5))
+}
@@ -0,0 +1,56 @@
+package goimage
+
+import (
+	"fmt"
+	"image"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type rotate struct {
+	Raw   rotateRawConfig `mapstructure:",squash"`
+	angle cfg.Selector
+}
+
+type rotateRawConfig struct {
+	Angle string
+}
+
+func (o *rotate) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *rotateDefaults() {
+		return false, nil
+	}
+
+	o.angle, err = cfg.NewSelector(o.Raw.Angle)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *rotate) Apply(img image.Image, data payload.Data, anchors anchors) (image.Image, error) {
+
+	angle, err := o.angle.Evaluate(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch angle {
+	case "0":
+		return img, nil
+	case "90":
+		return imaging.Rotate(img, 90), nil
+	case "180":
+		return imaging.Rotate(img, 180), nil
+	case "270":
+		return imaging.Rotate(img, 270), nil
+	default:
+		return nil, fmt.Errorf("invalid value for field [angle], got: %s", angle)
+	}
+}
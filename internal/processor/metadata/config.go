@@ -0,0 +1,18 @@
+package metadata
+
+type config struct {
+	Exif bool
+	GPS  bool `mapstructure:"gps"`
+	IPTC bool `mapstructure:"iptc"`
+	XMP  bool `mapstructure:"xmp"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Exif: true,
+		GPS:  true,
+		IPTC: true,
+		XMP:  true,
+	}
+}
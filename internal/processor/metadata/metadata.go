@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"io/ioutil"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	meta "github.com/sherifabdlnaby/prism/pkg/metadata"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Metadata is a read-only plugin that extracts EXIF (camera, capture time, orientation, GPS), IPTC and XMP metadata
+// into payload.Data under _exif, _iptc and _xmp, and the embedded ICC profile description and colour space under
+// _icc_profile and _colorspace.
+// Images with corrupt metadata are still acknowledged.
+type Metadata struct {
+	logger zap.SugaredLogger
+	config config
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Metadata{}
+}
+
+// Init metadata plugin
+func (m *Metadata) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	m.config = *defaultConfig()
+	err = config.Populate(&m.config)
+	if err != nil {
+		return err
+	}
+
+	m.logger = logger
+	return nil
+}
+
+// Start metadata plugin
+func (m *Metadata) Start() error {
+	return nil
+}
+
+// Stop metadata plugin
+func (m *Metadata) Stop() error {
+	return nil
+}
+
+// Decode finds metadata segments of the image
+func (m *Metadata) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	segments, err := meta.Read(in)
+	if err != nil {
+		m.logger.Debugw("failed to read image metadata", "error", err)
+	}
+
+	return segments, response.Ack()
+}
+
+// DecodeStream finds metadata segments of the image
+func (m *Metadata) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	buffer, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, response.Error(err)
+	}
+
+	return m.Decode(buffer, data)
+}
+
+// Process parses metadata segments and adds them to payload.Data
+func (m *Metadata) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	segments := in.(meta.Segments)
+
+	if m.config.Exif && segments.EXIF != nil {
+		exif, err := meta.ParseExif(segments.EXIF)
+		if err != nil {
+			m.logger.Debugw("failed to parse exif", "error", err)
+		} else {
+			if !m.config.GPS {
+				exif.GPS = nil
+			}
+			data[meta.ExifField] = exif.Fields()
+		}
+	}
+
+	if m.config.IPTC && segments.IPTC != nil {
+		data[meta.IPTCField] = meta.ParseIPTC(segments.IPTC)
+	}
+
+	if m.config.XMP && segments.XMP != nil {
+		xmp, err := meta.ParseXMP(segments.XMP)
+		if err != nil {
+			m.logger.Debugw("failed to parse xmp", "error", err)
+		} else {
+			data[meta.XMPField] = xmp
+		}
+	}
+
+	if description := meta.ProfileDescription(segments.ICC); description != "" {
+		data[meta.ProfileField] = description
+	}
+
+	if space := meta.ProfileColorspace(segments.ICC); space != "" {
+		data[meta.ColorspaceField] = space
+	}
+
+	return response.Ack()
+}
@@ -0,0 +1,107 @@
+package nude
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/sherifabdlnaby/prism/internal/processor/nude/gonude"
+	"github.com/sherifabdlnaby/prism/pkg/imaging"
+)
+
+// censor applies the configured mode to the configured area of detected skin regions
+func (d *Censor) censor(img image.Image, result detection) image.Image {
+	// original behaviour, paint skin pixels
+	if d.config.Mode == "fill" && d.config.Area == "skin" {
+		return gonude.CensorSkinPixels(img, result.skin, d.config.rgba)
+	}
+
+	bounds := img.Bounds()
+	mask := image.NewAlpha(bounds)
+
+	switch d.config.Area {
+	case "skin":
+		for _, skin := range result.skin {
+			for _, pixel := range skin {
+				mask.SetAlpha(pixel.X, pixel.Y, opaque)
+			}
+		}
+	case "box":
+		for _, r := range result.regions {
+			draw.Draw(mask, r.bounds, image.Opaque, image.Point{}, draw.Src)
+		}
+	case "hull":
+		for _, r := range result.regions {
+			hull := r.hull
+			if len(hull) < 3 {
+				draw.Draw(mask, r.bounds, image.Opaque, image.Point{}, draw.Src)
+				continue
+			}
+			area := r.bounds.Intersect(bounds)
+			for y := area.Min.Y; y < area.Max.Y; y++ {
+				for x := area.Min.X; x < area.Max.X; x++ {
+					if inside(hull, image.Pt(x, y)) {
+						mask.SetAlpha(x, y, opaque)
+					}
+				}
+			}
+		}
+	}
+
+	// effects are only computed on the area around censored pixels, with a margin for blur to sample.
+	area := image.Rectangle{}
+	for _, r := range result.regions {
+		area = area.Union(r.bounds)
+	}
+	for _, skin := range result.skin {
+		area = area.Union(skin.Bounds())
+	}
+	margin := int(3 * d.config.Strength)
+	area = area.Inset(-margin).Intersect(bounds)
+	if area.Empty() {
+		return img
+	}
+
+	var effect image.Image
+	switch d.config.Mode {
+	case "fill":
+		effect = image.NewUniform(d.config.rgba)
+	case "blur":
+		effect = imaging.Blur(imaging.Crop(img, area.Sub(bounds.Min)), d.config.Strength, 0)
+	case "pixelate":
+		effect = imaging.Pixelate(imaging.Crop(img, area.Sub(bounds.Min)), int(d.config.Strength))
+	}
+
+	// cropped effects start at (0, 0)
+	effectOrigin := image.Point{}
+	if d.config.Mode == "fill" {
+		effectOrigin = area.Min
+	}
+
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Src)
+	draw.DrawMask(dst, area, effect, effectOrigin, mask, area.Min, draw.Over)
+
+	return dst
+}
+
+var opaque = color.Alpha{A: 255}
+
+// inside return true if point is inside or on the edge of a convex polygon
+func inside(polygon []image.Point, point image.Point) bool {
+	positive, negative := false, false
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		cross := (b.X-a.X)*(point.Y-a.Y) - (b.Y-a.Y)*(point.X-a.X)
+		if cross > 0 {
+			positive = true
+		}
+		if cross < 0 {
+			negative = true
+		}
+		if positive && negative {
+			return false
+		}
+	}
+	return true
+}
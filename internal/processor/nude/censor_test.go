@@ -0,0 +1,219 @@
+package nude
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+func TestCensor(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	box := image.Rect(2, 2, 6, 6)
+	triangle := []image.Point{{2, 2}, {5, 5}, {2, 5}}
+
+	// columns are a gradient so pixelated blocks differ from the original
+	gradient := func(x, y int) color.RGBA {
+		v := uint8(x * 32)
+		return color.RGBA{R: v, G: v, B: v, A: 255}
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		area     string
+		strength float64
+		regions  []region
+		want     func(x, y int) color.RGBA
+	}{
+		{
+			name:    "fill box",
+			mode:    "fill",
+			area:    "box",
+			regions: []region{{bounds: box}},
+			want: func(x, y int) color.RGBA {
+				if image.Pt(x, y).In(box) {
+					return red
+				}
+				return gradient(x, y)
+			},
+		},
+		{
+			name:    "fill hull",
+			mode:    "fill",
+			area:    "hull",
+			regions: []region{{bounds: box, hull: triangle}},
+			want: func(x, y int) color.RGBA {
+				if x >= 2 && y <= 5 && x <= y {
+					return red
+				}
+				return gradient(x, y)
+			},
+		},
+		{
+			name:    "hull of a line fills its box",
+			mode:    "fill",
+			area:    "hull",
+			regions: []region{{bounds: box, hull: []image.Point{{2, 2}, {5, 5}}}},
+			want: func(x, y int) color.RGBA {
+				if image.Pt(x, y).In(box) {
+					return red
+				}
+				return gradient(x, y)
+			},
+		},
+		{
+			name:     "pixelate box",
+			mode:     "pixelate",
+			area:     "box",
+			strength: 2,
+			regions:  []region{{bounds: box}},
+			want: func(x, y int) color.RGBA {
+				if image.Pt(x, y).In(box) {
+					v := uint8(x&^1*32 + 16)
+					return color.RGBA{R: v, G: v, B: v, A: 255}
+				}
+				return gradient(x, y)
+			},
+		},
+		{
+			name:     "blur box",
+			mode:     "blur",
+			area:     "box",
+			strength: 1,
+			regions:  []region{{bounds: image.Rect(4, 4, 8, 8)}},
+			want: func(x, y int) color.RGBA {
+				// blurring a linear gradient keeps it, except near the clamped image edge which is not checked
+				if x >= 6 && y >= 4 {
+					return color.RGBA{}
+				}
+				return gradient(x, y)
+			},
+		},
+		{
+			name: "no regions",
+			mode: "pixelate",
+			area: "box",
+			want: gradient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					img.SetRGBA(x, y, gradient(x, y))
+				}
+			}
+
+			censor := &Censor{config: *defaultConfig()}
+			censor.config.Mode, censor.config.Area, censor.config.rgba = tt.mode, tt.area, red
+			if tt.strength > 0 {
+				censor.config.Strength = tt.strength
+			}
+
+			got := censor.censor(img, detection{nude: true, regions: tt.regions})
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					// zero colors are not checked
+					want := tt.want(x, y)
+					if want == (color.RGBA{}) {
+						continue
+					}
+					if c := color.RGBAModel.Convert(got.At(x, y)); c != want {
+						t.Errorf("censor() at (%d, %d) = %v, want %v", x, y, c, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestInside(t *testing.T) {
+	square := []image.Point{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+
+	tests := []struct {
+		name    string
+		polygon []image.Point
+		point   image.Point
+		want    bool
+	}{
+		{name: "inside", polygon: square, point: image.Pt(2, 2), want: true},
+		{name: "edge", polygon: square, point: image.Pt(4, 2), want: true},
+		{name: "corner", polygon: square, point: image.Pt(0, 0), want: true},
+		{name: "outside", polygon: square, point: image.Pt(5, 2)},
+		{name: "reversed", polygon: []image.Point{{0, 4}, {4, 4}, {4, 0}, {0, 0}}, point: image.Pt(1, 3), want: true},
+		{name: "outside triangle", polygon: []image.Point{{0, 0}, {4, 4}, {0, 4}}, point: image.Pt(3, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inside(tt.polygon, tt.point); got != tt.want {
+				t.Errorf("inside(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCensorDetection(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	gray := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	box := image.Rect(2, 2, 6, 6)
+
+	tests := []struct {
+		name     string
+		size     image.Point
+		drop     bool
+		censored bool
+		nude     bool
+		code     string
+	}{
+		{name: "reused", size: image.Pt(8, 8), censored: true, nude: true},
+		{name: "resized after detection", size: image.Pt(16, 16)},
+		{name: "dropped", size: image.Pt(8, 8), drop: true, code: CodeNudityDetected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					img.SetRGBA(x, y, gray)
+				}
+			}
+
+			// an upstream detection, the gray image itself has no skin
+			data := payload.Data{}
+			detection{nude: true, confidence: 0.9, size: tt.size, regions: []region{{bounds: box}}}.set(data)
+
+			censor := &Censor{config: *defaultConfig()}
+			censor.config.Area, censor.config.rgba, censor.config.Drop = "box", red, tt.drop
+
+			got, res := censor.Process(img, data)
+			if tt.code != "" {
+				reason, ok := res.Reason()
+				if res.Ack || !ok || reason.Code != tt.code {
+					t.Fatalf("Process() = %+v, want a rejection with code %s", res, tt.code)
+				}
+				return
+			}
+			if !res.Ack {
+				t.Fatalf("Process() = %+v, want ack", res)
+			}
+
+			if data[DetectionField] != tt.nude {
+				t.Errorf("Process() data[%s] = %v, want %v", DetectionField, data[DetectionField], tt.nude)
+			}
+
+			want := gray
+			if tt.censored {
+				want = red
+			}
+			if c := color.RGBAModel.Convert(got.(image.Image).At(3, 3)); c != want {
+				t.Errorf("Process() at (3, 3) = %v, want %v", c, want)
+			}
+		})
+	}
+}
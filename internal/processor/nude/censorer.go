@@ -2,35 +2,23 @@ package nude
 
 import (
 	"bytes"
-	"fmt"
 	"image"
 	"image/color"
-	// register GIF to decode function
-	_ "image/gif"
 	"image/jpeg"
-	// register JPEG to decode function
-	_ "image/jpeg"
 	"image/png"
-	// register PNG to decode function
-	_ "image/png"
-	"io"
 
-	"github.com/sherifabdlnaby/prism/internal/processor/nude/gonude"
 	"github.com/sherifabdlnaby/prism/pkg/component"
-
-	// register webp to decode function
-	_ "golang.org/x/image/webp"
-
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
-
+	"github.com/sherifabdlnaby/prism/pkg/decode"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
 	"go.uber.org/zap"
 )
 
 // Censor plugin will read an image and comes-up with the likely hood that this image contains nudity,
-// and will censor the image with configured color pixels. It can be configured to send a NoAck when it detects nudity and
-// will a boolean flag "nude" to payload.Data
+// and will censor skin, or boxes or hulls of skin regions by filling, blurring or pixelating them. Detection results of
+// an upstream nude_detector are reused when censoring boxes or hulls of an image of the same size. It can be
+// configured to send a NoAck when it detects nudity and will a boolean flag "nude" to payload.Data
 type Censor struct {
 	logger zap.SugaredLogger
 	config config
@@ -79,14 +67,7 @@ func (d *Censor) Decode(in payload.Bytes, data payload.Data) (payload.DecodedIma
 
 // DecodeStream return a decoded header(config) and image format from input stream
 func (d *Censor) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
-	reader := in.(io.Reader)
-
-	image, _, err := image.Decode(reader)
-	if err != nil {
-		return nil, response.Error(fmt.Errorf("unsupported format"))
-	}
-
-	return image, response.Ack()
+	return decode.Image(in)
 }
 
 // process process will process the image and calculate skin regions and the likelihood it's a nude image, according to
@@ -95,22 +76,26 @@ func (d *Censor) DecodeStream(in payload.Stream, data payload.Data) (payload.Dec
 func (d *Censor) Process(in payload.DecodedImage, data payload.Data) (payload.DecodedImage, response.Response) {
 	image := in.(image.Image)
 
-	decoder := gonude.NewDetector(image)
-
-	isNude, err := decoder.Parse()
-	if err != nil {
-		return nil, response.Error(err)
+	// reuse detection of an upstream nude_detector, skin pixels are only available when detecting here. images resized
+	// or cropped after detection are detected again as their regions no longer match.
+	result, ok := fromData(data)
+	if !ok || d.config.Area == "skin" || result.size != image.Bounds().Size() {
+		var err error
+		result, err = detect(image, d.config)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		// add to data
+		result.set(data)
 	}
 
-	// add to data
-	data["nude_detection"] = isNude
-
-	if d.config.Drop && isNude {
-		return nil, response.NoAck(fmt.Errorf("image may contain nudity"))
+	if d.config.Drop && result.nude {
+		return nil, result.reject(d.config.Threshold)
 	}
 
-	if isNude {
-		image = gonude.CensorSkinPixels(image, decoder.SkinRegions, d.config.rgba)
+	if result.nude {
+		image = d.censor(image, result)
 	}
 
 	return image, response.Ack()
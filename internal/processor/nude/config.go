@@ -1,12 +1,29 @@
 package nude
 
-import "image/color"
+import (
+	"image/color"
+
+	"github.com/sherifabdlnaby/prism/internal/processor/nude/gonude"
+)
 
 type config struct {
 	rgba   color.RGBA
 	Drop   bool
 	Export export
 	RGBA   rgba
+
+	// Threshold is the confidence (0 to 1) at which an image is considered nude, 0 uses the decision of the detector.
+	Threshold float64 `validate:"min=0,max=1"`
+	// Detection thresholds of skin regions.
+	Detection gonude.Thresholds
+	// Regions is the maximum number of skin regions bounding boxes added to payload.Data, largest first.
+	Regions int `validate:"min=0"`
+
+	// Mode of censoring, fill paints with RGBA, blur and pixelate use Strength as blur sigma and block size.
+	Mode     string  `validate:"oneof=fill blur pixelate"`
+	Strength float64 `validate:"min=1"`
+	// Area censored, either skin pixels, or bounding boxes or convex hulls of the largest skin regions.
+	Area string `validate:"oneof=skin box hull"`
 }
 
 type export struct {
@@ -33,5 +50,11 @@ func defaultConfig() *config {
 			Format:  "jpeg",
 			Quality: 85,
 		},
+		Threshold: 0,
+		Detection: gonude.DefaultThresholds(),
+		Regions:   10,
+		Mode:      "fill",
+		Strength:  16,
+		Area:      "skin",
 	}
 }
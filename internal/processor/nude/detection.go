@@ -0,0 +1,210 @@
+package nude
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/sherifabdlnaby/prism/internal/processor/nude/gonude"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+)
+
+// Fields added to payload.Data by detection, regions are the bounding boxes {x, y, width, height} and convex hulls
+// (list of [x, y] points) of skin regions, in pixels of the image detected which is SizeField {width, height}.
+const (
+	DetectionField  = "nude_detection"
+	ConfidenceField = "nude_confidence"
+	RegionsField    = "nude_regions"
+	SizeField       = "nude_size"
+)
+
+type detection struct {
+	nude       bool
+	confidence float64
+
+	// size of the image detected, regions are in its pixels
+	size image.Point
+
+	// skin is nil if detection is read from payload.Data
+	skin    gonude.Regions
+	regions []region
+}
+
+// region is the bounding box and convex hull of a skin region
+type region struct {
+	bounds image.Rectangle
+	hull   []image.Point
+}
+
+// detect parses the image with configured thresholds, threshold overrides the detector decision if set.
+func detect(img image.Image, config config) (detection, error) {
+	detector := gonude.NewDetector(img)
+	detector.Thresholds = config.Detection
+
+	isNude, err := detector.Parse()
+	if err != nil {
+		return detection{}, err
+	}
+
+	result := detection{
+		nude:       isNude,
+		confidence: detector.Confidence(),
+		size:       img.Bounds().Size(),
+		skin:       detector.SkinRegions,
+	}
+
+	if config.Threshold > 0 {
+		result.nude = result.confidence >= config.Threshold
+	}
+
+	// skin regions are sorted by size
+	count := config.Regions
+	if count > len(detector.SkinRegions) {
+		count = len(detector.SkinRegions)
+	}
+
+	result.regions = make([]region, count)
+	for i := range result.regions {
+		result.regions[i] = region{
+			bounds: detector.SkinRegions[i].Bounds(),
+			hull:   detector.SkinRegions[i].Hull(),
+		}
+	}
+
+	return result, nil
+}
+
+// reject return the response dropping a nude image, threshold is 0 if the detector decided.
+func (d detection) reject(threshold float64) response.Response {
+	message := fmt.Sprintf("image may contain nudity, confidence is %.2f", d.confidence)
+	if threshold > 0 {
+		message = fmt.Sprintf("image may contain nudity, confidence %.2f is at least %.2f", d.confidence, threshold)
+	}
+
+	return response.Reject(CodeNudityDetected, message,
+		map[string]interface{}{"confidence": d.confidence, "threshold": threshold})
+}
+
+// set adds the detection result to payload.Data
+func (d detection) set(data payload.Data) {
+	data[DetectionField] = d.nude
+	data[ConfidenceField] = d.confidence
+	data[SizeField] = map[string]interface{}{"width": d.size.X, "height": d.size.Y}
+
+	regions := make([]map[string]interface{}, len(d.regions))
+	for i, r := range d.regions {
+		hull := make([][]int, len(r.hull))
+		for j, point := range r.hull {
+			hull[j] = []int{point.X, point.Y}
+		}
+
+		regions[i] = map[string]interface{}{
+			"x":      r.bounds.Min.X,
+			"y":      r.bounds.Min.Y,
+			"width":  r.bounds.Dx(),
+			"height": r.bounds.Dy(),
+			"hull":   hull,
+		}
+	}
+	data[RegionsField] = regions
+}
+
+// fromData return the detection result added to payload.Data by an upstream node, ok is false if it's missing.
+func fromData(data payload.Data) (detection, bool) {
+	nude, ok := data[DetectionField].(bool)
+	if !ok {
+		return detection{}, false
+	}
+
+	result := detection{nude: nude}
+	result.confidence, _ = number(data[ConfidenceField])
+
+	size, ok := data[SizeField].(map[string]interface{})
+	if !ok {
+		return detection{}, false
+	}
+	width, okW := number(size["width"])
+	height, okH := number(size["height"])
+	if !okW || !okH {
+		return detection{}, false
+	}
+	result.size = image.Pt(int(width), int(height))
+
+	// regions are a list of maps, or a list of interfaces if data was decoded from JSON
+	var items []map[string]interface{}
+	switch regions := data[RegionsField].(type) {
+	case []map[string]interface{}:
+		items = regions
+	case []interface{}:
+		for _, item := range regions {
+			if m, ok := item.(map[string]interface{}); ok {
+				items = append(items, m)
+			}
+		}
+	default:
+		return detection{}, false
+	}
+
+	for _, item := range items {
+		x, okX := number(item["x"])
+		y, okY := number(item["y"])
+		width, okW := number(item["width"])
+		height, okH := number(item["height"])
+		if !okX || !okY || !okW || !okH {
+			return detection{}, false
+		}
+
+		r := region{bounds: image.Rect(int(x), int(y), int(x+width), int(y+height))}
+		r.hull = points(item["hull"])
+		result.regions = append(result.regions, r)
+	}
+
+	return result, true
+}
+
+// points decodes a list of [x, y] points
+func points(value interface{}) []image.Point {
+	var pairs []interface{}
+	switch value := value.(type) {
+	case [][]int:
+		hull := make([]image.Point, 0, len(value))
+		for _, pair := range value {
+			if len(pair) == 2 {
+				hull = append(hull, image.Pt(pair[0], pair[1]))
+			}
+		}
+		return hull
+	case []interface{}:
+		pairs = value
+	default:
+		return nil
+	}
+
+	hull := make([]image.Point, 0, len(pairs))
+	for _, pair := range pairs {
+		xy, ok := pair.([]interface{})
+		if !ok || len(xy) != 2 {
+			return nil
+		}
+		x, okX := number(xy[0])
+		y, okY := number(xy[1])
+		if !okX || !okY {
+			return nil
+		}
+		hull = append(hull, image.Pt(int(x), int(y)))
+	}
+	return hull
+}
+
+func number(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,138 @@
+package nude
+
+import (
+	"encoding/json"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+func TestDetectionData(t *testing.T) {
+	result := detection{
+		nude:       true,
+		confidence: 0.75,
+		size:       image.Pt(100, 60),
+		regions: []region{
+			{bounds: image.Rect(5, 5, 55, 55), hull: []image.Point{{5, 5}, {54, 5}, {54, 54}, {5, 54}}},
+			{bounds: image.Rect(70, 5, 90, 25), hull: []image.Point{{70, 5}, {89, 5}, {89, 24}, {70, 24}}},
+		},
+	}
+
+	// data decoded from JSON has float64 numbers and lists of interfaces
+	jsonData := func(data payload.Data) payload.Data {
+		buf, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		decoded := payload.Data{}
+		if err := json.Unmarshal(buf, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		return decoded
+	}
+
+	tests := []struct {
+		name string
+		data func() payload.Data
+		want detection
+		ok   bool
+	}{
+		{
+			name: "set",
+			data: func() payload.Data {
+				data := payload.Data{}
+				result.set(data)
+				return data
+			},
+			want: result,
+			ok:   true,
+		},
+		{
+			name: "json",
+			data: func() payload.Data {
+				data := payload.Data{}
+				result.set(data)
+				return jsonData(data)
+			},
+			want: result,
+			ok:   true,
+		},
+		{
+			name: "no regions",
+			data: func() payload.Data {
+				data := payload.Data{}
+				detection{confidence: 0.1}.set(data)
+				return jsonData(data)
+			},
+			want: detection{confidence: 0.1},
+			ok:   true,
+		},
+		{
+			name: "missing",
+			data: func() payload.Data { return payload.Data{} },
+		},
+		{
+			name: "missing size",
+			data: func() payload.Data {
+				data := payload.Data{}
+				result.set(data)
+				delete(data, SizeField)
+				return data
+			},
+		},
+		{
+			name: "invalid region",
+			data: func() payload.Data {
+				return payload.Data{
+					DetectionField: true,
+					SizeField:      map[string]interface{}{"width": 100, "height": 60},
+					RegionsField:   []interface{}{map[string]interface{}{"x": "1"}},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fromData(tt.data())
+			if ok != tt.ok {
+				t.Fatalf("fromData() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fromData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReject(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		message   string
+	}{
+		{name: "detector decision", message: "image may contain nudity, confidence is 0.75"},
+		{name: "threshold", threshold: 0.5, message: "image may contain nudity, confidence 0.75 is at least 0.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := detection{nude: true, confidence: 0.75}.reject(tt.threshold)
+			if res.Ack {
+				t.Fatal("reject() is acknowledged")
+			}
+
+			reason, ok := res.Reason()
+			if !ok {
+				t.Fatalf("reject() has no reason, error = %v", res.AckErr)
+			}
+
+			want := map[string]interface{}{"confidence": 0.75, "threshold": tt.threshold}
+			if reason.Code != CodeNudityDetected || reason.Message != tt.message || !reflect.DeepEqual(reason.Values, want) {
+				t.Errorf("reject() = %+v, want code %s, message %q and values %v", reason, CodeNudityDetected, tt.message, want)
+			}
+		})
+	}
+}
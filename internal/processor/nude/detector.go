@@ -2,24 +2,11 @@ package nude
 
 import (
 	"bytes"
-	"fmt"
 	"image"
-	// register GIF to decode function
-	_ "image/gif"
-	// register JPEG to decode function
-	_ "image/jpeg"
-	// register PNG to decode function
-	_ "image/png"
-	"io"
-
-	"github.com/sherifabdlnaby/prism/internal/processor/nude/gonude"
-	"github.com/sherifabdlnaby/prism/pkg/component"
-
-	// register webp to decode function
-	_ "golang.org/x/image/webp"
 
+	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
-
+	"github.com/sherifabdlnaby/prism/pkg/decode"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
 	"go.uber.org/zap"
@@ -69,34 +56,26 @@ func (d *Detector) Decode(in payload.Bytes, data payload.Data) (payload.DecodedI
 
 // DecodeStream return a decoded header(config) and image format from input stream
 func (d *Detector) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
-	reader := in.(io.Reader)
-
-	image, _, err := image.Decode(reader)
-	if err != nil {
-		return nil, response.Error(fmt.Errorf("unsupported format"))
-	}
-
-	return image, response.Ack()
+	return decode.Image(in)
 }
 
 // process process will process the image and calculate skin regions and the likelihood it's a nude image, according to
 // configuration, process will either send a NoAck to Drop the image according to configuration,
-// otherwise will add "nude" boolean to payload.Data
+// otherwise will add "nude" boolean, confidence and skin regions to payload.Data
 func (d *Detector) Process(in payload.DecodedImage, data payload.Data) response.Response {
 
 	image := in.(image.Image)
-	decoder := gonude.NewDetector(image)
 
-	isNude, err := decoder.Parse()
+	result, err := detect(image, d.config)
 	if err != nil {
 		return response.Error(err)
 	}
 
 	// add to data
-	data["nude_detection"] = isNude
+	result.set(data)
 
-	if d.config.Drop && isNude {
-		return response.NoAck(fmt.Errorf("image may contain nudity"))
+	if d.config.Drop && result.nude {
+		return result.reject(d.config.Threshold)
 	}
 
 	return response.Ack()
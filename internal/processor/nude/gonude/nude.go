@@ -11,8 +11,44 @@ import (
 	"sort"
 )
 
+//Thresholds used to decide if skin regions are nude, percentages are from 0 to 100.
+type Thresholds struct {
+	// MinRegionSize is the minimum pixels of a skin region, smaller regions are ignored.
+	MinRegionSize int `mapstructure:"min_region_size" validate:"min=1"`
+	// MinRegions is the minimum number of skin regions.
+	MinRegions int `mapstructure:"min_regions" validate:"min=1"`
+	// MinSkin is the minimum percentage of skin pixels in the image.
+	MinSkin float64 `mapstructure:"min_skin" validate:"min=0,max=100"`
+	// LargestRegions are percentages of skin the three largest regions must have at least one of.
+	LargestRegions []float64 `mapstructure:"largest_regions" validate:"len=3,dive,min=0,max=100"`
+	// MinBiggestRegion is the minimum percentage of skin in the largest region.
+	MinBiggestRegion float64 `mapstructure:"min_biggest_region" validate:"min=0,max=100"`
+	// below PolygonSkin percentage of skin, every region must have MinPolygonRate of skin in its bounding polygon.
+	PolygonSkin    float64 `mapstructure:"polygon_skin" validate:"min=0,max=100"`
+	MinPolygonRate float64 `mapstructure:"min_polygon_rate" validate:"min=0,max=1"`
+	// images with more than MaxRegions regions must have at least MinIntensity average intensity.
+	MaxRegions   int     `mapstructure:"max_regions" validate:"min=0"`
+	MinIntensity float64 `mapstructure:"min_intensity" validate:"min=0,max=1"`
+}
+
+//DefaultThresholds return thresholds of the original algorithm
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MinRegionSize:    30,
+		MinRegions:       3,
+		MinSkin:          15,
+		LargestRegions:   []float64{35, 30, 30},
+		MinBiggestRegion: 45,
+		PolygonSkin:      30,
+		MinPolygonRate:   0.55,
+		MaxRegions:       60,
+		MinIntensity:     0.25,
+	}
+}
+
 //Detector Detect Image Nudity
 type Detector struct {
+	Thresholds      Thresholds
 	image           image.Image
 	width           int
 	height          int
@@ -29,7 +65,7 @@ type Detector struct {
 
 //NewDetector Returns new Detector
 func NewDetector(img image.Image) *Detector {
-	d := &Detector{image: img}
+	d := &Detector{image: img, Thresholds: DefaultThresholds()}
 	return d
 }
 
@@ -184,18 +220,19 @@ func (d *Detector) merge(detectedRegions Regions, mergeRegions [][]int) {
 // only push regions which are bigger than a specific amount to the final resul
 func (d *Detector) clearRegions(detectedRegions Regions) {
 	for _, region := range detectedRegions {
-		if len(region) > 30 {
+		if len(region) > d.Thresholds.MinRegionSize {
 			d.SkinRegions = append(d.SkinRegions, region)
 		}
 	}
 }
 
 func (d *Detector) analyzeRegions() bool {
+	t := d.Thresholds
 	skinRegionLength := len(d.SkinRegions)
 
 	// if there are less than 3 regions
-	if skinRegionLength < 3 {
-		d.message = fmt.Sprintf("Less than 3 skin regions (%v)", skinRegionLength)
+	if skinRegionLength < t.MinRegions {
+		d.message = fmt.Sprintf("Less than %d skin regions (%v)", t.MinRegions, skinRegionLength)
 		d.result = false
 		return d.result
 	}
@@ -208,9 +245,9 @@ func (d *Detector) analyzeRegions() bool {
 
 	// check if there are more than 15% skin pixel in the image
 	totalSkinParcentage := totalSkinPixels / float64(d.totalPixels) * 100
-	if totalSkinParcentage < 15 {
+	if totalSkinParcentage < t.MinSkin {
 		// if the parcentage lower than 15, it's not nude!
-		d.message = fmt.Sprintf("Total skin parcentage lower than 15 (%v%%)", totalSkinParcentage)
+		d.message = fmt.Sprintf("Total skin parcentage lower than %v (%v%%)", t.MinSkin, totalSkinParcentage)
 		d.result = false
 		return d.result
 	}
@@ -218,20 +255,21 @@ func (d *Detector) analyzeRegions() bool {
 	// check if the largest skin region is less than 35% of the total skin count
 	// AND if the second largest region is less than 30% of the total skin count
 	// AND if the third largest region is less than 30% of the total skin count
-	biggestRegionParcentage := float64(len(d.SkinRegions[0])) / totalSkinPixels * 100
-	secondLargeRegionParcentage := float64(len(d.SkinRegions[1])) / totalSkinPixels * 100
-	thirdLargesRegionParcentage := float64(len(d.SkinRegions[2])) / totalSkinPixels * 100
-	if biggestRegionParcentage < 35 &&
-		secondLargeRegionParcentage < 30 &&
-		thirdLargesRegionParcentage < 30 {
-		d.message = "Less than 35%, 30%, 30% skin in the biggest regions"
+	biggestRegionParcentage := d.SkinRegions.percentage(0, totalSkinPixels)
+	secondLargeRegionParcentage := d.SkinRegions.percentage(1, totalSkinPixels)
+	thirdLargesRegionParcentage := d.SkinRegions.percentage(2, totalSkinPixels)
+	if biggestRegionParcentage < t.LargestRegions[0] &&
+		secondLargeRegionParcentage < t.LargestRegions[1] &&
+		thirdLargesRegionParcentage < t.LargestRegions[2] {
+		d.message = fmt.Sprintf("Less than %v%%, %v%%, %v%% skin in the biggest regions",
+			t.LargestRegions[0], t.LargestRegions[1], t.LargestRegions[2])
 		d.result = false
 		return d.result
 	}
 
 	// check if the number of skin pixels in the largest region is less than 45% of the total skin count
-	if biggestRegionParcentage < 45 {
-		d.message = fmt.Sprintf("The biggest region contains less than 45%% (%v)", biggestRegionParcentage)
+	if biggestRegionParcentage < t.MinBiggestRegion {
+		d.message = fmt.Sprintf("The biggest region contains less than %v%% (%v)", t.MinBiggestRegion, biggestRegionParcentage)
 		d.result = false
 		return d.result
 	}
@@ -239,12 +277,12 @@ func (d *Detector) analyzeRegions() bool {
 	// check if the total skin count is less than 30% of the total number of pixels
 	// AND the number of skin pixels within the bounding polygon is less than 55% of the size of the polygon
 	// if this condition is true, it's not nude.
-	if totalSkinParcentage < 30 {
+	if totalSkinParcentage < t.PolygonSkin {
 		for i, region := range d.SkinRegions {
 			skinRate := region.skinRateInBoundingPolygon()
 			//fmt.Printf("skinRate[%v] = %v\n", i, skinRate)
-			if skinRate < 0.55 {
-				d.message = fmt.Sprintf("region[%d].skinRate(%v) < 0.55", i, skinRate)
+			if skinRate < t.MinPolygonRate {
+				d.message = fmt.Sprintf("region[%d].skinRate(%v) < %v", i, skinRate, t.MinPolygonRate)
 				d.result = false
 				return d.result
 			}
@@ -254,8 +292,9 @@ func (d *Detector) analyzeRegions() bool {
 	// if there are more than 60 skin regions and the average intensity within the polygon is less than 0.25
 	// the image is not nude
 	averageIntensity := d.SkinRegions.averageIntensity()
-	if skinRegionLength > 60 && averageIntensity < 0.25 {
-		d.message = fmt.Sprintf("More than 60 skin regions(%v) and averageIntensity(%v) < 0.25", skinRegionLength, averageIntensity)
+	if skinRegionLength > t.MaxRegions && averageIntensity < t.MinIntensity {
+		d.message = fmt.Sprintf("More than %d skin regions(%v) and averageIntensity(%v) < %v",
+			t.MaxRegions, skinRegionLength, averageIntensity, t.MinIntensity)
 		d.result = false
 		return d.result
 	}
@@ -265,6 +304,33 @@ func (d *Detector) analyzeRegions() bool {
 	return d.result
 }
 
+//Confidence return a continuous score from 0 to 1 of how likely the parsed image is nude, it's the geometric mean of
+//how close the skin percentage, regions count, largest region and its bounding polygon are to their thresholds.
+func (d *Detector) Confidence() float64 {
+	t := d.Thresholds
+	if len(d.SkinRegions) == 0 || d.totalPixels == 0 {
+		return 0
+	}
+
+	sort.Sort(sort.Reverse(d.SkinRegions))
+
+	totalSkinPixels := float64(d.SkinRegions.totalPixels())
+	skin := ratio(totalSkinPixels/float64(d.totalPixels)*100, 2*t.MinSkin)
+	regions := ratio(float64(len(d.SkinRegions)), float64(t.MinRegions))
+	biggest := ratio(float64(len(d.SkinRegions[0]))/totalSkinPixels*100, t.MinBiggestRegion)
+	polygon := ratio(d.SkinRegions[0].skinRateInBoundingPolygon(), t.MinPolygonRate)
+
+	return math.Pow(skin*regions*biggest*polygon, 0.25)
+}
+
+// ratio of value to threshold capped to 1
+func ratio(value, threshold float64) float64 {
+	if threshold <= 0 {
+		return 1
+	}
+	return math.Min(1, value/threshold)
+}
+
 func (d *Detector) String() string {
 	return fmt.Sprintf("#<nude.Detector result=%t, message=%s>", d.result, d.message)
 }
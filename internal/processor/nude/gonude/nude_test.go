@@ -0,0 +1,143 @@
+package gonude
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var (
+	skin       = color.RGBA{R: 224, G: 172, B: 140, A: 255}
+	background = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// canvas returns a 100x100 white image with skin patches
+func canvas(patches ...image.Rectangle) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for _, patch := range patches {
+		draw.Draw(img, patch, image.NewUniform(skin), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func TestClassifySkin(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, g, b uint32
+		want    bool
+	}{
+		{name: "light skin", r: 224, g: 172, b: 140, want: true},
+		{name: "dark skin", r: 141, g: 85, b: 36, want: true},
+		{name: "white", r: 255, g: 255, b: 255},
+		{name: "green", r: 40, g: 200, b: 60},
+		{name: "blue", r: 80, g: 140, b: 220},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := classifySkin(tt.r, tt.g, tt.b); got != tt.want {
+				t.Errorf("classifySkin(%d, %d, %d) = %v, want %v", tt.r, tt.g, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	// the bounding polygon of a square joins its first found leftmost, uppermost, rightmost and lowermost pixels, so
+	// only about half of its pixels are in it.
+	tests := []struct {
+		name       string
+		img        image.Image
+		want       bool
+		regions    int
+		confidence float64
+	}{
+		{
+			name:       "three regions",
+			img:        canvas(image.Rect(5, 5, 55, 55), image.Rect(70, 5, 90, 25), image.Rect(70, 70, 90, 90)),
+			want:       true,
+			regions:    3,
+			confidence: math.Pow(1275.0/2500/0.55, 0.25),
+		},
+		{
+			name:       "single region",
+			img:        canvas(image.Rect(5, 5, 45, 45)),
+			regions:    1,
+			confidence: math.Pow(16.0/30*1/3*820/1600/0.55, 0.25),
+		},
+		{
+			name:    "small regions are ignored",
+			img:     canvas(image.Rect(5, 5, 10, 10), image.Rect(60, 5, 65, 10), image.Rect(60, 60, 65, 65)),
+			regions: 0,
+		},
+		{name: "no skin", img: canvas()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetector(tt.img)
+			got, err := detector.Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v (%s)", got, tt.want, detector)
+			}
+			if len(detector.SkinRegions) != tt.regions {
+				t.Errorf("SkinRegions = %d, want %d", len(detector.SkinRegions), tt.regions)
+			}
+			if confidence := detector.Confidence(); math.Abs(confidence-tt.confidence) > 1e-9 {
+				t.Errorf("Confidence() = %v, want %v", confidence, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		name   string
+		pixels [][2]int
+		bounds image.Rectangle
+		hull   []image.Point
+	}{
+		{name: "empty"},
+		{
+			name:   "single pixel",
+			pixels: [][2]int{{3, 4}},
+			bounds: image.Rect(3, 4, 4, 5),
+			hull:   []image.Point{{3, 4}},
+		},
+		{
+			name:   "square",
+			pixels: [][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {2, 1}, {0, 2}, {1, 2}, {2, 2}},
+			bounds: image.Rect(0, 0, 3, 3),
+			hull:   []image.Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}},
+		},
+		{
+			name:   "triangle",
+			pixels: [][2]int{{2, 0}, {1, 1}, {2, 1}, {3, 1}, {0, 2}, {1, 2}, {2, 2}, {3, 2}, {4, 2}},
+			bounds: image.Rect(0, 0, 5, 3),
+			hull:   []image.Point{{0, 2}, {2, 0}, {4, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var region Region
+			for _, p := range tt.pixels {
+				region = append(region, &Pixel{X: p[0], Y: p[1], isSkin: true})
+			}
+
+			if got := region.Bounds(); got != tt.bounds {
+				t.Errorf("Bounds() = %v, want %v", got, tt.bounds)
+			}
+			if got := region.Hull(); !reflect.DeepEqual(got, tt.hull) {
+				t.Errorf("Hull() = %v, want %v", got, tt.hull)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package gonude
 
 import (
+	"image"
 	"math"
+	"sort"
 )
 
 // THIS FILE IS A MODIFIED FILE FROM github.com/koyachi/go-nude
@@ -79,6 +81,78 @@ func (r Region) lowerMost() *Pixel {
 	return r[index]
 }
 
+//Bounds return the bounding box of the region
+func (r Region) Bounds() image.Rectangle {
+	if len(r) == 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(r.leftMost().X, r.upperMost().Y, r.rightMost().X+1, r.lowerMost().Y+1)
+}
+
+//Hull return the convex hull of the region, in counter-clockwise order of the image coordinates.
+func (r Region) Hull() []image.Point {
+	if len(r) == 0 {
+		return nil
+	}
+
+	// only the leftmost and rightmost pixels of every row can be on the hull
+	bounds := r.Bounds()
+	left := make([]int, bounds.Dy())
+	right := make([]int, bounds.Dy())
+	for i := range left {
+		left[i], right[i] = math.MaxInt32, math.MinInt32
+	}
+	for _, pixel := range r {
+		row := pixel.Y - bounds.Min.Y
+		if pixel.X < left[row] {
+			left[row] = pixel.X
+		}
+		if pixel.X > right[row] {
+			right[row] = pixel.X
+		}
+	}
+
+	points := make([]image.Point, 0, 2*len(left))
+	for row := range left {
+		if left[row] < right[row] {
+			points = append(points, image.Pt(left[row], bounds.Min.Y+row), image.Pt(right[row], bounds.Min.Y+row))
+		} else if left[row] == right[row] {
+			points = append(points, image.Pt(left[row], bounds.Min.Y+row))
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].X < points[j].X || (points[i].X == points[j].X && points[i].Y < points[j].Y)
+	})
+
+	// monotone chain
+	hull := make([]image.Point, 0, len(points))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, p := range points {
+			for len(hull) >= start+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		// last point is the first of the next chain
+		hull = hull[:len(hull)-1]
+
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+
+	if len(hull) == 0 {
+		return []image.Point{points[0]}
+	}
+	return hull
+}
+
+func cross(o, a, b image.Point) int {
+	return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+}
+
 func (r Region) skinRateInBoundingPolygon() float64 {
 	// build the bounding polygon by the regions edge values:
 	// Identify the leftmost, the uppermost, the rightmost, and the lowermost skin pixels of the three largest skin regions.
@@ -130,6 +204,14 @@ func (r Regions) totalPixels() int {
 	return totalSkin
 }
 
+// percentage of skin pixels in the i-th region, 0 if there are fewer regions
+func (r Regions) percentage(i int, totalSkin float64) float64 {
+	if i >= len(r) {
+		return 0
+	}
+	return float64(len(r[i])) / totalSkin * 100
+}
+
 func (r Regions) averageIntensity() float64 {
 	var totalIntensity float64
 	for _, region := range r {
@@ -0,0 +1,6 @@
+package nude
+
+// Codes of rejection reasons, nude images are reported with their confidence and the configured threshold.
+const (
+	CodeNudityDetected = "NUDITY_DETECTED"
+)
@@ -0,0 +1,29 @@
+package phash
+
+type config struct {
+	Hashes []string `validate:"min=1,dive,oneof=ahash dhash phash"`
+
+	// Dedupe looks up near-duplicates of images seen in the last MaxAge seconds (0 keeps them forever) using Hash.
+	Dedupe   bool
+	Drop     bool
+	Hash     string `validate:"oneof=ahash dhash phash"`
+	Distance int    `validate:"min=0,max=64"`
+	MaxAge   int    `mapstructure:"max_age" validate:"min=0"`
+
+	// Index is the name of the bolt db file and bucket in Directory (default is persistence data directory).
+	Index     string `validate:"required"`
+	Directory string
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Hashes:   []string{"ahash", "dhash", "phash"},
+		Dedupe:   false,
+		Drop:     true,
+		Hash:     "phash",
+		Distance: 5,
+		MaxAge:   86400,
+		Index:    "phash",
+	}
+}
@@ -0,0 +1,204 @@
+package phash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/sherifabdlnaby/prism/pkg/imagehash"
+)
+
+// entry is a seen image hash, key is its hash and value is when it was seen in unix nanoseconds.
+type entry struct {
+	hash imagehash.Hash
+	seen time.Time
+}
+
+// index of recently seen hashes, kept in memory ordered by time and persisted to a bolt bucket. hashes of jobs in flight
+// are pending until they're committed once the job succeeds.
+type index struct {
+	db      *bolt.DB
+	bucket  []byte
+	maxAge  time.Duration
+	entries []entry
+	pending []imagehash.Hash
+	mu      sync.Mutex
+}
+
+func openIndex(directory, name string, maxAge time.Duration) (*index, error) {
+	err := os.MkdirAll(directory, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(directory, name+".db"), 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error while opening hash index file %s", err.Error())
+	}
+
+	i := &index{
+		db:      db,
+		bucket:  []byte(name),
+		maxAge:  maxAge,
+		entries: make([]entry, 0),
+	}
+
+	err = i.load()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// load reads persisted hashes, dropping expired ones
+func (i *index) load() error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(i.bucket)
+		if err != nil {
+			return err
+		}
+
+		expired := make([][]byte, 0)
+		err = bucket.ForEach(func(k, v []byte) error {
+			if len(k) != 8 || len(v) != 8 {
+				expired = append(expired, k)
+				return nil
+			}
+
+			e := entry{
+				hash: imagehash.Hash(binary.BigEndian.Uint64(k)),
+				seen: time.Unix(0, int64(binary.BigEndian.Uint64(v))),
+			}
+
+			if i.expired(e, time.Now()) {
+				expired = append(expired, k)
+				return nil
+			}
+
+			i.entries = append(i.entries, e)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			err = bucket.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+
+		sort.Slice(i.entries, func(a, b int) bool {
+			return i.entries[a].seen.Before(i.entries[b].seen)
+		})
+
+		return nil
+	})
+}
+
+// Reserve return the closest hash within distance seen before or reserved by a job in flight, otherwise it reserves
+// hash until it's committed or released.
+func (i *index) Reserve(hash imagehash.Hash, distance int) (imagehash.Hash, int, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	match, closest := imagehash.Hash(0), distance+1
+
+	for _, e := range i.entries {
+		if d := imagehash.Distance(e.hash, hash); d < closest && !i.expired(e, now) {
+			match, closest = e.hash, d
+		}
+	}
+
+	for _, pending := range i.pending {
+		if d := imagehash.Distance(pending, hash); d < closest {
+			match, closest = pending, d
+		}
+	}
+
+	if closest <= distance {
+		return match, closest, true
+	}
+
+	i.pending = append(i.pending, hash)
+
+	return 0, 0, false
+}
+
+// Commit adds a reserved hash to the index, expired hashes are removed.
+func (i *index) Commit(hash imagehash.Hash) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.release(hash)
+
+	now := time.Now()
+
+	// entries are ordered by time, expired ones are at the start
+	expired := 0
+	for expired < len(i.entries) && i.expired(i.entries[expired], now) {
+		expired++
+	}
+
+	err := i.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(i.bucket)
+
+		for _, e := range i.entries[:expired] {
+			err := bucket.Delete(key(e.hash))
+			if err != nil {
+				return err
+			}
+		}
+
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, uint64(now.UnixNano()))
+		return bucket.Put(key(hash), value)
+	})
+	if err != nil {
+		return err
+	}
+
+	i.entries = append(i.entries[expired:], entry{hash: hash, seen: now})
+
+	return nil
+}
+
+// Release drops a reserved hash
+func (i *index) Release(hash imagehash.Hash) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.release(hash)
+}
+
+func (i *index) release(hash imagehash.Hash) {
+	for k, pending := range i.pending {
+		if pending == hash {
+			i.pending = append(i.pending[:k], i.pending[k+1:]...)
+			return
+		}
+	}
+}
+
+func (i *index) expired(e entry, now time.Time) bool {
+	return i.maxAge > 0 && now.Sub(e.seen) > i.maxAge
+}
+
+func (i *index) Close() error {
+	return i.db.Close()
+}
+
+func key(hash imagehash.Hash) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(hash))
+	return k
+}
@@ -0,0 +1,68 @@
+package phash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/imagehash"
+)
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	i, err := openIndex(dir, "phash", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := imagehash.Hash(0xff00ff00ff00ff00)
+	near := hash ^ 0x7
+	far := ^hash
+
+	if _, _, duplicate := i.Reserve(hash, 5); duplicate {
+		t.Fatal("Reserve() of an empty index is a duplicate")
+	}
+
+	// a job in flight reserves its hash
+	match, distance, duplicate := i.Reserve(near, 5)
+	if !duplicate || match != hash || distance != 3 {
+		t.Errorf("Reserve() = %v, %d, %v, want %v, 3, true", match, distance, duplicate, hash)
+	}
+
+	// a failed job releases it
+	i.Release(hash)
+	if _, _, duplicate := i.Reserve(near, 5); duplicate {
+		t.Error("Reserve() matched a released hash")
+	}
+	i.Release(near)
+
+	if _, _, duplicate := i.Reserve(hash, 5); duplicate {
+		t.Fatal("Reserve() matched a released hash")
+	}
+	err = i.Commit(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, duplicate := i.Reserve(far, 5); duplicate {
+		t.Error("Reserve() matched a far hash")
+	}
+	i.Release(far)
+
+	err = i.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// committed hashes are persisted
+	i, err = openIndex(dir, "phash", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+
+	match, distance, duplicate = i.Reserve(near, 5)
+	if !duplicate || match != hash || distance != 3 {
+		t.Errorf("Reserve() after reopening = %v, %d, %v, want %v, 3, true", match, distance, duplicate, hash)
+	}
+}
@@ -0,0 +1,147 @@
+package phash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"time"
+
+	appcfg "github.com/sherifabdlnaby/prism/app/config"
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/imagehash"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// DuplicateField is the payload.Data key set to true if the image is a near-duplicate of a recently seen image.
+const DuplicateField = "_duplicate"
+
+var hashers = map[string]func(image.Image) imagehash.Hash{
+	"ahash": imagehash.Average,
+	"dhash": imagehash.Difference,
+	"phash": imagehash.Perceptual,
+}
+
+// PHash is a read-only plugin that adds perceptual hashes of the image to payload.Data (_ahash, _dhash, _phash) as hex,
+// and can detect near-duplicates of recently seen images, either dropping them or flagging them with _duplicate. images
+// are seen once their job succeeds.
+type PHash struct {
+	logger zap.SugaredLogger
+	config config
+	index  *index
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &PHash{}
+}
+
+// Init phash plugin
+func (p *PHash) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	p.config = *defaultConfig()
+	err = config.Populate(&p.config)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Directory == "" {
+		p.config.Directory = appcfg.EnvPrismDataDir.Lookup()
+	}
+
+	p.logger = logger
+	return nil
+}
+
+// Start opens the duplicates index
+func (p *PHash) Start() error {
+	if !p.config.Dedupe {
+		return nil
+	}
+
+	var err error
+	p.index, err = openIndex(p.config.Directory, p.config.Index, time.Duration(p.config.MaxAge)*time.Second)
+	return err
+}
+
+// Stop closes the duplicates index
+func (p *PHash) Stop() error {
+	if p.index == nil {
+		return nil
+	}
+	return p.index.Close()
+}
+
+// Decode decodes the image
+func (p *PHash) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return p.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image
+func (p *PHash) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return decode.Image(in)
+}
+
+// Process hashes the image and looks up duplicates
+func (p *PHash) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	img := in.(image.Image)
+
+	hashes := make(map[string]imagehash.Hash, len(p.config.Hashes))
+	for _, name := range p.config.Hashes {
+		hashes[name] = hashers[name](img)
+		data["_"+name] = hashes[name].String()
+	}
+
+	if !p.config.Dedupe {
+		return response.Ack()
+	}
+
+	hash, ok := hashes[p.config.Hash]
+	if !ok {
+		hash = hashers[p.config.Hash](img)
+	}
+
+	// the hash is reported so it's added to the index once the job succeeds
+	data["_"+p.config.Hash] = hash.String()
+
+	match, distance, duplicate := p.index.Reserve(hash, p.config.Distance)
+	data[DuplicateField] = duplicate
+
+	if p.config.Drop && duplicate {
+		return response.Reject(CodeDuplicate,
+			fmt.Sprintf("image is a near-duplicate of a recently seen image, distance %d is at most %d", distance,
+				p.config.Distance),
+			map[string]interface{}{"hash": hash.String(), "match": match.String(), "distance": distance,
+				"max_distance": p.config.Distance})
+	}
+
+	return response.Ack()
+}
+
+// Complete adds the hash of an image to the index once its job succeeded, images of failed jobs aren't seen when
+// they're retried.
+func (p *PHash) Complete(data payload.Data, res response.Response) {
+	if p.index == nil || data[DuplicateField] != false {
+		return
+	}
+
+	value, _ := data["_"+p.config.Hash].(string)
+	hash, err := imagehash.Parse(value)
+	if err != nil {
+		return
+	}
+
+	if !res.Ack {
+		p.index.Release(hash)
+		return
+	}
+
+	err = p.index.Commit(hash)
+	if err != nil {
+		p.logger.Errorw("failed to add hash to index", "hash", value, "error", err.Error())
+	}
+}
@@ -0,0 +1,6 @@
+package phash
+
+// Codes of rejection reasons, duplicates are reported with the hash they matched and their distance.
+const (
+	CodeDuplicate = "DUPLICATE"
+)
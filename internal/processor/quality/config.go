@@ -0,0 +1,31 @@
+package quality
+
+import (
+	"github.com/sherifabdlnaby/prism/pkg/quality"
+)
+
+type config struct {
+	// Field is the payload.Data key of the metrics and scores, Bins is the number of ranges of the luminance histogram.
+	Field string `validate:"required"`
+	Bins  int    `validate:"min=1,max=256"`
+
+	// Weights of the sharpness, exposure, noise and resolution scores in the combined score.
+	Weights quality.Weights
+
+	// Thresholds NoAcking images, 0 disables a threshold. Brightness is from 0 to 1, MinSharpness is the Laplacian
+	// variance, MaxNoise is the noise standard deviation (0-255) and MinScore is the combined score from 0 to 1.
+	MinSharpness  float64 `mapstructure:"min_sharpness" validate:"min=0"`
+	MinBrightness float64 `mapstructure:"min_brightness" validate:"min=0,max=1"`
+	MaxBrightness float64 `mapstructure:"max_brightness" validate:"min=0,max=1"`
+	MaxNoise      float64 `mapstructure:"max_noise" validate:"min=0"`
+	MinScore      float64 `mapstructure:"min_score" validate:"min=0,max=1"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Field:   "_quality",
+		Bins:    16,
+		Weights: quality.DefaultWeights(),
+	}
+}
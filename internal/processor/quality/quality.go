@@ -0,0 +1,109 @@
+package quality
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/quality"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Quality is a read-only plugin that measures sharpness (Laplacian variance), exposure (brightness, contrast,
+// clipping and histogram) and noise of images, it adds them and a combined quality score to payload.Data and can NoAck
+// blurry, badly exposed, noisy or low quality images.
+type Quality struct {
+	logger zap.SugaredLogger
+	config config
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Quality{}
+}
+
+// Init quality plugin
+func (q *Quality) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	q.config = *defaultConfig()
+	err = config.Populate(&q.config)
+	if err != nil {
+		return err
+	}
+
+	q.logger = logger
+	return nil
+}
+
+// Start quality plugin
+func (q *Quality) Start() error {
+	return nil
+}
+
+// Stop quality plugin
+func (q *Quality) Stop() error {
+	return nil
+}
+
+// Decode decodes the image
+func (q *Quality) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return q.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image
+func (q *Quality) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return decode.Image(in)
+}
+
+// Process measures the image quality
+func (q *Quality) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	metrics := quality.Measure(in.(image.Image), q.config.Bins)
+	scores := metrics.Scores()
+	score := scores.Combine(q.config.Weights)
+
+	data[q.config.Field] = map[string]interface{}{
+		"score":        score,
+		"sharpness":    metrics.Sharpness,
+		"brightness":   metrics.Brightness,
+		"contrast":     metrics.Contrast,
+		"underexposed": metrics.Underexposed,
+		"overexposed":  metrics.Overexposed,
+		"histogram":    metrics.Histogram,
+		"noise":        metrics.Noise,
+		"scores": map[string]interface{}{
+			"sharpness":  scores.Sharpness,
+			"exposure":   scores.Exposure,
+			"noise":      scores.Noise,
+			"resolution": scores.Resolution,
+		},
+	}
+
+	c := q.config
+	switch {
+	case c.MinBrightness > 0 && metrics.Brightness < c.MinBrightness:
+		return reject(CodeUnderexposed, "image is too dark", "brightness", metrics.Brightness, "below", c.MinBrightness)
+	case c.MaxBrightness > 0 && metrics.Brightness > c.MaxBrightness:
+		return reject(CodeOverexposed, "image is too bright", "brightness", metrics.Brightness, "above", c.MaxBrightness)
+	case c.MinSharpness > 0 && metrics.Sharpness < c.MinSharpness:
+		return reject(CodeBlurry, "image is blurry", "sharpness", metrics.Sharpness, "below", c.MinSharpness)
+	case c.MaxNoise > 0 && metrics.Noise > c.MaxNoise:
+		return reject(CodeNoisy, "image is noisy", "noise", metrics.Noise, "above", c.MaxNoise)
+	case c.MinScore > 0 && score < c.MinScore:
+		return reject(CodeLowQuality, "image quality is low", "score", score, "below", c.MinScore)
+	}
+
+	return response.Ack()
+}
+
+// reject NoAcks with the metric and its threshold, e.g "image is blurry, sharpness 12.50 is below 100.00"
+func reject(code, problem, metric string, value float64, comparison string, threshold float64) response.Response {
+	return response.Reject(code,
+		fmt.Sprintf("%s, %s %.2f is %s %.2f", problem, metric, value, comparison, threshold),
+		map[string]interface{}{"metric": metric, "value": value, "threshold": threshold})
+}
@@ -0,0 +1,10 @@
+package quality
+
+// Codes of rejection reasons, reported with the metric value and its threshold.
+const (
+	CodeBlurry       = "BLURRY"
+	CodeUnderexposed = "UNDEREXPOSED"
+	CodeOverexposed  = "OVEREXPOSED"
+	CodeNoisy        = "NOISY"
+	CodeLowQuality   = "LOW_QUALITY"
+)
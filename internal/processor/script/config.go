@@ -0,0 +1,20 @@
+package script
+
+type config struct {
+	// Script is an expr-lang (https://expr-lang.org) script, or File is the path of one.
+	Script string
+	File   string
+
+	// Timeout of a script run in milliseconds, checked by every iteration of loops, 0 disables it.
+	Timeout int `validate:"min=0"`
+	// MaxMemory is the memory budget of a script run, counted in allocated elements (e.g by arrays and ranges).
+	MaxMemory uint `mapstructure:"max_memory" validate:"min=1"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{
+		Timeout:   100,
+		MaxMemory: 1000000,
+	}
+}
@@ -0,0 +1,93 @@
+package script
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// errTimeout stops a script that runs past its deadline
+var errTimeout = errors.New("script timed out")
+
+// run is the state of a single script run, scripts work on a copy of payload.Data and their changes are recorded to be
+// applied once it completes, so a timed out script can't change it.
+type run struct {
+	data    map[string]interface{}
+	image   map[string]interface{}
+	changes  []change
+	dropped  *string
+	deadline time.Time
+}
+
+type change struct {
+	field  string
+	value  interface{}
+	remove bool
+}
+
+func newRun(data payload.Data, header header, deadline time.Time) *run {
+	copied := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		copied[key] = value
+	}
+
+	return &run{
+		data: copied,
+		image: map[string]interface{}{
+			"width":  header.width,
+			"height": header.height,
+			"format": header.format,
+		},
+		deadline: deadline,
+	}
+}
+
+// env return the variables and functions available to scripts
+func (r *run) env() map[string]interface{} {
+	return map[string]interface{}{
+		"data":   r.data,
+		"image":  r.image,
+		"set":    r.set,
+		"remove": r.remove,
+		"drop":   r.drop,
+
+		deadlineFunc: r.check,
+	}
+}
+
+func (r *run) set(field string, value interface{}) bool {
+	r.data[field] = value
+	r.changes = append(r.changes, change{field: field, value: value})
+	return true
+}
+
+func (r *run) remove(field string) bool {
+	delete(r.data, field)
+	r.changes = append(r.changes, change{field: field, remove: true})
+	return true
+}
+
+func (r *run) drop(message string) bool {
+	r.dropped = &message
+	return true
+}
+
+// check stops the run once it is past its deadline, it is called by every iteration of loops (see loopDeadline).
+func (r *run) check() bool {
+	if !r.deadline.IsZero() && time.Now().After(r.deadline) {
+		panic(errTimeout)
+	}
+	return true
+}
+
+// apply the recorded changes to payload.Data
+func (r *run) apply(data payload.Data) {
+	for _, change := range r.changes {
+		if change.remove {
+			delete(data, change.field)
+			continue
+		}
+		data[change.field] = change.value
+	}
+}
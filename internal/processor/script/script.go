@@ -0,0 +1,150 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/decode"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CodeScriptRejected is the code of rejection reasons of images dropped by a script
+const CodeScriptRejected = "SCRIPT_REJECTED"
+
+// Script is a read-only plugin that runs an expr-lang script for business rules, e.g
+//	set("width", data.plan == "premium" ? 2048 : 1024)
+// scripts read payload.Data as `data` and the image width, height and format as `image`, write payload.Data with
+// set(field, value) and remove(field), and drop the image with drop(message) or by evaluating to false.
+// Runs are bounded by max_memory and timeout, which is checked by every iteration of loops, a single builtin call (e.g
+// sorting a large array) is not interrupted so scripts are not a sandbox for untrusted code.
+type Script struct {
+	logger  zap.SugaredLogger
+	config  config
+	program *vm.Program
+}
+
+// header is the decoded image header exposed to scripts
+type header struct {
+	width, height int
+	format        string
+}
+
+// deadlineFunc is the function of the run called by patched loops.
+const deadlineFunc = "$deadline"
+
+// loopDeadline patches the predicate of every loop (e.g all, filter and map) to check the deadline of the run first,
+// expr has no other loops so scripts stop shortly after their timeout.
+type loopDeadline struct{}
+
+// Visit patches a node
+func (loopDeadline) Visit(node *ast.Node) {
+	predicate, ok := (*node).(*ast.PredicateNode)
+	if !ok {
+		return
+	}
+
+	check := &ast.CallNode{Callee: &ast.IdentifierNode{Value: deadlineFunc}}
+	predicate.Node = &ast.SequenceNode{Nodes: []ast.Node{check, predicate.Node}}
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Script{}
+}
+
+// Init script plugin, compiles the script
+func (s *Script) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	s.config = *defaultConfig()
+	err = config.Populate(&s.config)
+	if err != nil {
+		return err
+	}
+
+	if (s.config.Script == "") == (s.config.File == "") {
+		return fmt.Errorf("either script or file must be set")
+	}
+
+	source := s.config.Script
+	if s.config.File != "" {
+		buffer, err := ioutil.ReadFile(s.config.File)
+		if err != nil {
+			return err
+		}
+		source = string(buffer)
+	}
+
+	s.program, err = expr.Compile(source, expr.Env(newRun(payload.Data{}, header{}, time.Time{}).env()), expr.Patch(loopDeadline{}))
+	if err != nil {
+		return fmt.Errorf("failed to compile script: %s", err.Error())
+	}
+
+	s.logger = logger
+	return nil
+}
+
+// Start script plugin
+func (s *Script) Start() error {
+	return nil
+}
+
+// Stop script plugin
+func (s *Script) Stop() error {
+	return nil
+}
+
+// Decode decodes the image header
+func (s *Script) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return s.DecodeStream(bytes.NewReader(in), data)
+}
+
+// DecodeStream decodes the image header
+func (s *Script) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	config, format, Response := decode.Config(in)
+	if !Response.Ack {
+		return nil, Response
+	}
+
+	return header{width: config.Width, height: config.Height, format: format}, response.Ack()
+}
+
+// Process runs the script, its changes to payload.Data are applied only if it completes.
+func (s *Script) Process(in payload.DecodedImage, data payload.Data) response.Response {
+	var deadline time.Time
+	if s.config.Timeout > 0 {
+		deadline = time.Now().Add(time.Duration(s.config.Timeout) * time.Millisecond)
+	}
+
+	r := newRun(data, in.(header), deadline)
+
+	machine := vm.VM{MemoryBudget: s.config.MaxMemory}
+	result, err := machine.Run(s.program, r.env())
+	if errors.Is(err, errTimeout) {
+		return response.Error(fmt.Errorf("script timed out after %dms", s.config.Timeout))
+	}
+	if err != nil {
+		return response.Error(err)
+	}
+
+	if result == false && r.dropped == nil {
+		r.drop("image is rejected by script")
+	}
+
+	if r.dropped != nil {
+		return response.Reject(CodeScriptRejected, *r.dropped, nil)
+	}
+
+	r.apply(data)
+	return response.Ack()
+}
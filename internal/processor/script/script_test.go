@@ -0,0 +1,103 @@
+package script
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"go.uber.org/zap"
+)
+
+func TestProcess(t *testing.T) {
+	items := make([]interface{}, 1000)
+	for i := range items {
+		items[i] = i
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		timeout int
+		want    payload.Data
+		code    string
+		message string
+		err     string
+	}{
+		{
+			name:   "accept",
+			script: `set("size", image.width > 1000 ? "large" : "small") && remove("plan")`,
+			want:   payload.Data{"size": "small", "items": items},
+		},
+		{
+			name:    "reject with reason",
+			script:  `data.plan == "free" && image.width > 100 ? drop("free plan is limited to 100px") : true`,
+			code:    CodeScriptRejected,
+			message: "free plan is limited to 100px",
+		},
+		{
+			name:    "reject by evaluating to false",
+			script:  `set("size", "large") && image.format == "gif"`,
+			code:    CodeScriptRejected,
+			message: "image is rejected by script",
+		},
+		{
+			name:   "runtime error",
+			script: `set("size", "small") && int(data.plan) > 0`,
+			err:    "invalid operation: int(free)",
+		},
+		{
+			name:    "timeout",
+			script:  `all(data.items, {all(data.items, {all(data.items, # >= 0)})})`,
+			timeout: 10,
+			err:     "script timed out after 10ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Script{}
+			err := s.Init(*cfg.NewConfig(map[string]interface{}{
+				"script":  tt.script,
+				"timeout": tt.timeout,
+			}), *zap.NewNop().Sugar())
+			if err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			data := payload.Data{"plan": "free", "items": items}
+			started := time.Now()
+			Response := s.Process(header{width: 640, height: 480, format: "png"}, data)
+
+			switch {
+			case tt.err != "":
+				if Response.Error == nil || !strings.Contains(Response.Error.Error(), tt.err) {
+					t.Fatalf("Error = %v, want %q", Response.Error, tt.err)
+				}
+				if time.Since(started) > time.Second {
+					t.Errorf("Process() took %v, want it stopped by its timeout", time.Since(started))
+				}
+			case tt.code != "":
+				reason, ok := Response.Reason()
+				if !ok || reason.Code != tt.code || reason.Message != tt.message {
+					t.Fatalf("Reason() = %v, want %s: %q", reason, tt.code, tt.message)
+				}
+			default:
+				if !Response.Ack {
+					t.Fatalf("Process() = %+v, want ack", Response)
+				}
+				if !reflect.DeepEqual(data, tt.want) {
+					t.Errorf("data = %v, want %v", data, tt.want)
+				}
+				return
+			}
+
+			// changes of scripts that don't complete are not applied
+			if !reflect.DeepEqual(data, payload.Data{"plan": "free", "items": items}) {
+				t.Errorf("data = %v, want it unchanged", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package transform
+
+type config struct {
+	// Operations is an ordered list of operations, each is a map with a single operation, e.g `- rename: {...}`.
+	Operations []interface{} `validate:"min=1"`
+}
+
+//defaultConfig returns the default configs
+func defaultConfig() *config {
+	return &config{}
+}
+
+// setDefaults return default configuration for set operation configuration
+func setDefaults() *set {
+	return &set{}
+}
+
+// renameDefaults return default configuration for rename operation configuration
+func renameDefaults() *rename {
+	return &rename{}
+}
+
+// removeDefaults return default configuration for remove operation configuration
+func removeDefaults() *remove {
+	return &remove{}
+}
+
+// lowercaseDefaults return default configuration for lowercase operation configuration
+func lowercaseDefaults() *lowercase {
+	return &lowercase{}
+}
+
+// slugifyDefaults return default configuration for slugify operation configuration
+func slugifyDefaults() *slugify {
+	return &slugify{
+		Separator: "-",
+	}
+}
+
+// numberDefaults return default configuration for number operation configuration
+func numberDefaults() *number {
+	return &number{}
+}
+
+// hashDefaults return default configuration for hash operation configuration
+func hashDefaults() *hash {
+	return &hash{
+		Algorithm: "sha256",
+	}
+}
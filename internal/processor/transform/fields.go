@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// fields are dot-separated paths to nested maps in payload.Data (e.g `image.title`). payload.Data is copied shallowly
+// for every next node, so nested maps are shared with other branches of the pipeline, and are copied before writing.
+
+// get return the value of a field, ok is false if it doesn't exist
+func get(data payload.Data, field string) (interface{}, bool) {
+	parent, key, ok := lookup(data, field, false)
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := parent[key]
+	return value, ok
+}
+
+// put sets the value of a field, creating its parents if needed
+func put(data payload.Data, field string, value interface{}) error {
+	parent, key, ok := lookup(data, field, true)
+	if !ok {
+		return fmt.Errorf("field [%s] has a parent that is not a map", field)
+	}
+
+	parent[key] = value
+	return nil
+}
+
+// del deletes a field if it exists
+func del(data payload.Data, field string) {
+	if _, ok := get(data, field); !ok {
+		return
+	}
+
+	parent, key, _ := lookup(data, field, true)
+	delete(parent, key)
+}
+
+// lookup return the map holding the last key of field, and the key. when writing, maps along the path are copied and
+// missing ones are created.
+func lookup(data payload.Data, field string, write bool) (map[string]interface{}, string, bool) {
+	keys := strings.Split(field, ".")
+	current := map[string]interface{}(data)
+
+	for _, key := range keys[:len(keys)-1] {
+		var next map[string]interface{}
+		switch value := current[key].(type) {
+		case map[string]interface{}:
+			next = value
+		case payload.Data:
+			next = value
+		case nil:
+			if !write {
+				return nil, "", false
+			}
+		default:
+			return nil, "", false
+		}
+
+		if write {
+			next = clone(next)
+			current[key] = next
+		}
+		current = next
+	}
+
+	return current, keys[len(keys)-1], true
+}
+
+// clone return a shallow copy of a map
+func clone(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// str return the string representation of a value
+func str(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,49 @@
+package transform
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	gohash "hash"
+	"hash/fnv"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// hash replaces a field value by its hex encoded hash, the result is written to To if set, missing fields are skipped.
+type hash struct {
+	Field     string `validate:"required"`
+	To        string
+	Algorithm string `validate:"oneof=md5 sha1 sha256 sha512 fnv"`
+	hash      func() gohash.Hash
+}
+
+func (o *hash) Init() error {
+	switch o.Algorithm {
+	case "md5":
+		o.hash = md5.New
+	case "sha1":
+		o.hash = sha1.New
+	case "sha256":
+		o.hash = sha256.New
+	case "sha512":
+		o.hash = sha512.New
+	case "fnv":
+		o.hash = func() gohash.Hash { return fnv.New64a() }
+	}
+	return nil
+}
+
+func (o *hash) Apply(data payload.Data) error {
+	value, ok := get(data, o.Field)
+	if !ok {
+		return nil
+	}
+
+	h := o.hash()
+	_, _ = h.Write([]byte(str(value)))
+
+	return put(data, target(o.Field, o.To), hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,41 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// number parses a field value as an integer, or a float if it's not an integer, the result is written to To if set,
+// missing fields are skipped.
+type number struct {
+	Field string `validate:"required"`
+	To    string
+}
+
+func (o *number) Init() error {
+	return nil
+}
+
+func (o *number) Apply(data payload.Data) error {
+	value, ok := get(data, o.Field)
+	if !ok {
+		return nil
+	}
+
+	text := strings.TrimSpace(str(value))
+
+	integer, err := strconv.ParseInt(text, 10, 64)
+	if err == nil {
+		return put(data, target(o.Field, o.To), integer)
+	}
+
+	float, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("field [%s] is not a number, got: %s", o.Field, text)
+	}
+
+	return put(data, target(o.Field, o.To), float)
+}
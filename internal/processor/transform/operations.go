@@ -0,0 +1,63 @@
+package transform
+
+import (
+	"fmt"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// operation transforms payload.Data
+type operation interface {
+	Init() error
+	Apply(data payload.Data) error
+}
+
+// constructors of operations by name, returning their defaults
+var constructors = map[string]func() operation{
+	"set":       func() operation { return setDefaults() },
+	"rename":    func() operation { return renameDefaults() },
+	"remove":    func() operation { return removeDefaults() },
+	"lowercase": func() operation { return lowercaseDefaults() },
+	"slugify":   func() operation { return slugifyDefaults() },
+	"number":    func() operation { return numberDefaults() },
+	"hash":      func() operation { return hashDefaults() },
+}
+
+// newOperations decode the operations list, every item is a map with a single operation.
+func newOperations(raw []interface{}) ([]operation, error) {
+	operations := make([]operation, 0, len(raw))
+	for i, item := range raw {
+		op, ok := item.(map[string]interface{})
+		if !ok || len(op) != 1 {
+			return nil, fmt.Errorf("operations[%d] must be a single operation", i)
+		}
+
+		for name, config := range op {
+			constructor, ok := constructors[name]
+			if !ok {
+				return nil, fmt.Errorf("operations[%d]: unknown operation [%s]", i, name)
+			}
+
+			values, ok := config.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("operations[%d]: operation [%s] config must be a map", i, name)
+			}
+
+			operation := constructor()
+			err := cfg.NewConfig(values).Populate(operation)
+			if err != nil {
+				return nil, fmt.Errorf("operations[%d]: %s", i, err.Error())
+			}
+
+			err = operation.Init()
+			if err != nil {
+				return nil, fmt.Errorf("operations[%d]: %s", i, err.Error())
+			}
+
+			operations = append(operations, operation)
+		}
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,21 @@
+package transform
+
+import (
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// remove deletes fields, missing fields are skipped
+type remove struct {
+	Fields []string `validate:"min=1,dive,required"`
+}
+
+func (o *remove) Init() error {
+	return nil
+}
+
+func (o *remove) Apply(data payload.Data) error {
+	for _, field := range o.Fields {
+		del(data, field)
+	}
+	return nil
+}
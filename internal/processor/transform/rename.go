@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// rename moves a field, missing fields are skipped
+type rename struct {
+	Field string `validate:"required"`
+	To    string `validate:"required"`
+}
+
+func (o *rename) Init() error {
+	return nil
+}
+
+func (o *rename) Apply(data payload.Data) error {
+	value, ok := get(data, o.Field)
+	if !ok || o.Field == o.To {
+		return nil
+	}
+
+	// the field is replaced by a map holding its value
+	if strings.HasPrefix(o.To, o.Field+".") {
+		del(data, o.Field)
+		return put(data, o.To, value)
+	}
+
+	err := put(data, o.To, value)
+	if err != nil {
+		return err
+	}
+
+	del(data, o.Field)
+	return nil
+}
@@ -0,0 +1,28 @@
+package transform
+
+import (
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// set a field to a template, e.g `@{_filename}-@{_id}`, fails if fields used by the template are missing.
+type set struct {
+	Field string `validate:"required"`
+	Value string
+	value cfg.Selector
+}
+
+func (o *set) Init() error {
+	var err error
+	o.value, err = cfg.NewSelector(o.Value)
+	return err
+}
+
+func (o *set) Apply(data payload.Data) error {
+	value, err := o.value.Evaluate(data)
+	if err != nil {
+		return err
+	}
+
+	return put(data, o.Field, value)
+}
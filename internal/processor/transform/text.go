@@ -0,0 +1,60 @@
+package transform
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// lowercase a field value, the result is written to To if set, missing fields are skipped
+type lowercase struct {
+	Field string `validate:"required"`
+	To    string
+}
+
+func (o *lowercase) Init() error {
+	return nil
+}
+
+func (o *lowercase) Apply(data payload.Data) error {
+	value, ok := get(data, o.Field)
+	if !ok {
+		return nil
+	}
+
+	return put(data, target(o.Field, o.To), strings.ToLower(str(value)))
+}
+
+// slugify a field value to lower-case letters and digits joined by Separator, e.g `My Photo (1)` -> `my-photo-1`, the
+// result is written to To if set, missing fields are skipped.
+type slugify struct {
+	Field     string `validate:"required"`
+	To        string
+	Separator string
+}
+
+func (o *slugify) Init() error {
+	return nil
+}
+
+func (o *slugify) Apply(data payload.Data) error {
+	value, ok := get(data, o.Field)
+	if !ok {
+		return nil
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(str(value)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return put(data, target(o.Field, o.To), strings.Join(words, o.Separator))
+}
+
+// target return the field a result is written to, the source field itself if to is empty
+func target(field, to string) string {
+	if to == "" {
+		return field
+	}
+	return to
+}
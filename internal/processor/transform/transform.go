@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"github.com/sherifabdlnaby/prism/pkg/component"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Transform is a read-only plugin that shapes payload.Data (set fields from templates, rename, remove, lower-case,
+// slugify, parse numbers and hash values) without decoding the image, the payload is passed through as is.
+type Transform struct {
+	logger     zap.SugaredLogger
+	config     config
+	operations []operation
+}
+
+// NewComponent Return a new Base
+func NewComponent() component.Base {
+	return &Transform{}
+}
+
+// Init transform plugin
+func (t *Transform) Init(config cfg.Config, logger zap.SugaredLogger) error {
+	var err error
+
+	t.config = *defaultConfig()
+	err = config.Populate(&t.config)
+	if err != nil {
+		return err
+	}
+
+	t.operations, err = newOperations(t.config.Operations)
+	if err != nil {
+		return err
+	}
+
+	t.logger = logger
+	return nil
+}
+
+// Start transform plugin
+func (t *Transform) Start() error {
+	return nil
+}
+
+// Stop transform plugin
+func (t *Transform) Stop() error {
+	return nil
+}
+
+// Decode doesn't read the image, it is passed through untouched
+func (t *Transform) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	return nil, response.Ack()
+}
+
+// DecodeStream doesn't read the image, it is passed through untouched
+func (t *Transform) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
+	return nil, response.Ack()
+}
+
+// Process applies operations to payload.Data in order
+func (t *Transform) Process(_ payload.DecodedImage, data payload.Data) response.Response {
+	for _, op := range t.operations {
+		err := op.Apply(data)
+		if err != nil {
+			return response.Error(err)
+		}
+	}
+
+	return response.Ack()
+}
@@ -0,0 +1,122 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+func TestOperations(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      operation
+		want    payload.Data
+		wantErr bool
+	}{
+		{
+			name: "set nested",
+			op:   &set{Field: "image.slug", Value: "@{id}-photo"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "slug": "7-photo", "meta": map[string]interface{}{"camera": "X100"}}},
+		},
+		{
+			name: "set creates parents",
+			op:   &set{Field: "image.meta.lens.name", Value: "23mm"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{"title": "My Photo", "size": "12",
+				"meta": map[string]interface{}{"camera": "X100", "lens": map[string]interface{}{"name": "23mm"}}}},
+		},
+		{
+			name:    "set under a value",
+			op:      &set{Field: "image.title.text", Value: "x"},
+			wantErr: true,
+		},
+		{
+			name: "rename nested",
+			op:   &rename{Field: "image.meta.camera", To: "camera"},
+			want: payload.Data{"id": "7", "camera": "X100", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{}}},
+		},
+		{
+			name: "rename into itself",
+			op:   &rename{Field: "id", To: "id.value"},
+			want: payload.Data{"id": map[string]interface{}{"value": "7"}, "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{"camera": "X100"}}},
+		},
+		{
+			name: "rename to the same field",
+			op:   &rename{Field: "image.title", To: "image.title"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{"camera": "X100"}}},
+		},
+		{
+			name:    "rename failing keeps the field",
+			op:      &rename{Field: "image.size", To: "image.title.size"},
+			wantErr: true,
+		},
+		{
+			name: "remove nested",
+			op:   &remove{Fields: []string{"image.meta.camera", "image.missing.field"}},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{}}},
+		},
+		{
+			name: "hash nested",
+			op:   &hash{Field: "image.title", To: "image.meta.md5", Algorithm: "md5"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{"title": "My Photo", "size": "12",
+				"meta": map[string]interface{}{"camera": "X100", "md5": "ca17e989f935299297613c3a26bc38f6"}}},
+		},
+		{
+			name: "number nested",
+			op:   &number{Field: "image.size"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": int64(12), "meta": map[string]interface{}{"camera": "X100"}}},
+		},
+		{
+			name: "lowercase nested",
+			op:   &lowercase{Field: "image.meta.camera"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{"camera": "x100"}}},
+		},
+		{
+			name: "slugify nested",
+			op:   &slugify{Field: "image.title", To: "image.slug", Separator: "-"},
+			want: payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "slug": "my-photo", "meta": map[string]interface{}{"camera": "X100"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op.Init(); err != nil {
+				t.Fatal(err)
+			}
+
+			// data of a sibling branch is a shallow copy sharing nested maps
+			shared := payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{"camera": "X100"}}}
+			sibling := payload.Data{"id": "7", "image": map[string]interface{}{
+				"title": "My Photo", "size": "12", "meta": map[string]interface{}{"camera": "X100"}}}
+
+			data := payload.Data{}
+			for k, v := range shared {
+				data[k] = v
+			}
+
+			err := tt.op.Apply(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("Apply() data = %v, want %v", data, tt.want)
+			}
+			if tt.wantErr && !reflect.DeepEqual(data, sibling) {
+				t.Errorf("Apply() failing changed data = %v, want %v", data, sibling)
+			}
+
+			if !reflect.DeepEqual(shared, sibling) {
+				t.Errorf("Apply() changed data of a sibling branch = %v, want %v", shared, sibling)
+			}
+		})
+	}
+}
@@ -7,10 +7,39 @@ type config struct {
 	MinHeight int      `mapstructure:"min_height" validate:"min=0"`
 	MinWidth  int      `mapstructure:"min_width" validate:"min=0"`
 
-	jpeg, png, webp, formatOnly bool
+	// frames of GIF/WebP are counted, adding _animated and _frames, only if animation is disallowed or limited.
+	AllowAnimated bool `mapstructure:"allow_animated"`
+	MaxFrames     int  `mapstructure:"max_frames" validate:"min=0"`
+	MaxDuration   int  `mapstructure:"max_duration" validate:"min=0"`
+
+	// MaxSize and MinSize are limits of the file size in bytes.
+	MaxSize int64 `mapstructure:"max_size" validate:"min=0"`
+	MinSize int64 `mapstructure:"min_size" validate:"min=0"`
+
+	// MaxMegapixels limits width x height, aspect ratio is width / height.
+	MaxMegapixels  float64 `mapstructure:"max_megapixels" validate:"min=0"`
+	MinAspectRatio float64 `mapstructure:"min_aspect_ratio" validate:"min=0"`
+	MaxAspectRatio float64 `mapstructure:"max_aspect_ratio" validate:"min=0"`
+
+	// ColorModes are the allowed colour modes, empty allows all.
+	ColorModes       []string `mapstructure:"color_modes" validate:"dive,oneof=rgb gray cmyk paletted"`
+	AllowProgressive bool     `mapstructure:"allow_progressive"`
+
+	// MaxMemory is the limit in MB of memory needed to decode the image according to its header dimensions, it guards
+	// processors decoding the image from decompression bombs. 0 disables it.
+	MaxMemory int `mapstructure:"max_memory" validate:"min=0"`
+
+	formats    map[string]bool
+	colorModes map[string]bool
+	formatOnly bool
+	inspect    bool
 }
 
 //defaultConfig returns the default configs
 func defaultConfig() *config {
-	return &config{}
+	return &config{
+		AllowAnimated:    true,
+		AllowProgressive: true,
+		MaxMemory:        1024,
+	}
 }
@@ -0,0 +1,22 @@
+package validator
+
+// Codes of rejection reasons, reported with the offending and allowed values.
+const (
+	CodeInvalidImage          = "INVALID_IMAGE"
+	CodeUnsupportedFormat     = "UNSUPPORTED_FORMAT"
+	CodeFormatNotAllowed      = "FORMAT_NOT_ALLOWED"
+	CodeAnimationNotAllowed   = "ANIMATION_NOT_ALLOWED"
+	CodeTooManyFrames         = "TOO_MANY_FRAMES"
+	CodeAnimationTooLong      = "ANIMATION_TOO_LONG"
+	CodeFileTooLarge          = "FILE_TOO_LARGE"
+	CodeFileTooSmall          = "FILE_TOO_SMALL"
+	CodeTooLargeToDecode      = "TOO_LARGE_TO_DECODE"
+	CodeTooWide               = "TOO_WIDE"
+	CodeTooNarrow             = "TOO_NARROW"
+	CodeTooTall               = "TOO_TALL"
+	CodeTooShort              = "TOO_SHORT"
+	CodeTooManyMegapixels     = "TOO_MANY_MEGAPIXELS"
+	CodeAspectRatioNotAllowed = "ASPECT_RATIO_NOT_ALLOWED"
+	CodeColorModeNotAllowed   = "COLOR_MODE_NOT_ALLOWED"
+	CodeProgressiveNotAllowed = "PROGRESSIVE_NOT_ALLOWED"
+)
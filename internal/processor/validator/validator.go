@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"image"
+	"image/color"
 	// register GIF to decode function
 	_ "image/gif"
 	// register JPEG to decode function
@@ -11,11 +14,17 @@ import (
 	// register PNG to decode function
 	_ "image/png"
 	"io"
+	"io/ioutil"
+	"time"
 
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/matchers"
+	"github.com/h2non/filetype/matchers/isobmff"
+	"github.com/sherifabdlnaby/prism/pkg/animation"
 	"github.com/sherifabdlnaby/prism/pkg/component"
 
+	// register tiff to decode function
+	_ "golang.org/x/image/tiff"
 	// register webp to decode function
 	_ "golang.org/x/image/webp"
 
@@ -33,9 +42,45 @@ type Validator struct {
 	config config
 }
 
+// Fields added to payload.Data besides format and dimensions
+const (
+	SizeField        = "_size"
+	ColorModeField   = "_color_mode"
+	ProgressiveField = "_progressive"
+)
+
+// formats that have a Go decoder, their header is read to get dimensions
+var decodable = map[string]bool{
+	"jpeg": true,
+	"png":  true,
+	"gif":  true,
+	"webp": true,
+	"tiff": true,
+}
+
 type header struct {
 	format string
+	frames animation.Info
 	image.Config
+
+	// size in bytes is -1 if not counted, decoded is false if Config wasn't read and inspected is false if frames
+	// weren't counted.
+	size        int64
+	decoded     bool
+	inspected   bool
+	progressive bool
+}
+
+// countingReader counts bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
 
 // NewComponent Return a new Base
@@ -54,24 +99,40 @@ func (d *Validator) Init(config cfg.Config, logger zap.SugaredLogger) error {
 	}
 
 	// set enabled types
+	d.config.formats = make(map[string]bool)
 	for _, value := range d.config.Format {
 		switch value {
 		case "jpeg", "jpg":
-			d.config.jpeg = true
-		case "png":
-			d.config.png = true
-		case "webp":
-			d.config.webp = true
+			d.config.formats["jpeg"] = true
+		case "tiff", "tif":
+			d.config.formats["tiff"] = true
+		case "png", "webp", "gif", "heif", "avif":
+			d.config.formats[value] = true
 		default:
 			return fmt.Errorf("unsuppprted format configuration [%s]", value)
 		}
 	}
 
+	d.config.colorModes = make(map[string]bool)
+	for _, value := range d.config.ColorModes {
+		d.config.colorModes[value] = true
+	}
+
 	// check if we'll only need to get format only (to use a faster method)
-	if d.config.MinHeight+d.config.MaxHeight+d.config.MinWidth+d.config.MaxWidth == 0 {
+	if d.config.MinHeight+d.config.MaxHeight+d.config.MinWidth+d.config.MaxWidth == 0 &&
+		d.config.MaxMegapixels+d.config.MinAspectRatio+d.config.MaxAspectRatio == 0 &&
+		len(d.config.ColorModes) == 0 && d.config.AllowProgressive {
 		d.config.formatOnly = true
 	}
 
+	// animated images are read whole to count their frames, only when a limit needs them
+	d.config.inspect = !d.config.AllowAnimated || d.config.MaxFrames > 0 || d.config.MaxDuration > 0
+
+	// there is no heif/avif decoder to read their header, so they can only be validated by format, size and frames.
+	if !d.config.formatOnly && (d.config.formats["heif"] || d.config.formats["avif"]) {
+		return fmt.Errorf("heif and avif formats can't be validated by dimensions, aspect ratio, color mode or progressive")
+	}
+
 	d.logger = logger
 	return nil
 }
@@ -91,79 +152,323 @@ func (d *Validator) Decode(in payload.Bytes, data payload.Data) (payload.Decoded
 	return d.DecodeStream(bytes.NewReader(in), data)
 }
 
-// DecodeStream Decodes an image reading only the necessary bytes to validate the image
+// DecodeStream Decodes an image reading only the necessary bytes to validate the image, the whole stream is read only
+// if file size is limited or frames of an animated image are limited, no further than max_size or max_memory.
 func (d *Validator) DecodeStream(in payload.Stream, data payload.Data) (payload.DecodedImage, response.Response) {
-	reader := in.(io.Reader)
+	counter := &countingReader{r: in.(io.Reader)}
+	buffered := bufio.NewReaderSize(counter, 512)
+	var reader io.Reader = buffered
 
-	var format string
+	// peek the bytes needed to detect the format without consuming them
+	head, _ := buffered.Peek(261)
+	if len(head) == 0 {
+		return nil, response.Reject(CodeInvalidImage, "bytes not enough to validate image type", nil)
+	}
+
+	format := detectFormat(head)
+
+	result := header{
+		format: format,
+		frames: animation.Info{Frames: 1},
+		size:   -1,
+	}
+
+	// formats that can be animated are read whole to count their frames
+	if d.config.inspect && (format == "gif" || format == "webp") {
+		limit := d.readLimit()
+		rest := io.Reader(buffered)
+		if limit > 0 {
+			rest = io.LimitReader(buffered, limit+1)
+		}
 
-	// if only need to check for type -> use the quicker method that only need 260 byte
-	if d.config.formatOnly {
-		head := make([]byte, 261)
-		_, err := io.ReadFull(reader, head)
+		buffer, err := ioutil.ReadAll(rest)
 		if err != nil {
-			return nil, response.NoAck(fmt.Errorf("bytes not enough to validate image type"))
+			return nil, response.Error(err)
 		}
 
-		if jpeg := filetype.IsType(head, matchers.TypeJpeg); jpeg {
-			format = "jpeg"
-		} else if png := filetype.IsType(head, matchers.TypePng); png {
-			format = "png"
-		} else if webp := filetype.IsType(head, matchers.TypeWebp); webp {
-			format = "webp"
+		if limit > 0 && int64(len(buffer)) > limit {
+			return nil, d.tooLarge()
 		}
 
-		return header{
-			format: format,
-		}, response.Ack()
+		result.frames, err = animation.Inspect(buffer)
+		if err != nil {
+			return nil, response.Reject(CodeInvalidImage, fmt.Sprintf("invalid image: %s", err.Error()), nil)
+		}
+
+		result.inspected = true
+		reader = bytes.NewReader(buffer)
 	}
 
-	// use image.Decode to get both format AND dimensions
-	config, format, err := image.DecodeConfig(reader)
-	if err != nil {
-		return nil, response.NoAck(fmt.Errorf("unsupported format"))
+	if format == "" {
+		return nil, response.Reject(CodeUnsupportedFormat, "unsupported format", nil)
 	}
 
-	return header{
-		format: format,
-		Config: config,
-	}, response.Ack()
+	// if only need to check for type -> use the quicker method that only need 260 byte. formats without a Go decoder
+	// (heif/avif) are never decoded, Init only allows them if no limit needs their header.
+	if decodable[format] && (!d.config.formatOnly || d.config.MaxMemory > 0) {
+		// keep the bytes read by the decoder to find how the image is encoded
+		read := &bytes.Buffer{}
+
+		// use image.Decode to get both format AND dimensions
+		config, format, err := image.DecodeConfig(io.TeeReader(reader, read))
+		if err != nil {
+			return nil, response.Reject(CodeUnsupportedFormat, "unsupported format", nil)
+		}
+
+		result.format = format
+		result.Config = config
+		result.decoded = true
+		result.progressive = progressive(format, read.Bytes())
+	}
+
+	// the rest of the stream is read to count its size, no further than needed to know it's too large.
+	if d.config.MaxSize > 0 || d.config.MinSize > 0 {
+		rest := io.Reader(buffered)
+		if d.config.MaxSize > 0 {
+			rest = io.LimitReader(buffered, d.config.MaxSize+1)
+		}
+
+		_, err := io.Copy(ioutil.Discard, rest)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		result.size = counter.n
+	}
+
+	return result, response.Ack()
 }
 
 // process will validate that the image is as configured. adding format and dimensions to payload.Dataa
 func (d *Validator) Process(in payload.DecodedImage, data payload.Data) response.Response {
 	header := in.(header)
 
-	switch header.format {
-	case "jpeg":
-		if !d.config.jpeg {
-			return response.NoAck(fmt.Errorf("unsupported format"))
+	if !d.config.formats[header.format] {
+		return response.Reject(CodeFormatNotAllowed, fmt.Sprintf("format %s is not allowed", header.format),
+			map[string]interface{}{"format": header.format, "allowed": d.config.Format})
+	}
+
+	data["_format"] = header.format
+	if header.inspected {
+		data[animation.AnimatedField] = header.frames.Animated
+		data[animation.FramesField] = header.frames.Frames
+	}
+
+	if header.frames.Animated && !d.config.AllowAnimated {
+		return response.Reject(CodeAnimationNotAllowed, "animated images are not allowed", nil)
+	}
+
+	if d.config.MaxFrames > 0 && header.frames.Frames > d.config.MaxFrames {
+		return response.Reject(CodeTooManyFrames,
+			fmt.Sprintf("animation has %d frames, more than the maximum %d", header.frames.Frames, d.config.MaxFrames),
+			map[string]interface{}{"frames": header.frames.Frames, "max_frames": d.config.MaxFrames})
+	}
+
+	if d.config.MaxDuration > 0 && header.frames.Duration > time.Duration(d.config.MaxDuration)*time.Millisecond {
+		duration := int(header.frames.Duration / time.Millisecond)
+		return response.Reject(CodeAnimationTooLong,
+			fmt.Sprintf("animation is %dms long, more than the maximum %dms", duration, d.config.MaxDuration),
+			map[string]interface{}{"duration": duration, "max_duration": d.config.MaxDuration})
+	}
+
+	if header.size >= 0 {
+		data[SizeField] = header.size
+
+		if d.config.MaxSize > 0 && header.size > d.config.MaxSize {
+			return response.Reject(CodeFileTooLarge,
+				fmt.Sprintf("file size %d bytes is more than the maximum %d bytes", header.size, d.config.MaxSize),
+				map[string]interface{}{"size": header.size, "max_size": d.config.MaxSize})
 		}
-	case "png":
-		if !d.config.png {
-			return response.NoAck(fmt.Errorf("unsupported format"))
+
+		if header.size < d.config.MinSize {
+			return response.Reject(CodeFileTooSmall,
+				fmt.Sprintf("file size %d bytes is less than the minimum %d bytes", header.size, d.config.MinSize),
+				map[string]interface{}{"size": header.size, "min_size": d.config.MinSize})
 		}
-	case "webp":
-		if !d.config.webp {
-			return response.NoAck(fmt.Errorf("unsupported format"))
+	}
+
+	if !header.decoded {
+		return response.Ack()
+	}
+
+	// check memory needed to decode first, before any other processor decodes the image
+	if d.config.MaxMemory > 0 && memory(header.Config) > int64(d.config.MaxMemory)<<20 {
+		return response.Reject(CodeTooLargeToDecode,
+			fmt.Sprintf("image dimensions %dx%d are too large to decode", header.Width, header.Height),
+			map[string]interface{}{"width": header.Width, "height": header.Height, "max_memory": d.config.MaxMemory})
+	}
+
+	mode := colorMode(header.ColorModel)
+
+	data["_width"] = header.Width
+	data["_height"] = header.Height
+	data[ColorModeField] = mode
+	data[ProgressiveField] = header.progressive
+
+	if d.config.MaxWidth > 0 && header.Width > d.config.MaxWidth {
+		return response.Reject(CodeTooWide,
+			fmt.Sprintf("image width %dpx is more than the maximum %dpx", header.Width, d.config.MaxWidth),
+			map[string]interface{}{"width": header.Width, "max_width": d.config.MaxWidth})
+	}
+
+	if header.Width < d.config.MinWidth {
+		return response.Reject(CodeTooNarrow,
+			fmt.Sprintf("image width %dpx is less than the minimum %dpx", header.Width, d.config.MinWidth),
+			map[string]interface{}{"width": header.Width, "min_width": d.config.MinWidth})
+	}
+
+	if d.config.MaxHeight > 0 && header.Height > d.config.MaxHeight {
+		return response.Reject(CodeTooTall,
+			fmt.Sprintf("image height %dpx is more than the maximum %dpx", header.Height, d.config.MaxHeight),
+			map[string]interface{}{"height": header.Height, "max_height": d.config.MaxHeight})
+	}
+
+	if header.Height < d.config.MinHeight {
+		return response.Reject(CodeTooShort,
+			fmt.Sprintf("image height %dpx is less than the minimum %dpx", header.Height, d.config.MinHeight),
+			map[string]interface{}{"height": header.Height, "min_height": d.config.MinHeight})
+	}
+
+	megapixels := float64(header.Width) * float64(header.Height) / 1e6
+	if d.config.MaxMegapixels > 0 && megapixels > d.config.MaxMegapixels {
+		return response.Reject(CodeTooManyMegapixels,
+			fmt.Sprintf("image has %.1f megapixels, more than the maximum %g", megapixels, d.config.MaxMegapixels),
+			map[string]interface{}{"megapixels": megapixels, "max_megapixels": d.config.MaxMegapixels})
+	}
+
+	if header.Height > 0 {
+		ratio := float64(header.Width) / float64(header.Height)
+		if ratio < d.config.MinAspectRatio || (d.config.MaxAspectRatio > 0 && ratio > d.config.MaxAspectRatio) {
+			return response.Reject(CodeAspectRatioNotAllowed, fmt.Sprintf("aspect ratio %.2f is not allowed", ratio),
+				map[string]interface{}{
+					"aspect_ratio":     ratio,
+					"min_aspect_ratio": d.config.MinAspectRatio,
+					"max_aspect_ratio": d.config.MaxAspectRatio,
+				})
 		}
+	}
+
+	if len(d.config.colorModes) > 0 && !d.config.colorModes[mode] {
+		return response.Reject(CodeColorModeNotAllowed, fmt.Sprintf("color mode %s is not allowed", mode),
+			map[string]interface{}{"color_mode": mode, "allowed": d.config.ColorModes})
+	}
+
+	if header.progressive && !d.config.AllowProgressive {
+		return response.Reject(CodeProgressiveNotAllowed, "progressive images are not allowed", nil)
+	}
+
+	return response.Ack()
+}
+
+// readLimit return the most bytes of a stream read in memory, 0 if unlimited
+func (d *Validator) readLimit() int64 {
+	if d.config.MaxSize > 0 {
+		return d.config.MaxSize
+	}
+
+	return int64(d.config.MaxMemory) << 20
+}
+
+// tooLarge return the rejection of a stream longer than readLimit
+func (d *Validator) tooLarge() response.Response {
+	if d.config.MaxSize > 0 {
+		return response.Reject(CodeFileTooLarge,
+			fmt.Sprintf("file size is more than the maximum %d bytes", d.config.MaxSize),
+			map[string]interface{}{"max_size": d.config.MaxSize})
+	}
+
+	return response.Reject(CodeTooLargeToDecode,
+		fmt.Sprintf("file size is more than the maximum memory %dMB", d.config.MaxMemory),
+		map[string]interface{}{"max_memory": d.config.MaxMemory})
+}
+
+// memory return bytes needed to hold the decoded image
+func memory(config image.Config) int64 {
+	bytesPerPixel := int64(4)
+	switch config.ColorModel {
+	case color.RGBA64Model, color.NRGBA64Model:
+		bytesPerPixel = 8
+	}
+
+	return int64(config.Width) * int64(config.Height) * bytesPerPixel
+}
+
+// colorMode return the name of a decoder colour model
+func colorMode(model color.Model) string {
+	if _, ok := model.(color.Palette); ok {
+		return "paletted"
+	}
+
+	switch model {
+	case color.GrayModel, color.Gray16Model:
+		return "gray"
+	case color.CMYKModel:
+		return "cmyk"
 	default:
-		return response.NoAck(fmt.Errorf("unsupported format"))
+		return "rgb"
 	}
+}
 
-	data["_format"] = header.format
+// progressive return true if a JPEG is progressive or a PNG is interlaced, read is the bytes read to decode the header.
+func progressive(format string, read []byte) bool {
+	switch format {
+	case "png":
+		// interlace method of IHDR
+		return len(read) > 28 && read[28] == 1
+	case "jpeg":
+		pos := 2
+		for pos+4 <= len(read) {
+			if read[pos] != 0xFF {
+				return false
+			}
 
-	if !d.config.formatOnly {
-		data["_width"] = header.Width
-		data["_height"] = header.Height
+			marker := read[pos+1]
+			switch {
+			case marker == 0xFF:
+				pos++
+				continue
+			case marker == 0xC2, marker == 0xC6, marker == 0xCA, marker == 0xCE:
+				return true
+			case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+				// baseline or extended start of frame
+				return false
+			}
+
+			pos += 2 + int(binary.BigEndian.Uint16(read[pos+2:]))
+		}
 	}
 
-	if header.Width > d.config.MaxWidth ||
-		header.Width < d.config.MinWidth ||
-		header.Height > d.config.MaxHeight ||
-		header.Height < d.config.MinHeight {
-		return response.NoAck(fmt.Errorf("unsupported dimensions"))
+	return false
+}
+
+// isAVIF checks for an ISOBMFF file with avif major brand, filetype doesn't match AVIF yet.
+func isAVIF(head []byte) bool {
+	if !isobmff.IsISOBMFF(head) {
+		return false
 	}
 
-	return response.Ack()
+	majorBrand, _, _ := isobmff.GetFtyp(head)
+	return majorBrand == "avif" || majorBrand == "avis"
+}
+
+// detectFormat detects image format from its first bytes
+func detectFormat(head []byte) string {
+	switch {
+	case filetype.IsType(head, matchers.TypeJpeg):
+		return "jpeg"
+	case filetype.IsType(head, matchers.TypePng):
+		return "png"
+	case filetype.IsType(head, matchers.TypeWebp):
+		return "webp"
+	case filetype.IsType(head, matchers.TypeGif):
+		return "gif"
+	case filetype.IsType(head, matchers.TypeTiff):
+		return "tiff"
+	case filetype.IsType(head, matchers.TypeHeif):
+		return "heif"
+	case isAVIF(head):
+		return "avif"
+	}
+
+	return ""
 }
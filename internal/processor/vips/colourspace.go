@@ -0,0 +1,90 @@
+package vips
+
+import (
+	"fmt"
+
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type colourspace struct {
+	Raw   colourspaceRawConfig `mapstructure:",squash"`
+	space cfg.Selector
+}
+
+type colourspaceRawConfig struct {
+	Space string
+}
+
+func (o *colourspace) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *colourspaceDefaults() {
+		return false, nil
+	}
+
+	o.space, err = cfg.NewSelector(o.Raw.Space)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *colourspace) Apply(p *bimg.Options, data payload.Data) error {
+
+	space, err := o.space.Evaluate(data)
+	if err != nil {
+		return err
+	}
+
+	switch space {
+	case "srgb":
+		p.Interpretation = bimg.InterpretationSRGB
+	case "rgb":
+		p.Interpretation = bimg.InterpretationRGB
+	case "grayscale", "b-w":
+		p.Interpretation = bimg.InterpretationBW
+	case "cmyk":
+		p.Interpretation = bimg.InterpretationCMYK
+	case "lab":
+		p.Interpretation = bimg.InterpretationLAB
+	case "xyz":
+		p.Interpretation = bimg.InterpretationXYZ
+	case "scrgb":
+		p.Interpretation = bimg.InterpretationScRGB
+	case "rgb16":
+		p.Interpretation = bimg.InterpretationRGB16
+	case "grey16":
+		p.Interpretation = bimg.InterpretationGREY16
+	default:
+		err = fmt.Errorf("invalid value for field [space], got: %s", space)
+	}
+
+	return err
+}
+
+// interpretationName return the space name of an interpretation as configured in colourspace
+func interpretationName(interpretation bimg.Interpretation) string {
+	switch interpretation {
+	case bimg.InterpretationRGB:
+		return "rgb"
+	case bimg.InterpretationBW:
+		return "b-w"
+	case bimg.InterpretationCMYK:
+		return "cmyk"
+	case bimg.InterpretationLAB:
+		return "lab"
+	case bimg.InterpretationXYZ:
+		return "xyz"
+	case bimg.InterpretationScRGB:
+		return "scrgb"
+	case bimg.InterpretationRGB16:
+		return "rgb16"
+	case bimg.InterpretationGREY16:
+		return "grey16"
+	default:
+		return "srgb"
+	}
+}
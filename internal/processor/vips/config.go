@@ -2,49 +2,96 @@ package vips
 
 import (
 	"github.com/sherifabdlnaby/bimg"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
 )
 
 // config struct used to decode YAML into
 type config struct {
-	Operations operations
+	// Operations is either a map of operations applied in a single stage, or an ordered list of operations where every
+	// operation is applied in its own stage.
+	Operations interface{}
 	Export     export
+
+	// Animated is what to do with animated GIF/WebP, only their first frame can be processed.
+	Animated string `validate:"oneof=first_frame reject"`
+
+	// AutoRotate rotates the image according to its EXIF orientation before any operation.
+	AutoRotate bool `mapstructure:"auto_rotate"`
+
+	// Cascade is the path of a pigo cascade file used to detect faces for the face anchor instead of the built-in one,
+	// faces the cascade doesn't detect are located by skin tone.
+	Cascade string
+	cascade *focus.Cascade
+
+	stages []*operations
 }
 
 // defaultConfig return default configuration for VIPS plugin configuration
 func defaultConfig() *config {
 	return &config{
-		Operations: operations{
-			Resize: resize{
-				Raw: *resizeDefaults(),
-			},
-			Flip: flip{
-				Raw: *flipDefaults(),
-			},
-			Blur: blur{
-				Raw: *blurDefaults(),
-			},
-			Rotate: rotate{
-				Raw: *rotateDefaults(),
-			},
-			Crop: crop{
-				Raw: *cropDefaults(),
-			},
-			Label: label{
-				Raw: *labelDefaults(),
-			},
-		},
+		Animated:   "first_frame",
+		AutoRotate: true,
 		Export: export{
 			Raw: *exportDefaults(),
 		},
 	}
 }
 
+// operationsDefaults return default configuration for a single stage of operations
+func operationsDefaults() *operations {
+	return &operations{
+		Resize: resize{
+			Raw: *resizeDefaults(),
+		},
+		Flip: flip{
+			Raw: *flipDefaults(),
+		},
+		Blur: blur{
+			Raw: *blurDefaults(),
+		},
+		Rotate: rotate{
+			Raw: *rotateDefaults(),
+		},
+		Crop: crop{
+			Raw: *cropDefaults(),
+		},
+		Label: label{
+			Raw: *labelDefaults(),
+		},
+		Extract: extract{
+			Raw: *extractDefaults(),
+		},
+		Trim: trim{
+			Raw: *trimDefaults(),
+		},
+		Sharpen: sharpen{
+			Raw: *sharpenDefaults(),
+		},
+		Colourspace: colourspace{
+			Raw: *colourspaceDefaults(),
+		},
+		Flatten: flatten{
+			Raw: *flattenDefaults(),
+		},
+		Overlay: overlay{
+			Raw: *overlayDefaults(),
+		},
+		Gamma: gamma{
+			Raw: *gammaDefaults(),
+		},
+		Linear: linear{
+			Raw: *linearDefaults(),
+		},
+	}
+}
+
 // resizeDefaults return default configuration for resize operation configuration
 func resizeDefaults() *resizeRawConfig {
 	return &resizeRawConfig{
 		Width:    "",
 		Height:   "",
 		Strategy: "embed",
+		Anchor:   "center",
 	}
 }
 
@@ -96,6 +143,83 @@ func cropDefaults() *cropRawConfig {
 	}
 }
 
+// extractDefaults return default configuration for extract operation configuration
+func extractDefaults() *extractRawConfig {
+	return &extractRawConfig{
+		Top:    "",
+		Left:   "",
+		Width:  "",
+		Height: "",
+	}
+}
+
+// trimDefaults return default configuration for trim operation configuration
+func trimDefaults() *trimRawConfig {
+	return &trimRawConfig{
+		Threshold: "",
+		Background: rgb{
+			R: "255",
+			G: "255",
+			B: "255",
+		},
+	}
+}
+
+// sharpenDefaults return default configuration for sharpen operation configuration
+func sharpenDefaults() *sharpenRawConfig {
+	return &sharpenRawConfig{
+		Sigma:  "",
+		Flat:   "0",
+		Jagged: "3",
+	}
+}
+
+// colourspaceDefaults return default configuration for colourspace operation configuration
+func colourspaceDefaults() *colourspaceRawConfig {
+	return &colourspaceRawConfig{
+		Space: "",
+	}
+}
+
+// flattenDefaults return default configuration for flatten operation configuration
+func flattenDefaults() *flattenRawConfig {
+	return &flattenRawConfig{
+		Background: rgb{
+			R: "",
+			G: "",
+			B: "",
+		},
+	}
+}
+
+// overlayDefaults return default configuration for overlay operation configuration
+func overlayDefaults() *overlayRawConfig {
+	return &overlayRawConfig{
+		File:    "",
+		Gravity: "south-east",
+		OffsetX: "0",
+		OffsetY: "0",
+		Scale:   "",
+		Opacity: "1",
+		Tile:    "false",
+	}
+}
+
+// gammaDefaults return default configuration for gamma operation configuration
+func gammaDefaults() *gammaRawConfig {
+	return &gammaRawConfig{
+		Exponent: "",
+	}
+}
+
+// linearDefaults return default configuration for linear operation configuration
+func linearDefaults() *linearRawConfig {
+	return &linearRawConfig{
+		Brightness: "0",
+		Contrast:   "1",
+	}
+}
+
 // exportDefaults return default exporting configuration for VIPS plugin
 func exportDefaults() *exportRawConfig {
 	return &exportRawConfig{
@@ -104,6 +228,10 @@ func exportDefaults() *exportRawConfig {
 		Quality:       85,
 		Compression:   6,
 		StripMetadata: true,
+		Png:           pngExport{Colors: 256},
+		Gif:           gifExport{Colors: 256},
+		Accept:        "@{_accept}",
+		Fallback:      "jpeg",
 	}
 }
 
@@ -53,12 +53,12 @@ func (o *crop) Apply(p *bimg.Options, data payload.Data) error {
 
 	width, err := o.width.EvaluateInt64(data)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	height, err := o.height.EvaluateInt64(data)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	// // // // // // //
@@ -69,25 +69,50 @@ func (o *crop) Apply(p *bimg.Options, data payload.Data) error {
 
 	anchor, err := o.anchor.Evaluate(data)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	p.Gravity, err = gravity(anchor)
+	return err
+}
+
+// Focal return the crop size and anchor, used to crop around a focal point.
+func (o *crop) Focal(data payload.Data) (int, int, string, error) {
+	width, err := o.width.EvaluateInt64(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	height, err := o.height.EvaluateInt64(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	anchor, err := o.anchor.Evaluate(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	return int(width), int(height), anchor, nil
+}
+
+// gravity return the bimg gravity of an anchor, focal point anchors are cropped to the right aspect ratio around the
+// focal point before the stage, so what's left is centered.
+func gravity(anchor string) (bimg.Gravity, error) {
 	switch anchor {
-	case "center":
-		p.Gravity = bimg.GravityCentre
+	case "center", "focal", "face", "entropy":
+		return bimg.GravityCentre, nil
 	case "north":
-		p.Gravity = bimg.GravityNorth
+		return bimg.GravityNorth, nil
 	case "east":
-		p.Gravity = bimg.GravityEast
+		return bimg.GravityEast, nil
 	case "south":
-		p.Gravity = bimg.GravitySouth
+		return bimg.GravitySouth, nil
 	case "west":
-		p.Gravity = bimg.GravityWest
+		return bimg.GravityWest, nil
 	case "smart":
-		p.Gravity = bimg.GravitySmart
+		return bimg.GravitySmart, nil
 	default:
-		return fmt.Errorf("invalid value for field [anchor], got: %s", anchor)
+		return 0, fmt.Errorf("invalid value for field [anchor], got: %s", anchor)
 	}
-
-	return err
 }
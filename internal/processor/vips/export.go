@@ -1,42 +1,160 @@
 package vips
 
 import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
 	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/metadata"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 )
 
 type export struct {
-	Raw exportRawConfig `mapstructure:",squash"`
+	Raw    exportRawConfig `mapstructure:",squash"`
+	accept cfg.Selector
+	strip  metadata.Strip
+	// profile is the description of the profile images are converted to
+	profile string
 }
 
+// exportRawConfig formats are the ones libvips saves through the bimg bindings, GIF and palette PNG are quantized in
+// Go. The bindings have no AVIF and HEIF savers nor a webp effort option, they're rejected by Init.
 type exportRawConfig struct {
-	Format        string `validate:"oneof=jpg jpeg png webp"`
+	Format        string `validate:"oneof=jpg jpeg png webp tiff gif avif heif auto"`
 	Extend        string `validate:"oneof=black copy repeat mirror white last"`
 	Quality       int    `validate:"min=1,max=100"`
 	Compression   int    `validate:"min=1,max=9"`
 	StripMetadata bool   `mapstructure:"strip_metadata"`
 	Interlace     bool
+	Webp          webpExport
+	Png           pngExport
+	Gif           gifExport
+
+	// Strip selectively removes metadata and keeps the rest, overrides StripMetadata when set.
+	Strip []string `validate:"dive,oneof=exif gps iptc xmp icc"`
+
+	ICC iccExport `mapstructure:"icc"`
+
+	// used by auto format, picks a format accepted by the client (e.g HTTP Accept header) or fallback to Fallback.
+	Accept   string
+	Fallback string `validate:"oneof=jpg jpeg png webp tiff gif"`
+}
+
+type webpExport struct {
+	Lossless bool
+	Effort   int `validate:"min=0,max=6"`
+}
+
+type pngExport struct {
+	// Palette saves PNGs with a palette of at most Colors colours
+	Palette bool
+	Colors  int `validate:"min=2,max=256"`
+}
+
+type gifExport struct {
+	Colors int `validate:"min=2,max=256"`
+}
+
+type iccExport struct {
+	// Convert images with an embedded profile to "srgb" or to the profile in the given file path.
+	Convert string
+	// Profile is whether to keep or drop the profile on export, empty follows StripMetadata.
+	Profile string `validate:"omitempty,oneof=keep drop"`
 }
 
 func (o *export) Init() (bool, error) {
-	//no need to validate, all validation are done by tags. and no need to create selectors
+	var err error
+
+	switch {
+	case o.Raw.Format == "avif" || o.Raw.Format == "heif":
+		return false, fmt.Errorf("%s export is not supported, libvips bindings have no %s saver", o.Raw.Format, o.Raw.Format)
+	case o.Raw.Webp.Effort != 0:
+		return false, fmt.Errorf("webp effort is not supported, libvips bindings don't expose it")
+	}
+
+	o.accept, err = cfg.NewSelector(o.Raw.Accept)
+	if err != nil {
+		return false, err
+	}
+
+	for _, value := range o.Raw.Strip {
+		switch value {
+		case "exif":
+			o.strip.EXIF = true
+		case "gps":
+			o.strip.GPS = true
+		case "iptc":
+			o.strip.IPTC = true
+		case "xmp":
+			o.strip.XMP = true
+		case "icc":
+			o.strip.ICC = true
+		}
+	}
+
+	switch o.Raw.ICC.Profile {
+	case "keep", "drop":
+		// strip_metadata still strips everything else
+		if o.Raw.StripMetadata && !o.selective() {
+			o.strip = metadata.Strip{EXIF: true, IPTC: true, XMP: true}
+		}
+		o.strip.ICC = o.Raw.ICC.Profile == "drop"
+	}
+
+	switch o.Raw.ICC.Convert {
+	case "":
+	case "srgb":
+		o.profile = "sRGB"
+	default:
+		profile, err := ioutil.ReadFile(o.Raw.ICC.Convert)
+		if err != nil {
+			return false, fmt.Errorf("failed to read icc profile: %s", err.Error())
+		}
+
+		o.profile = metadata.ProfileDescription(profile)
+		if o.profile == "" {
+			return false, fmt.Errorf("invalid icc profile [%s]", o.Raw.ICC.Convert)
+		}
+	}
+
 	return true, nil
 }
 
-func (o *export) Apply(p *bimg.Options, data payload.Data) error {
+// Apply sets the export options, it returns the exported format as GIF and palette PNG are quantized after saving.
+func (o *export) Apply(p *bimg.Options, data payload.Data) (string, error) {
 
 	p.Quality = o.Raw.Quality
 	p.Compression = o.Raw.Compression
 	p.StripMetadata = o.Raw.StripMetadata
 	p.Interlace = o.Raw.Interlace
 
-	switch o.Raw.Format {
+	// libvips transforms using the embedded profile, "srgb" is its built-in profile.
+	p.OutputICC = o.Raw.ICC.Convert
+
+	// selected metadata is removed after saving, except a profile that's not needed for conversion
+	if o.selective() {
+		p.StripMetadata = false
+		p.NoProfile = o.strip.ICC && o.Raw.ICC.Convert == ""
+	}
+
+	format := o.Raw.Format
+	if format == "auto" {
+		format = o.negotiate(data)
+	}
+
+	switch format {
 	case "jpeg", "jpg":
 		p.Type = bimg.JPEG
-	case "png":
+	case "png", "gif":
 		p.Type = bimg.PNG
 	case "webp":
 		p.Type = bimg.WEBP
+		p.Lossless = o.Raw.Webp.Lossless
+	case "tiff":
+		p.Type = bimg.TIFF
 	}
 
 	switch o.Raw.Extend {
@@ -54,5 +172,102 @@ func (o *export) Apply(p *bimg.Options, data payload.Data) error {
 		p.Extend = bimg.ExtendLast
 	}
 
-	return nil
+	return format, nil
+}
+
+// quantize return the palette size the exported format is quantized to, zero if it's saved as is.
+func (o *export) quantize(format string) int {
+	switch {
+	case format == "gif":
+		return o.Raw.Gif.Colors
+	case format == "png" && o.Raw.Png.Palette:
+		return o.Raw.Png.Colors
+	default:
+		return 0
+	}
+}
+
+// selective return true if only some metadata is stripped
+func (o *export) selective() bool {
+	return o.strip != metadata.Strip{}
+}
+
+// profileOf return the profile description of the exported image, empty if it has no profile.
+func (o *export) profileOf(img *image) string {
+	if img.profile == "" {
+		return ""
+	}
+
+	if o.Raw.ICC.Convert != "" {
+		return o.profile
+	}
+
+	if img.options.StripMetadata || img.options.NoProfile || o.strip.ICC {
+		return ""
+	}
+
+	return img.profile
+}
+
+// negotiate picks webp if the client accepts it at least as much as the fallback format, following Accept header
+// quality values. a missing accept field falls back too.
+func (o *export) negotiate(data payload.Data) string {
+	accept, err := o.accept.Evaluate(data)
+	if err != nil {
+		return o.Raw.Fallback
+	}
+
+	fallback := o.Raw.Fallback
+	if fallback == "jpg" {
+		fallback = "jpeg"
+	}
+
+	webp, explicit := acceptQuality(accept, "image/webp")
+	if explicit && webp > 0 && fallback != "webp" {
+		if q, _ := acceptQuality(accept, "image/"+fallback); webp >= q {
+			return "webp"
+		}
+	}
+
+	return o.Raw.Fallback
+}
+
+// acceptQuality return the quality value of the most specific media range of an Accept header matching mediaType,
+// explicit is true if the media type itself is listed.
+func acceptQuality(accept, mediaType string) (float64, bool) {
+	typ := mediaType[:strings.Index(mediaType, "/")]
+
+	quality, specificity := 0.0, -1
+	for _, item := range strings.Split(accept, ",") {
+		params := strings.Split(item, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+
+		match := -1
+		switch mediaRange {
+		case mediaType:
+			match = 2
+		case typ + "/*":
+			match = 1
+		case "*/*":
+			match = 0
+		}
+		if match <= specificity {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					q = value
+				}
+			}
+		}
+
+		quality, specificity = q, match
+	}
+
+	return quality, specificity == 2
 }
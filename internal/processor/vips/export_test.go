@@ -0,0 +1,100 @@
+package vips
+
+import (
+	"bytes"
+	goimage "image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   interface{}
+		fallback string
+		want     string
+	}{
+		{name: "browser", accept: "image/avif,image/webp,image/apng,image/*,*/*;q=0.8", fallback: "jpeg", want: "webp"},
+		{name: "refused", accept: "image/webp;q=0, image/*", fallback: "jpeg", want: "jpeg"},
+		{name: "preferred fallback", accept: "image/webp;q=0.5, image/jpeg", fallback: "jpg", want: "jpg"},
+		{name: "preferred webp", accept: "image/webp;q=0.9, image/*;q=0.5", fallback: "png", want: "webp"},
+		{name: "wildcard only", accept: "*/*", fallback: "jpeg", want: "jpeg"},
+		{name: "case and spaces", accept: " Image/WebP ; q=1 ", fallback: "jpeg", want: "webp"},
+		{name: "missing", fallback: "png", want: "png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &export{Raw: exportRawConfig{Accept: "@{_accept}", Fallback: tt.fallback}}
+			o.accept, _ = cfg.NewSelector(o.Raw.Accept)
+
+			data := payload.Data{}
+			if tt.accept != nil {
+				data["_accept"] = tt.accept
+			}
+			if got := o.negotiate(data); got != tt.want {
+				t.Errorf("negotiate(%v) = %s, want %s", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaletted(t *testing.T) {
+	img := goimage.NewNRGBA(goimage.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			switch {
+			case x < 4:
+				img.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+			case y < 4:
+				img.SetNRGBA(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, img)
+
+	tests := []struct {
+		format string
+		decode func(b []byte) (goimage.Image, error)
+	}{
+		{format: "png", decode: func(b []byte) (goimage.Image, error) { return png.Decode(bytes.NewReader(b)) }},
+		{format: "gif", decode: func(b []byte) (goimage.Image, error) { return gif.Decode(bytes.NewReader(b)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := paletted(buf.Bytes(), tt.format, 16, 6)
+			if err != nil {
+				t.Fatalf("paletted() error = %v", err)
+			}
+
+			decoded, err := tt.decode(out)
+			if err != nil {
+				t.Fatalf("decode error = %v", err)
+			}
+			p, ok := decoded.(*goimage.Paletted)
+			if !ok {
+				t.Fatalf("paletted() decoded to %T, want a palette image", decoded)
+			}
+			// gif palettes are padded to a power of two
+			if len(p.Palette) < 3 || len(p.Palette) > 4 {
+				t.Errorf("palette = %v, want red, blue and transparent", p.Palette)
+			}
+
+			for _, pixel := range []struct {
+				x, y int
+				want color.NRGBA
+			}{{1, 1, color.NRGBA{R: 255, A: 255}}, {6, 1, color.NRGBA{B: 255, A: 255}}, {6, 6, color.NRGBA{}}} {
+				if got := color.NRGBAModel.Convert(p.At(pixel.x, pixel.y)); got != pixel.want {
+					t.Errorf("pixel (%d, %d) = %v, want %v", pixel.x, pixel.y, got, pixel.want)
+				}
+			}
+		})
+	}
+}
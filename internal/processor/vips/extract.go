@@ -0,0 +1,82 @@
+package vips
+
+import (
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type extract struct {
+	Raw    extractRawConfig `mapstructure:",squash"`
+	top    cfg.Selector
+	left   cfg.Selector
+	width  cfg.Selector
+	height cfg.Selector
+}
+
+type extractRawConfig struct {
+	Top    string
+	Left   string
+	Width  string
+	Height string
+}
+
+func (o *extract) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *extractDefaults() {
+		return false, nil
+	}
+
+	o.top, err = cfg.NewSelector(o.Raw.Top)
+	if err != nil {
+		return false, err
+	}
+
+	o.left, err = cfg.NewSelector(o.Raw.Left)
+	if err != nil {
+		return false, err
+	}
+
+	o.width, err = cfg.NewSelector(o.Raw.Width)
+	if err != nil {
+		return false, err
+	}
+
+	o.height, err = cfg.NewSelector(o.Raw.Height)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *extract) Apply(p *bimg.Options, data payload.Data) error {
+
+	top, err := o.top.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	left, err := o.left.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	width, err := o.width.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	height, err := o.height.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	p.Top = int(top)
+	p.Left = int(left)
+	p.AreaWidth = int(width)
+	p.AreaHeight = int(height)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package vips
+
+import (
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// flatten flattens transparent images onto a background color (e.g for PNG -> JPEG), bimg only flattens PNG images,
+// and doesn't flatten onto black as it's its default.
+type flatten struct {
+	Raw    flattenRawConfig `mapstructure:",squash"`
+	colorR cfg.Selector
+	colorG cfg.Selector
+	colorB cfg.Selector
+}
+
+type flattenRawConfig struct {
+	Background rgb
+}
+
+func (o *flatten) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *flattenDefaults() {
+		return false, nil
+	}
+
+	o.colorR, err = cfg.NewSelector(o.Raw.Background.R)
+	if err != nil {
+		return false, err
+	}
+	o.colorG, err = cfg.NewSelector(o.Raw.Background.G)
+	if err != nil {
+		return false, err
+	}
+	o.colorB, err = cfg.NewSelector(o.Raw.Background.B)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *flatten) Apply(p *bimg.Options, data payload.Data) error {
+	var err error
+
+	p.Background.R, err = o.colorR.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	p.Background.G, err = o.colorG.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	p.Background.B, err = o.colorB.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
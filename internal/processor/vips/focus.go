@@ -0,0 +1,121 @@
+package vips
+
+import (
+	"bytes"
+	goimage "image"
+	"image/jpeg"
+
+	"github.com/sherifabdlnaby/bimg"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// detection runs on a thumbnail no larger than this
+const detectionSize = 512
+
+// focus extracts the region around the focal point for operations of the stage using a focal point anchor, returns
+// true if the image is cropped.
+func (d *Vips) focus(img *bimg.VipsImage, stage *operations, data payload.Data) (bool, error) {
+	for _, op := range stage.focals {
+		width, height, anchor, err := op.Focal(data)
+		if err != nil {
+			return false, err
+		}
+
+		if width <= 0 || height <= 0 || (anchor != "focal" && anchor != "face" && anchor != "entropy") {
+			continue
+		}
+
+		// focal point supplied by client takes precedence
+		point, ok := focus.FromData(data)
+		switch {
+		case ok:
+		case anchor == "focal":
+			point = focus.Center
+		default:
+			point, err = d.detect(img, anchor)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		imageWidth, imageHeight := img.GetDimensions()
+		region := focus.Region(imageWidth, imageHeight, width, height, point)
+		if region.Dx() == imageWidth && region.Dy() == imageHeight {
+			return false, nil
+		}
+
+		err = img.Process(bimg.Options{
+			Left:         region.Min.X,
+			Top:          region.Min.Y,
+			AreaWidth:    region.Dx(),
+			AreaHeight:   region.Dy(),
+			NoAutoRotate: true,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// detect return the center of faces in the image, or the most detailed area if it has no faces or for entropy anchor.
+// faces are located by skin tone if the cascade doesn't detect any.
+func (d *Vips) detect(img *bimg.VipsImage, anchor string) (focus.Point, error) {
+	small, err := thumbnail(img)
+	if err != nil {
+		return focus.Point{}, err
+	}
+
+	gray := focus.Gray(small)
+
+	if anchor == "face" {
+		bounds := gray.Bounds()
+		point, ok := focus.Faces(d.config.cascade.Detect(gray), bounds.Dx(), bounds.Dy())
+		if ok {
+			return point, nil
+		}
+
+		point, ok = focus.Skin(small)
+		if ok {
+			return point, nil
+		}
+	}
+
+	return focus.Entropy(gray), nil
+}
+
+// thumbnail return a small copy of the image for detection
+func thumbnail(img *bimg.VipsImage) (goimage.Image, error) {
+	options := bimg.Options{
+		Type:           bimg.JPEG,
+		Quality:        90,
+		Interpretation: bimg.InterpretationSRGB,
+		NoAutoRotate:   true,
+		StripMetadata:  true,
+	}
+
+	width, height := img.GetDimensions()
+	switch {
+	case width >= height && width > detectionSize:
+		options.Width = detectionSize
+	case height > width && height > detectionSize:
+		options.Height = detectionSize
+	}
+
+	clone := img.Clone()
+	err := clone.Process(options)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer, err := clone.Save(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return jpeg.Decode(bytes.NewReader(buffer))
+}
@@ -1,7 +1,10 @@
 package vips
 
 import (
+	"fmt"
+
 	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 )
 
@@ -9,17 +12,84 @@ import (
 // to process the image
 type operations struct {
 	// Parsing
-	Resize resize
-	Flip   flip
-	Blur   blur
-	Rotate rotate
-	Crop   crop
-	Label  label
+	Resize      resize
+	Flip        flip
+	Blur        blur
+	Rotate      rotate
+	Crop        crop
+	Label       label
+	Extract     extract
+	Trim        trim
+	Sharpen     sharpen
+	Colourspace colourspace
+	Flatten     flatten
+	Overlay     overlay
+	Gamma       gamma
+	Linear      linear
 	//------------disabled-------------------
 	//Invert  invert	`mapstructure:",squash"`
 	//---------------------------------------
 	// for internal use
 	operations []operation
+	composites []composite
+	tones      []tone
+	focals     []focal
+}
+
+// newStages decode operations config into stages, a map of operations is a single stage applied in a fixed order,
+// and a list of operations is applied in order where every operation is in its own stage.
+func newStages(raw interface{}) ([]*operations, error) {
+	switch raw := raw.(type) {
+	case nil:
+		stage := operationsDefaults()
+		return []*operations{stage}, stage.Init()
+	case map[string]interface{}:
+		stage, err := newStage(raw)
+		if err != nil {
+			return nil, err
+		}
+		return []*operations{stage}, nil
+	case []interface{}:
+		stages := make([]*operations, 0, len(raw))
+		for i, item := range raw {
+			op, ok := item.(map[string]interface{})
+			if !ok || len(op) != 1 {
+				return nil, fmt.Errorf("operations[%d] must be a single operation", i)
+			}
+
+			stage, err := newStage(op)
+			if err != nil {
+				return nil, fmt.Errorf("operations[%d]: %s", i, err.Error())
+			}
+
+			if len(stage.operations) == 0 && len(stage.composites) == 0 && len(stage.tones) == 0 {
+				for name := range op {
+					return nil, fmt.Errorf("operations[%d]: unknown or empty operation [%s]", i, name)
+				}
+			}
+
+			stages = append(stages, stage)
+		}
+		return stages, nil
+	default:
+		return nil, fmt.Errorf("operations must be either a map or a list of operations")
+	}
+}
+
+func newStage(raw map[string]interface{}) (*operations, error) {
+	stage := operationsDefaults()
+
+	err := cfg.NewConfig(raw).Populate(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stage.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return stage, nil
 }
 
 // operation represent a single operation that is applied to a bimg.Option used to process the image
@@ -28,6 +98,19 @@ type operation interface {
 	Apply(p *bimg.Options, data payload.Data) error
 }
 
+// composite represent an operation that needs the dimensions of the processed image, so it's applied after other
+// operations of its stage.
+type composite interface {
+	Init() (bool, error)
+	Apply(p *bimg.Options, width, height int, data payload.Data) error
+}
+
+// focal represent an operation that crops to a size around an anchor, for focal point anchors the region around the
+// focal point is extracted before the stage.
+type focal interface {
+	Focal(data payload.Data) (width, height int, anchor string, err error)
+}
+
 // Init each operation and validate decoded config
 func (o *operations) Init() error {
 
@@ -38,6 +121,7 @@ func (o *operations) Init() error {
 	}
 	if ok {
 		o.operations = append(o.operations, &o.Resize)
+		o.focals = append(o.focals, &o.Resize)
 	}
 
 	// Init every operation and add them if they're active.
@@ -73,6 +157,7 @@ func (o *operations) Init() error {
 	}
 	if ok {
 		o.operations = append(o.operations, &o.Crop)
+		o.focals = append(o.focals, &o.Crop)
 	}
 
 	// Init every operation and add them if they're active.
@@ -84,6 +169,89 @@ func (o *operations) Init() error {
 		o.operations = append(o.operations, &o.Label)
 	}
 
+	ok, err = o.Extract.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.operations = append(o.operations, &o.Extract)
+	}
+
+	ok, err = o.Trim.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.operations = append(o.operations, &o.Trim)
+	}
+
+	ok, err = o.Sharpen.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.operations = append(o.operations, &o.Sharpen)
+	}
+
+	ok, err = o.Colourspace.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.operations = append(o.operations, &o.Colourspace)
+	}
+
+	// applied after trim, as both use the background color
+	ok, err = o.Flatten.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.operations = append(o.operations, &o.Flatten)
+	}
+
+	ok, err = o.Overlay.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.composites = append(o.composites, &o.Overlay)
+	}
+
+	// gamma is applied before brightness and contrast
+	ok, err = o.Gamma.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.tones = append(o.tones, &o.Gamma)
+	}
+
+	ok, err = o.Linear.Init()
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.tones = append(o.tones, &o.Linear)
+	}
+
+	return o.conflicts()
+}
+
+// conflicts return an error if operations of the stage set the same options, they must be in separate stages.
+func (o *operations) conflicts() error {
+	active := make(map[operation]bool, len(o.operations))
+	for _, op := range o.operations {
+		active[op] = true
+	}
+
+	switch {
+	case active[&o.Trim] && active[&o.Flatten]:
+		return fmt.Errorf("trim and flatten both set the background color, use them in separate stages")
+	case active[&o.Extract] && active[&o.Crop]:
+		return fmt.Errorf("extract and crop both set the extracted area, use them in separate stages")
+	}
+
 	return nil
 }
 
@@ -101,3 +269,31 @@ func (o *operations) Apply(params *bimg.Options, data payload.Data) error {
 
 	return nil
 }
+
+// Curves return the curves of tone operations
+func (o *operations) Curves(data payload.Data) ([]func(float64) float64, error) {
+	curves := make([]func(float64) float64, 0, len(o.tones))
+	for _, op := range o.tones {
+		curve, err := op.Curve(data)
+		if err != nil {
+			return nil, err
+		}
+		curves = append(curves, curve)
+	}
+
+	return curves, nil
+}
+
+// Composite applies composite operations to bimg.Options using dimensions of the processed image
+func (o *operations) Composite(params *bimg.Options, width, height int, data payload.Data) error {
+	var err error
+
+	for _, op := range o.composites {
+		err = op.Apply(params, width, height, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package vips
+
+import (
+	"bytes"
+	"fmt"
+	goimage "image"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"sync"
+
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"golang.org/x/image/draw"
+)
+
+// maximum number of scaled/tiled logos cached
+const overlayCacheSize = 64
+
+// overlay composites a PNG logo onto the image, it's applied after other operations of the same stage as it needs the
+// dimensions of the processed image.
+type overlay struct {
+	Raw     overlayRawConfig `mapstructure:",squash"`
+	gravity cfg.Selector
+	offsetX cfg.Selector
+	offsetY cfg.Selector
+	scale   cfg.Selector
+	opacity cfg.Selector
+	tile    cfg.Selector
+
+	// loaded once at Init
+	logo    *goimage.NRGBA
+	logoBuf []byte
+
+	// scaled/tiled logos keyed by their dimensions, rendered once outside the lock
+	cache   map[string]*overlayEntry
+	cacheMx sync.Mutex
+}
+
+// overlayEntry is a cached logo, rendered by the first job that needs it while others wait for it.
+type overlayEntry struct {
+	once sync.Once
+	buf  []byte
+	err  error
+}
+
+type overlayRawConfig struct {
+	File    string
+	Gravity string
+	OffsetX string `mapstructure:"offset_x"`
+	OffsetY string `mapstructure:"offset_y"`
+	Scale   string
+	Opacity string
+	Tile    string
+}
+
+func (o *overlay) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *overlayDefaults() {
+		return false, nil
+	}
+
+	if o.Raw.File == "" {
+		return false, fmt.Errorf("overlay field [file] is required")
+	}
+
+	o.logoBuf, err = ioutil.ReadFile(o.Raw.File)
+	if err != nil {
+		return false, err
+	}
+
+	logo, err := png.Decode(bytes.NewReader(o.logoBuf))
+	if err != nil {
+		return false, fmt.Errorf("overlay file [%s] must be a PNG image: %s", o.Raw.File, err.Error())
+	}
+
+	o.logo = goimage.NewNRGBA(logo.Bounds().Sub(logo.Bounds().Min))
+	draw.Draw(o.logo, o.logo.Bounds(), logo, logo.Bounds().Min, draw.Src)
+	o.cache = make(map[string]*overlayEntry)
+
+	o.gravity, err = cfg.NewSelector(o.Raw.Gravity)
+	if err != nil {
+		return false, err
+	}
+
+	o.offsetX, err = cfg.NewSelector(o.Raw.OffsetX)
+	if err != nil {
+		return false, err
+	}
+
+	o.offsetY, err = cfg.NewSelector(o.Raw.OffsetY)
+	if err != nil {
+		return false, err
+	}
+
+	o.scale, err = cfg.NewSelector(o.Raw.Scale)
+	if err != nil {
+		return false, err
+	}
+
+	o.opacity, err = cfg.NewSelector(o.Raw.Opacity)
+	if err != nil {
+		return false, err
+	}
+
+	o.tile, err = cfg.NewSelector(o.Raw.Tile)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *overlay) Apply(p *bimg.Options, width, height int, data payload.Data) error {
+
+	gravity, err := o.gravity.Evaluate(data)
+	if err != nil {
+		return err
+	}
+
+	offsetX, err := o.offsetX.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	offsetY, err := o.offsetY.EvaluateInt64(data)
+	if err != nil {
+		return err
+	}
+
+	scale, err := o.scale.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	opacity, err := o.opacity.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	tile, err := o.tile.EvaluateBool(data)
+	if err != nil {
+		return err
+	}
+
+	// --------------------------------------------------------------------
+
+	logoWidth, logoHeight := o.logo.Bounds().Dx(), o.logo.Bounds().Dy()
+	if scale > 0 {
+		logoWidth = int(math.Max(1, math.Round(float64(width)*scale)))
+		logoHeight = int(math.Max(1, math.Round(float64(o.logo.Bounds().Dy())*float64(logoWidth)/float64(o.logo.Bounds().Dx()))))
+	}
+
+	// logos larger than the image are scaled down to fit it
+	if fit := math.Min(float64(width)/float64(logoWidth), float64(height)/float64(logoHeight)); fit < 1 {
+		logoWidth = int(math.Max(1, math.Floor(float64(logoWidth)*fit)))
+		logoHeight = int(math.Max(1, math.Floor(float64(logoHeight)*fit)))
+	}
+
+	watermark := bimg.WatermarkImage{
+		Opacity: float32(opacity),
+	}
+
+	if tile {
+		watermark.Buf, err = o.tiled(logoWidth, logoHeight, width, height)
+		if err != nil {
+			return err
+		}
+
+		// the tiled sheet starts a tile before the image, and libvips clips what's outside of it
+		watermark.Left = mod(int(offsetX), logoWidth) - logoWidth
+		watermark.Top = mod(int(offsetY), logoHeight) - logoHeight
+		p.WatermarkImage = watermark
+		return nil
+	}
+
+	watermark.Buf, err = o.scaled(logoWidth, logoHeight)
+	if err != nil {
+		return err
+	}
+
+	x, y := int(offsetX), int(offsetY)
+	switch gravity {
+	case "center":
+		watermark.Left, watermark.Top = (width-logoWidth)/2+x, (height-logoHeight)/2+y
+	case "north":
+		watermark.Left, watermark.Top = (width-logoWidth)/2+x, y
+	case "south":
+		watermark.Left, watermark.Top = (width-logoWidth)/2+x, height-logoHeight-y
+	case "east":
+		watermark.Left, watermark.Top = width-logoWidth-x, (height-logoHeight)/2+y
+	case "west":
+		watermark.Left, watermark.Top = x, (height-logoHeight)/2+y
+	case "north-east":
+		watermark.Left, watermark.Top = width-logoWidth-x, y
+	case "north-west":
+		watermark.Left, watermark.Top = x, y
+	case "south-east":
+		watermark.Left, watermark.Top = width-logoWidth-x, height-logoHeight-y
+	case "south-west":
+		watermark.Left, watermark.Top = x, height-logoHeight-y
+	default:
+		return fmt.Errorf("invalid value for field [gravity], got: %s", gravity)
+	}
+
+	watermark.Left = int(math.Max(0, float64(watermark.Left)))
+	watermark.Top = int(math.Max(0, float64(watermark.Top)))
+	p.WatermarkImage = watermark
+
+	return nil
+}
+
+// scaled returns the logo scaled to width x height encoded as PNG.
+func (o *overlay) scaled(width, height int) ([]byte, error) {
+	if width == o.logo.Bounds().Dx() && height == o.logo.Bounds().Dy() {
+		return o.logoBuf, nil
+	}
+
+	return o.cached(fmt.Sprintf("%dx%d", width, height), func() goimage.Image {
+		return o.resized(width, height)
+	})
+}
+
+// tiled returns the logo repeated over a sheet that covers canvasWidth x canvasHeight shifted by up to a tile, encoded
+// as PNG. the count of tiles is rounded up so sheets are reused by images of close sizes.
+func (o *overlay) tiled(width, height, canvasWidth, canvasHeight int) ([]byte, error) {
+	columns := roundUp(canvasWidth/width + 2)
+	rows := roundUp(canvasHeight/height + 2)
+
+	return o.cached(fmt.Sprintf("%dx%d-%dx%d", width, height, columns, rows), func() goimage.Image {
+		logo := o.resized(width, height)
+		sheet := goimage.NewNRGBA(goimage.Rect(0, 0, columns*width, rows*height))
+		for top := 0; top < sheet.Rect.Dy(); top += height {
+			for left := 0; left < sheet.Rect.Dx(); left += width {
+				draw.Draw(sheet, goimage.Rect(left, top, left+width, top+height), logo, goimage.Point{}, draw.Src)
+			}
+		}
+		return sheet
+	})
+}
+
+func (o *overlay) resized(width, height int) goimage.Image {
+	if width == o.logo.Bounds().Dx() && height == o.logo.Bounds().Dy() {
+		return o.logo
+	}
+
+	logo := goimage.NewNRGBA(goimage.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(logo, logo.Bounds(), o.logo, o.logo.Bounds(), draw.Src, nil)
+	return logo
+}
+
+func (o *overlay) cached(key string, render func() goimage.Image) ([]byte, error) {
+	o.cacheMx.Lock()
+	entry, ok := o.cache[key]
+	if !ok {
+		// keep memory bounded, sizes usually repeat so dropping everything once is fine
+		if len(o.cache) >= overlayCacheSize {
+			o.cache = make(map[string]*overlayEntry)
+		}
+		entry = &overlayEntry{}
+		o.cache[key] = entry
+	}
+	o.cacheMx.Unlock()
+
+	entry.once.Do(func() {
+		var buf bytes.Buffer
+		encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+		entry.err = encoder.Encode(&buf, render())
+		entry.buf = buf.Bytes()
+	})
+
+	return entry.buf, entry.err
+}
+
+// roundUp rounds n up keeping its 3 most significant bits, so it's at most 25% larger.
+func roundUp(n int) int {
+	step := 1
+	for n/step >= 8 {
+		step <<= 1
+	}
+	return (n + step - 1) / step * step
+}
+
+// mod return the non negative remainder of a / b
+func mod(a, b int) int {
+	return (a%b + b) % b
+}
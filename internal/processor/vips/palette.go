@@ -0,0 +1,76 @@
+package vips
+
+import (
+	"bytes"
+	goimage "image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+
+	"github.com/sherifabdlnaby/prism/pkg/colors"
+	"github.com/sherifabdlnaby/prism/pkg/metadata"
+)
+
+// paletted re-encodes a PNG saved by libvips as a palette PNG or a GIF of at most n colours, as the bindings can't
+// save either. Metadata of palette PNGs is kept, GIFs have none.
+func paletted(buf []byte, format string, n, compression int) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	transparent := !opaque(img)
+	if transparent {
+		n--
+	}
+
+	palette := make(color.Palette, 0, n+1)
+	for _, c := range colors.Palette(img, n) {
+		palette = append(palette, c)
+	}
+	if transparent {
+		palette = append(palette, color.Transparent)
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.Black)
+	}
+
+	dst := goimage.NewPaletted(bounds, palette)
+	draw.FloydSteinberg.Draw(dst, bounds, img, bounds.Min)
+
+	out := &bytes.Buffer{}
+	if format == "gif" {
+		err = gif.Encode(out, dst, &gif.Options{NumColors: len(palette)})
+		return out.Bytes(), err
+	}
+
+	encoder := png.Encoder{CompressionLevel: pngCompression(compression)}
+	err = encoder.Encode(out, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.CopyPNG(out.Bytes(), buf)
+}
+
+// opaque return true if the image has no transparent pixels
+func opaque(img goimage.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+// pngCompression maps zlib compression levels (1-9) of libvips to the levels of the Go encoder
+func pngCompression(level int) png.CompressionLevel {
+	switch {
+	case level <= 3:
+		return png.BestSpeed
+	case level >= 8:
+		return png.BestCompression
+	default:
+		return png.DefaultCompression
+	}
+}
@@ -17,6 +17,7 @@ type resize struct {
 	minHeight cfg.Selector
 	minWidth  cfg.Selector
 	strategy  cfg.Selector
+	anchor    cfg.Selector
 }
 
 type resizeRawConfig struct {
@@ -27,6 +28,7 @@ type resizeRawConfig struct {
 	MinHeight string `mapstructure:"min_height"`
 	MinWidth  string `mapstructure:"min_width"`
 	Strategy  string
+	Anchor    string
 }
 
 func (o *resize) Init() (bool, error) {
@@ -71,6 +73,11 @@ func (o *resize) Init() (bool, error) {
 		return false, err
 	}
 
+	o.anchor, err = cfg.NewSelector(o.Raw.Anchor)
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
@@ -132,6 +139,16 @@ func (o *resize) Apply(p *bimg.Options, data payload.Data) error {
 		if p.Width > 0 && p.Height > 0 {
 			p.Embed = true
 			p.Crop = true
+
+			anchor, err := o.anchor.Evaluate(data)
+			if err != nil {
+				return err
+			}
+
+			p.Gravity, err = gravity(anchor)
+			if err != nil {
+				return err
+			}
 			break
 		}
 		p.Force = true
@@ -143,3 +160,28 @@ func (o *resize) Apply(p *bimg.Options, data payload.Data) error {
 
 	return err
 }
+
+// Focal return the resize size and anchor if the image is cropped, used to crop around a focal point.
+func (o *resize) Focal(data payload.Data) (int, int, string, error) {
+	strategy, err := o.strategy.Evaluate(data)
+	if err != nil || strategy != "crop" {
+		return 0, 0, "", err
+	}
+
+	width, err := o.width.EvaluateInt64(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	height, err := o.height.EvaluateInt64(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	anchor, err := o.anchor.Evaluate(data)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	return int(width), int(height), anchor, nil
+}
@@ -0,0 +1,77 @@
+package vips
+
+import (
+	"math"
+
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type sharpen struct {
+	Raw    sharpenRawConfig `mapstructure:",squash"`
+	sigma  cfg.Selector
+	flat   cfg.Selector
+	jagged cfg.Selector
+}
+
+type sharpenRawConfig struct {
+	Sigma  string
+	Flat   string
+	Jagged string
+}
+
+func (o *sharpen) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *sharpenDefaults() {
+		return false, nil
+	}
+
+	o.sigma, err = cfg.NewSelector(o.Raw.Sigma)
+	if err != nil {
+		return false, err
+	}
+
+	o.flat, err = cfg.NewSelector(o.Raw.Flat)
+	if err != nil {
+		return false, err
+	}
+
+	o.jagged, err = cfg.NewSelector(o.Raw.Jagged)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *sharpen) Apply(p *bimg.Options, data payload.Data) error {
+
+	sigma, err := o.sigma.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	flat, err := o.flat.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	jagged, err := o.jagged.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	// bimg only exposes the deprecated radius param of vips_sharpen, where sigma = 1 + radius / 2
+	p.Sharpen = bimg.Sharpen{
+		Radius: int(math.Max(1, math.Round((sigma-1)*2))),
+		X1:     2,
+		Y2:     10,
+		Y3:     20,
+		M1:     flat,
+		M2:     jagged,
+	}
+
+	return nil
+}
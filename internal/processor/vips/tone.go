@@ -0,0 +1,222 @@
+package vips
+
+import (
+	"bytes"
+	"fmt"
+	goimage "image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// tone represent an operation that maps pixel values (0 to 1) through a curve, bimg has no bindings for them so
+// they're applied in Go after other operations of its stage.
+type tone interface {
+	Init() (bool, error)
+	Curve(data payload.Data) (func(float64) float64, error)
+}
+
+// gamma raises pixel values to the power of 1/exponent, exponents above 1 brighten mid tones and below 1 darken them.
+type gamma struct {
+	Raw      gammaRawConfig `mapstructure:",squash"`
+	exponent cfg.Selector
+}
+
+type gammaRawConfig struct {
+	Exponent string
+}
+
+func (o *gamma) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *gammaDefaults() {
+		return false, nil
+	}
+
+	o.exponent, err = cfg.NewSelector(o.Raw.Exponent)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *gamma) Curve(data payload.Data) (func(float64) float64, error) {
+	exponent, err := o.exponent.EvaluateFloat64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if exponent <= 0 {
+		return nil, fmt.Errorf("gamma exponent must be positive, got %v", exponent)
+	}
+
+	return func(value float64) float64 {
+		return math.Pow(value, 1/exponent)
+	}, nil
+}
+
+// linear adjusts brightness and contrast, contrast scales values around mid gray, then brightness is added.
+type linear struct {
+	Raw        linearRawConfig `mapstructure:",squash"`
+	brightness cfg.Selector
+	contrast   cfg.Selector
+}
+
+type linearRawConfig struct {
+	Brightness string
+	Contrast   string
+}
+
+func (o *linear) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *linearDefaults() {
+		return false, nil
+	}
+
+	o.brightness, err = cfg.NewSelector(o.Raw.Brightness)
+	if err != nil {
+		return false, err
+	}
+
+	o.contrast, err = cfg.NewSelector(o.Raw.Contrast)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *linear) Curve(data payload.Data) (func(float64) float64, error) {
+	brightness, err := o.brightness.EvaluateFloat64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	contrast, err := o.contrast.EvaluateFloat64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if contrast < 0 {
+		return nil, fmt.Errorf("contrast must not be negative, got %v", contrast)
+	}
+
+	return func(value float64) float64 {
+		return (value-0.5)*contrast + 0.5 + brightness
+	}, nil
+}
+
+// adjust maps pixel values of img through curves, img is round tripped losslessly through PNG.
+func adjust(img *bimg.VipsImage, options bimg.Options, curves []func(float64) float64) (*bimg.VipsImage, error) {
+	options.Type = bimg.PNG
+	options.Compression = 1
+	options.StripMetadata = false
+
+	buffer, err := img.Save(options)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(buffer))
+	if err != nil {
+		return nil, err
+	}
+
+	curve := func(value float64) float64 {
+		for _, c := range curves {
+			value = clamp(c(value))
+		}
+		return value
+	}
+
+	output := bytes.NewBuffer(make([]byte, 0, len(buffer)))
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	err = encoder.Encode(output, applyCurve(decoded, curve))
+	if err != nil {
+		return nil, err
+	}
+
+	return bimg.NewVipsImage(output.Bytes())
+}
+
+// applyCurve maps color channels of img through curve in place, keeping its depth and alpha.
+func applyCurve(img goimage.Image, curve func(float64) float64) goimage.Image {
+	switch img := img.(type) {
+	case *goimage.Gray:
+		lut := lut8(curve)
+		for i, value := range img.Pix {
+			img.Pix[i] = lut[value]
+		}
+	case *goimage.RGBA:
+		// png only decodes opaque images as RGBA, so values aren't premultiplied
+		applyLUT8(img.Pix, 4, lut8(curve))
+	case *goimage.NRGBA:
+		applyLUT8(img.Pix, 4, lut8(curve))
+	case *goimage.Paletted:
+		lut := lut8(curve)
+		for i, c := range img.Palette {
+			n := color.NRGBAModel.Convert(c).(color.NRGBA)
+			img.Palette[i] = color.NRGBA{R: lut[n.R], G: lut[n.G], B: lut[n.B], A: n.A}
+		}
+	case *goimage.Gray16:
+		applyLUT16(img.Pix, 1, lut16(curve))
+	case *goimage.RGBA64:
+		applyLUT16(img.Pix, 4, lut16(curve))
+	case *goimage.NRGBA64:
+		applyLUT16(img.Pix, 4, lut16(curve))
+	default:
+		converted := goimage.NewNRGBA(img.Bounds())
+		draw.Draw(converted, converted.Bounds(), img, img.Bounds().Min, draw.Src)
+		return applyCurve(converted, curve)
+	}
+
+	return img
+}
+
+// applyLUT8 maps 8 bit pixels of channels count through lut, alpha (the 4th channel) is kept.
+func applyLUT8(pix []uint8, channels int, lut [256]uint8) {
+	for i := range pix {
+		if channels == 4 && i%4 == 3 {
+			continue
+		}
+		pix[i] = lut[pix[i]]
+	}
+}
+
+// applyLUT16 maps big endian 16 bit pixels of channels count through lut, alpha (the 4th channel) is kept.
+func applyLUT16(pix []uint8, channels int, lut []uint16) {
+	for i := 0; i+1 < len(pix); i += 2 {
+		if channels == 4 && (i/2)%4 == 3 {
+			continue
+		}
+		value := lut[uint16(pix[i])<<8|uint16(pix[i+1])]
+		pix[i], pix[i+1] = uint8(value>>8), uint8(value)
+	}
+}
+
+func lut8(curve func(float64) float64) [256]uint8 {
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = uint8(math.Round(clamp(curve(float64(i)/0xff)) * 0xff))
+	}
+	return lut
+}
+
+func lut16(curve func(float64) float64) []uint16 {
+	lut := make([]uint16, 0x10000)
+	for i := range lut {
+		lut[i] = uint16(math.Round(clamp(curve(float64(i)/0xffff)) * 0xffff))
+	}
+	return lut
+}
+
+func clamp(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,77 @@
+package vips
+
+import (
+	"github.com/sherifabdlnaby/bimg"
+	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+type trim struct {
+	Raw       trimRawConfig `mapstructure:",squash"`
+	threshold cfg.Selector
+	colorR    cfg.Selector
+	colorG    cfg.Selector
+	colorB    cfg.Selector
+}
+
+type trimRawConfig struct {
+	Threshold  string
+	Background rgb
+}
+
+func (o *trim) Init() (bool, error) {
+	var err error
+
+	if o.Raw == *trimDefaults() {
+		return false, nil
+	}
+
+	o.threshold, err = cfg.NewSelector(o.Raw.Threshold)
+	if err != nil {
+		return false, err
+	}
+
+	o.colorR, err = cfg.NewSelector(o.Raw.Background.R)
+	if err != nil {
+		return false, err
+	}
+	o.colorG, err = cfg.NewSelector(o.Raw.Background.G)
+	if err != nil {
+		return false, err
+	}
+	o.colorB, err = cfg.NewSelector(o.Raw.Background.B)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o *trim) Apply(p *bimg.Options, data payload.Data) error {
+
+	threshold, err := o.threshold.EvaluateFloat64(data)
+	if err != nil {
+		return err
+	}
+
+	// border color to trim
+	p.Background.R, err = o.colorR.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	p.Background.G, err = o.colorG.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	p.Background.B, err = o.colorB.EvaluateUint8(data)
+	if err != nil {
+		return err
+	}
+
+	p.Trim = true
+	p.Threshold = threshold
+
+	return nil
+}
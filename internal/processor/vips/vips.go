@@ -1,19 +1,21 @@
 package vips
 
 import (
+	"fmt"
 	"io/ioutil"
 	"runtime"
 
 	"github.com/sherifabdlnaby/bimg"
+	"github.com/sherifabdlnaby/prism/pkg/animation"
 	"github.com/sherifabdlnaby/prism/pkg/component"
 	cfg "github.com/sherifabdlnaby/prism/pkg/config"
+	"github.com/sherifabdlnaby/prism/pkg/focus"
+	"github.com/sherifabdlnaby/prism/pkg/metadata"
 	"github.com/sherifabdlnaby/prism/pkg/payload"
 	"github.com/sherifabdlnaby/prism/pkg/response"
 	"go.uber.org/zap"
 )
 
-// TODO: EXTEND BIMG TO DO FACE AND ENTROPY smart crop
-
 // Vips a processing plugin that use libvips C library to do multiple operations
 type Vips struct {
 	logger zap.SugaredLogger
@@ -26,8 +28,16 @@ func NewComponent() component.Base {
 }
 
 type image struct {
-	image   *bimg.VipsImage
-	options bimg.Options
+	image     *bimg.VipsImage
+	imageType bimg.ImageType
+	options   bimg.Options
+
+	// orientation is EXIF orientation to apply, rotated is set once applied.
+	orientation int
+	rotated     bool
+
+	// profile is the description of the embedded ICC profile
+	profile string
 }
 
 //Init Initialize Plugin based on parsed operations
@@ -41,7 +51,7 @@ func (d *Vips) Init(config cfg.Config, logger zap.SugaredLogger) error {
 	}
 
 	// init operations
-	err = d.config.Operations.Init()
+	d.config.stages, err = newStages(d.config.Operations)
 	if err != nil {
 		return err
 	}
@@ -52,6 +62,20 @@ func (d *Vips) Init(config cfg.Config, logger zap.SugaredLogger) error {
 		return err
 	}
 
+	// load face detection cascade
+	d.config.cascade = focus.DefaultCascade()
+	if d.config.Cascade != "" {
+		buffer, err := ioutil.ReadFile(d.config.Cascade)
+		if err != nil {
+			return err
+		}
+
+		d.config.cascade, err = focus.LoadCascade(buffer)
+		if err != nil {
+			return err
+		}
+	}
+
 	d.logger = logger
 	return nil
 }
@@ -68,17 +92,38 @@ func (d *Vips) Stop() error {
 
 //Decode Decode input Bytes into a vipsImage
 func (d *Vips) Decode(in payload.Bytes, data payload.Data) (payload.DecodedImage, response.Response) {
+	// libvips loads only the first frame of animated images, so they're either reduced to it or rejected
+	if d.config.Animated == "reject" {
+		info, err := animation.Inspect(in)
+		if err != nil {
+			return nil, response.NoAck(err)
+		}
+		if info.Animated {
+			return nil, response.NoAck(fmt.Errorf("animated images are not supported"))
+		}
+	}
 
 	vimage, err := bimg.NewVipsImage(in)
-
 	if err != nil {
 		return nil, response.Error(err)
 	}
 
-	return image{
-		image:   vimage,
-		options: bimg.Options{},
-	}, response.ACK
+	img := image{
+		image:     vimage,
+		imageType: bimg.DetermineImageType(in),
+		options:   bimg.Options{},
+	}
+
+	// metadata is only needed for orientation and profile, images with broken metadata are processed without.
+	segments, err := metadata.Read(in)
+	if err == nil {
+		img.profile = metadata.ProfileDescription(segments.ICC)
+		if d.config.AutoRotate && segments.EXIF != nil {
+			img.orientation = orientation(segments.EXIF)
+		}
+	}
+
+	return img, response.ACK
 }
 
 //DecodeStream Decode input Stream into a vipsImage
@@ -88,15 +133,17 @@ func (d *Vips) DecodeStream(in payload.Stream, data payload.Data) (payload.Decod
 		return nil, response.Error(err)
 	}
 
-	vimage, err := bimg.NewVipsImage(buff)
+	return d.Decode(buff, data)
+}
+
+//orientation return EXIF orientation, 0 if it has none.
+func orientation(exif []byte) int {
+	parsed, err := metadata.ParseExif(exif)
 	if err != nil {
-		return nil, response.Error(err)
+		return 0
 	}
 
-	return image{
-		image:   vimage,
-		options: bimg.Options{},
-	}, response.ACK
+	return parsed.Orientation
 }
 
 //process process vips image according to internal configuration of Vips plugin
@@ -106,29 +153,123 @@ func (d *Vips) Process(in payload.DecodedImage, data payload.Data) (payload.Deco
 	defer runtime.KeepAlive(in)
 
 	vimage := in.(image)
-	params := defaultOptions()
+	options := defaultOptions()
 
 	img := vimage.image.Clone()
+	imageType := vimage.imageType
+
+	// orient the image before operations, so it's applied once regardless of stages or configured rotation.
+	rotated := vimage.orientation > 1
+	if rotated {
+		err := img.Process(*defaultOptions())
+		if err != nil {
+			return nil, response.Error(err)
+		}
+	}
 
 	//TODO use clone instead when finishing forking BIMG to be ready to use intermediate results.
 	//img := vimage.image.Clone()
 
-	// apply configs
-	err := d.config.Operations.Apply(params, data)
-	if err != nil {
-		return nil, response.Error(err)
+	for i, stage := range d.config.stages {
+		var err error
+
+		// bimg shrink-on-load of jpeg/webp re-reads the source buffer, which discards previous stages, so reload the
+		// image from a lossless intermediate result.
+		if i > 0 && (imageType == bimg.JPEG || imageType == bimg.WEBP) {
+			img, err = reload(img, *options)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+			imageType = bimg.PNG
+		}
+
+		// crop around a focal point, reload as shrink-on-load would discard the crop too.
+		cropped, err := d.focus(img, stage, data)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		if cropped && (imageType == bimg.JPEG || imageType == bimg.WEBP) {
+			img, err = reload(img, *options)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+			imageType = bimg.PNG
+		}
+
+		params := defaultOptions()
+		params.NoAutoRotate = true
+
+		// apply configs
+		err = stage.Apply(params, data)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		// process
+		err = img.Process(*params)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+
+		// composite operations need the dimensions of the processed image, so process them after the stage
+		if len(stage.composites) > 0 {
+			width, height := img.GetDimensions()
+			composite := &bimg.Options{NoAutoRotate: true}
+
+			err = stage.Composite(composite, width, height, data)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+
+			err = img.Process(*composite)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+		}
+
+		// tone operations have no bimg bindings, they're applied in Go on a lossless copy
+		if len(stage.tones) > 0 {
+			curves, err := stage.Curves(data)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+
+			img, err = adjust(img, *params, curves)
+			if err != nil {
+				return nil, response.Error(err)
+			}
+			imageType = bimg.PNG
+		}
+
+		// colourspace is only converted on save, keep the last one set by any stage
+		if params.Interpretation == defaultOptions().Interpretation {
+			params.Interpretation = options.Interpretation
+		}
+		options = params
 	}
 
-	// process
-	err = img.Process(*params)
+	return &image{
+		image:     img,
+		imageType: imageType,
+		options:   *options,
+		rotated:   rotated,
+		profile:   vimage.profile,
+	}, response.ACK
+}
+
+//reload encodes img losslessly and decodes it back as a new image
+func reload(img *bimg.VipsImage, options bimg.Options) (*bimg.VipsImage, error) {
+	options.Type = bimg.PNG
+	options.Compression = 1
+	options.StripMetadata = false
+
+	buffer, err := img.Save(options)
 	if err != nil {
-		return nil, response.Error(err)
+		return nil, err
 	}
 
-	return &image{
-		image:   img,
-		options: *params,
-	}, response.ACK
+	return bimg.NewVipsImage(buffer)
 }
 
 //Encode Encodes the image according to internal configurations of the plugin and returns it as a byte buffer
@@ -138,7 +279,7 @@ func (d *Vips) Encode(in payload.DecodedImage, data payload.Data) (payload.Bytes
 	img := in.(*image)
 
 	// apply export
-	err := d.config.Export.Apply(&img.options, data)
+	format, err := d.config.Export.Apply(&img.options, data)
 	if err != nil {
 		return nil, response.Error(err)
 	}
@@ -148,8 +289,30 @@ func (d *Vips) Encode(in payload.DecodedImage, data payload.Data) (payload.Bytes
 		return nil, response.Error(err)
 	}
 
+	if colors := d.config.Export.quantize(format); colors > 0 {
+		bytes, err = paletted(bytes, format, colors, img.options.Compression)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+	}
+
+	// kept EXIF orientation must be reset after rotating, or viewers will rotate the image again.
+	if d.config.Export.selective() || (img.rotated && !img.options.StripMetadata) {
+		bytes, err = metadata.Rewrite(bytes, d.config.Export.strip, img.rotated)
+		if err != nil {
+			return nil, response.Error(err)
+		}
+	}
+
 	data["_width"], data["_height"] = img.image.GetDimensions()
-	data["_format"] = d.config.Export.Raw.Format
+	data["_format"] = format
+	data[metadata.ColorspaceField] = interpretationName(img.options.Interpretation)
+	delete(data, metadata.ProfileField)
+	if profile := d.config.Export.profileOf(img); profile != "" && format != "gif" {
+		data[metadata.ProfileField] = profile
+	}
+	data[animation.AnimatedField] = false
+	data[animation.FramesField] = 1
 
 	return bytes, response.ACK
 }
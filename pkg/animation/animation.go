@@ -0,0 +1,141 @@
+// Package animation inspects animated GIF and WebP images without decoding their frames.
+package animation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Fields are the payload.Data keys under which animation info is reported.
+const (
+	AnimatedField = "_animated"
+	FramesField   = "_frames"
+)
+
+var errTruncated = errors.New("truncated image")
+
+// Info describes the frames of an image, non animated images have a single frame.
+type Info struct {
+	Animated bool
+	Frames   int
+	Duration time.Duration
+}
+
+// Inspect returns frames info of a GIF or WebP image, other formats are reported as a single frame.
+func Inspect(buf []byte) (Info, error) {
+	switch {
+	case bytes.HasPrefix(buf, []byte("GIF87a")), bytes.HasPrefix(buf, []byte("GIF89a")):
+		return inspectGIF(buf)
+	case len(buf) >= 12 && bytes.Equal(buf[0:4], []byte("RIFF")) && bytes.Equal(buf[8:12], []byte("WEBP")):
+		return inspectWebP(buf)
+	default:
+		return Info{Frames: 1}, nil
+	}
+}
+
+// inspectGIF walks GIF blocks counting image descriptors and summing graphic control delays.
+func inspectGIF(buf []byte) (Info, error) {
+	info := Info{}
+
+	// header + logical screen descriptor
+	pos := 13
+	if len(buf) < pos {
+		return info, errTruncated
+	}
+
+	// global color table
+	if flags := buf[10]; flags&0x80 != 0 {
+		pos += 3 * (1 << ((flags & 0x07) + 1))
+	}
+
+	for pos < len(buf) {
+		switch buf[pos] {
+		case 0x21: // extension
+			if pos+2 > len(buf) {
+				return info, errTruncated
+			}
+			// graphic control extension, delay in 1/100 of a second
+			if buf[pos+1] == 0xF9 && pos+6 <= len(buf) {
+				delay := binary.LittleEndian.Uint16(buf[pos+4 : pos+6])
+				info.Duration += time.Duration(delay) * 10 * time.Millisecond
+			}
+			pos += 2
+		case 0x2C: // image descriptor
+			if pos+10 > len(buf) {
+				return info, errTruncated
+			}
+			info.Frames++
+			flags := buf[pos+9]
+			pos += 10
+			// local color table
+			if flags&0x80 != 0 {
+				pos += 3 * (1 << ((flags & 0x07) + 1))
+			}
+			// LZW minimum code size
+			pos++
+		case 0x3B: // trailer
+			info.Animated = info.Frames > 1
+			return info, nil
+		default:
+			return info, errors.New("malformed GIF block")
+		}
+
+		// skip data sub-blocks
+		var err error
+		pos, err = skipSubBlocks(buf, pos)
+		if err != nil {
+			return info, err
+		}
+	}
+
+	// tolerate missing trailer
+	info.Animated = info.Frames > 1
+	return info, nil
+}
+
+func skipSubBlocks(buf []byte, pos int) (int, error) {
+	for {
+		if pos >= len(buf) {
+			return pos, errTruncated
+		}
+		size := int(buf[pos])
+		pos += size + 1
+		if size == 0 {
+			return pos, nil
+		}
+	}
+}
+
+// inspectWebP walks RIFF chunks counting ANMF frames and summing their durations.
+func inspectWebP(buf []byte) (Info, error) {
+	info := Info{}
+
+	for pos := 12; pos+8 <= len(buf); {
+		fourCC := string(buf[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(buf[pos+4 : pos+8]))
+		payload := pos + 8
+
+		if fourCC == "ANMF" {
+			if payload+16 > len(buf) {
+				return info, errTruncated
+			}
+			info.Frames++
+			// 24 bits duration in milliseconds
+			duration := uint32(buf[payload+12]) | uint32(buf[payload+13])<<8 | uint32(buf[payload+14])<<16
+			info.Duration += time.Duration(duration) * time.Millisecond
+		}
+
+		// chunks are padded to even sizes
+		pos = payload + size + size%2
+	}
+
+	// simple (non-animated) WebP
+	if info.Frames == 0 {
+		info.Frames = 1
+	}
+
+	info.Animated = info.Frames > 1
+	return info, nil
+}
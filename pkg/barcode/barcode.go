@@ -0,0 +1,87 @@
+// Package barcode decodes QR codes and common 1D barcodes (EAN-13, UPC-A, EAN-8, Code 128 and Code 39) from images.
+package barcode
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Formats of decoded codes
+const (
+	QR      = "qr"
+	EAN13   = "ean13"
+	UPCA    = "upca"
+	EAN8    = "ean8"
+	Code128 = "code128"
+	Code39  = "code39"
+)
+
+// Formats supported
+var Formats = []string{QR, EAN13, UPCA, EAN8, Code128, Code39}
+
+// Code is a decoded code, Points are its corners clockwise from its top-left corner.
+type Code struct {
+	Format string
+	Text   string
+	Points []image.Point
+}
+
+// Bounds return the bounding box of the code
+func (c Code) Bounds() image.Rectangle {
+	if len(c.Points) == 0 {
+		return image.Rectangle{}
+	}
+
+	bounds := image.Rectangle{Min: c.Points[0], Max: c.Points[0]}
+	for _, point := range c.Points[1:] {
+		if point.X < bounds.Min.X {
+			bounds.Min.X = point.X
+		}
+		if point.Y < bounds.Min.Y {
+			bounds.Min.Y = point.Y
+		}
+		if point.X > bounds.Max.X {
+			bounds.Max.X = point.X
+		}
+		if point.Y > bounds.Max.Y {
+			bounds.Max.Y = point.Y
+		}
+	}
+	return bounds
+}
+
+// Scan return the codes of the given formats found in the image, all formats are scanned if none are given.
+func Scan(img image.Image, formats ...string) []Code {
+	if len(formats) == 0 {
+		formats = Formats
+	}
+
+	enabled := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		enabled[format] = true
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return []Code{}
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+	matrix := binarize(gray)
+
+	codes := make([]Code, 0)
+	if enabled[QR] {
+		codes = append(codes, scanQR(matrix)...)
+	}
+	codes = append(codes, scanLinear(matrix, enabled)...)
+
+	// report positions in the image coordinates
+	for _, code := range codes {
+		for i := range code.Points {
+			code.Points[i] = code.Points[i].Add(bounds.Min)
+		}
+	}
+
+	return codes
+}
@@ -0,0 +1,201 @@
+package barcode
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+// samples were generated with github.com/boombuler/barcode, dark modules are '#'.
+const (
+	ean13Sample   = "#.#...##.#.#..###.#.####.####.#...#..#.##..##.#.#.#....#.#....#.#....#.###.#..#....#.##..##.#.#"
+	upcaSample    = "#.#...##.#.####.#.#.####...##.#...##.#...##.#.#.#.##.##..###.#..##..##.#.###..#..###.##.##..#.#"
+	ean8Sample    = "#.#...#.##.#.####.####.#.##.###.#.#.#..###.###..#.#...#..#.###..#.#"
+	code128Sample = "##.#..#....###.###.##.#..#..####.#....##.#..#.####..#..####.###.#.#..##.###..#..###..##.##..###..#.###.#..##..###.##...#.##...###.#.##"
+	code39Sample  = "#..#.##.##.#.#.##.##.#..#.##.#.#.##..#.#.##.#..##.#.#.##.#.##..#.##.##.#.#..#.#..#.#.##.##.##.##..#.#.#.#.##..#.##.#.#..#.##.##.#"
+)
+
+// qrSample encodes "https://prism.dev" at level M
+var qrSample = []string{
+	"#######.#...##.#..#######",
+	"#.....#.#..#.#....#.....#",
+	"#.###.#..###...#..#.###.#",
+	"#.###.#.#.#...###.#.###.#",
+	"#.###.#..##.#.###.#.###.#",
+	"#.....#..#.###....#.....#",
+	"#######.#.#.#.#.#.#######",
+	"........###.....#........",
+	"#.##.###.##.##..#.#..#.##",
+	"##..##..#....##.##.#...#.",
+	"#.###.#..#.#.#...#..#....",
+	"..#..#.#....#.....##.##..",
+	"#..#..#..####.##.####.###",
+	".#.##......##.#######...#",
+	".#..#.#..###.#..#.#.#.##.",
+	"#...##...###..#.#####...#",
+	"..#..##.#.#.#.###########",
+	"........#.#...#.#...#.#.#",
+	"#######.#....##.#.#.#.###",
+	"#.....#.#.##.##.#...#....",
+	"#.###.#...#..##.######...",
+	"#.###.#.###......##.#####",
+	"#.###.#.#.#...###.#.#.##.",
+	"#.....#..######.....#.#..",
+	"#######.#.##.....#.######",
+}
+
+// render draws modules of scale pixels with a quiet zone of 10 modules, the image starts at origin.
+func render(rows []string, scale int, origin image.Point) *image.Gray {
+	width, height := (len(rows[0])+20)*scale, (len(rows)+20)*scale
+	img := image.NewGray(image.Rectangle{Min: origin, Max: origin.Add(image.Pt(width, height))})
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for y, row := range rows {
+		for x, module := range row {
+			if module == '#' {
+				rect := image.Rect((x+10)*scale, (y+10)*scale, (x+11)*scale, (y+11)*scale).Add(origin)
+				draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return img
+}
+
+// linear repeats a 1D barcode on rows
+func linear(pattern string, height int) []string {
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = pattern
+	}
+	return rows
+}
+
+// transposed turns rows into columns
+func transposed(rows []string) []string {
+	columns := make([]string, len(rows[0]))
+	for x := range columns {
+		var column strings.Builder
+		for y := range rows {
+			column.WriteByte(rows[y][x])
+		}
+		columns[x] = column.String()
+	}
+	return columns
+}
+
+// rotated turns a square symbol a quarter clockwise
+func rotated(rows []string) []string {
+	result := make([]string, len(rows))
+	for y := range result {
+		var row strings.Builder
+		for x := range rows {
+			row.WriteByte(rows[len(rows)-1-x][y])
+		}
+		result[y] = row.String()
+	}
+	return result
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		img     image.Image
+		formats []string
+		want    []Code
+	}{
+		{
+			name: "qr",
+			img:  render(qrSample, 4, image.Point{}),
+			want: []Code{{Format: QR, Text: "https://prism.dev"}},
+		},
+		{
+			name: "qr rotated",
+			img:  render(rotated(qrSample), 4, image.Point{}),
+			want: []Code{{Format: QR, Text: "https://prism.dev"}},
+		},
+		{
+			name: "ean13",
+			img:  render(linear(ean13Sample, 30), 3, image.Point{}),
+			want: []Code{{Format: EAN13, Text: "4006381333931"}},
+		},
+		{
+			name: "upca",
+			img:  render(linear(upcaSample, 30), 3, image.Point{}),
+			want: []Code{{Format: UPCA, Text: "036000291452"}},
+		},
+		{
+			name:    "upca as ean13",
+			img:     render(linear(upcaSample, 30), 3, image.Point{}),
+			formats: []string{EAN13},
+			want:    []Code{{Format: EAN13, Text: "0036000291452"}},
+		},
+		{
+			name: "ean8",
+			img:  render(linear(ean8Sample, 30), 3, image.Point{}),
+			want: []Code{{Format: EAN8, Text: "96385074"}},
+		},
+		{
+			name: "code128",
+			img:  render(linear(code128Sample, 30), 3, image.Point{}),
+			want: []Code{{Format: Code128, Text: "Prism-128"}},
+		},
+		{
+			name: "code128 vertical",
+			img:  render(transposed(linear(code128Sample, 30)), 3, image.Point{}),
+			want: []Code{{Format: Code128, Text: "Prism-128"}},
+		},
+		{
+			name: "code39",
+			img:  render(linear(code39Sample, 30), 3, image.Point{}),
+			want: []Code{{Format: Code39, Text: "PRISM-39"}},
+		},
+		{
+			name:    "disabled format",
+			img:     render(linear(ean13Sample, 30), 3, image.Point{}),
+			formats: []string{QR, Code128},
+			want:    []Code{},
+		},
+		{
+			name: "empty image",
+			img:  image.NewGray(image.Rectangle{}),
+			want: []Code{},
+		},
+		{
+			name: "blank image",
+			img:  render([]string{"."}, 4, image.Point{}),
+			want: []Code{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Scan(tt.img, tt.formats...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Scan() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Format != tt.want[i].Format || got[i].Text != tt.want[i].Text {
+					t.Errorf("Scan()[%d] = %s %q, want %s %q", i, got[i].Format, got[i].Text, tt.want[i].Format, tt.want[i].Text)
+				}
+			}
+		})
+	}
+}
+
+func TestScanBounds(t *testing.T) {
+	// the symbol is at modules 10 to 35 of 4 pixels, in an image starting at (100, 50)
+	origin := image.Pt(100, 50)
+	symbol := image.Rect(40, 40, 140, 140).Add(origin)
+
+	codes := Scan(render(qrSample, 4, origin), QR)
+	if len(codes) != 1 {
+		t.Fatalf("Scan() = %+v, want a QR code", codes)
+	}
+
+	bounds := codes[0].Bounds()
+	if !bounds.In(symbol.Inset(-4)) || bounds.Dx() < symbol.Dx()-8 || bounds.Dy() < symbol.Dy()-8 {
+		t.Errorf("Bounds() = %v, want about %v", bounds, symbol)
+	}
+}
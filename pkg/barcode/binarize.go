@@ -0,0 +1,116 @@
+package barcode
+
+import (
+	"image"
+)
+
+// local thresholds are computed on blocks of blockSize pixels
+const (
+	blockSize   = 8
+	minContrast = 24
+)
+
+// bitmap is a binarized image, set bits are dark
+type bitmap struct {
+	width, height int
+	bits          []bool
+}
+
+func (b *bitmap) get(x, y int) bool {
+	return b.bits[y*b.width+x]
+}
+
+// binarize thresholds every pixel on the average of the 5x5 blocks around its block, so uneven lighting is handled.
+// blocks with little contrast are considered background.
+func binarize(gray *image.Gray) *bitmap {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	matrix := &bitmap{width: width, height: height, bits: make([]bool, width*height)}
+
+	columns, rows := (width+blockSize-1)/blockSize, (height+blockSize-1)/blockSize
+	if columns < 5 || rows < 5 {
+		global(gray, matrix)
+		return matrix
+	}
+
+	// black point of every block
+	points := make([]int, columns*rows)
+	for by := 0; by < rows; by++ {
+		for bx := 0; bx < columns; bx++ {
+			sum, count, min, max := 0, 0, 255, 0
+			for y := by * blockSize; y < (by+1)*blockSize && y < height; y++ {
+				for x := bx * blockSize; x < (bx+1)*blockSize && x < width; x++ {
+					value := int(gray.Pix[y*gray.Stride+x])
+					sum += value
+					count++
+					if value < min {
+						min = value
+					}
+					if value > max {
+						max = value
+					}
+				}
+			}
+
+			average := sum / count
+			if max-min <= minContrast {
+				// background, unless it's darker than its neighbours
+				average = min / 2
+				if by > 0 && bx > 0 {
+					neighbours := (points[(by-1)*columns+bx] + 2*points[by*columns+bx-1] + points[(by-1)*columns+bx-1]) / 4
+					if min < neighbours {
+						average = neighbours
+					}
+				}
+			}
+			points[by*columns+bx] = average
+		}
+	}
+
+	for by := 0; by < rows; by++ {
+		for bx := 0; bx < columns; bx++ {
+			cx, cy := clamp(bx, 2, columns-3), clamp(by, 2, rows-3)
+			sum := 0
+			for y := cy - 2; y <= cy+2; y++ {
+				for x := cx - 2; x <= cx+2; x++ {
+					sum += points[y*columns+x]
+				}
+			}
+			threshold := sum / 25
+
+			for y := by * blockSize; y < (by+1)*blockSize && y < height; y++ {
+				for x := bx * blockSize; x < (bx+1)*blockSize && x < width; x++ {
+					matrix.bits[y*width+x] = int(gray.Pix[y*gray.Stride+x]) <= threshold
+				}
+			}
+		}
+	}
+
+	return matrix
+}
+
+// global thresholds small images on their mean
+func global(gray *image.Gray, matrix *bitmap) {
+	sum := 0
+	for y := 0; y < matrix.height; y++ {
+		for x := 0; x < matrix.width; x++ {
+			sum += int(gray.Pix[y*gray.Stride+x])
+		}
+	}
+
+	threshold := sum / (matrix.width * matrix.height)
+	for y := 0; y < matrix.height; y++ {
+		for x := 0; x < matrix.width; x++ {
+			matrix.bits[y*matrix.width+x] = int(gray.Pix[y*gray.Stride+x]) < threshold
+		}
+	}
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
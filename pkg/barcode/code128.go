@@ -0,0 +1,170 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	code128MaxVariance   = 0.25
+	code128MaxIndividual = 0.7
+
+	code128Shift  = 98
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128FNC1   = 102
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// widths of the Code 128 symbols starting with a dark run, the stop symbol has a final dark run.
+var code128Patterns = [107][]int{
+	{2, 1, 2, 2, 2, 2}, {2, 2, 2, 1, 2, 2}, {2, 2, 2, 2, 2, 1}, {1, 2, 1, 2, 2, 3}, {1, 2, 1, 3, 2, 2},
+	{1, 3, 1, 2, 2, 2}, {1, 2, 2, 2, 1, 3}, {1, 2, 2, 3, 1, 2}, {1, 3, 2, 2, 1, 2}, {2, 2, 1, 2, 1, 3},
+	{2, 2, 1, 3, 1, 2}, {2, 3, 1, 2, 1, 2}, {1, 1, 2, 2, 3, 2}, {1, 2, 2, 1, 3, 2}, {1, 2, 2, 2, 3, 1},
+	{1, 1, 3, 2, 2, 2}, {1, 2, 3, 1, 2, 2}, {1, 2, 3, 2, 2, 1}, {2, 2, 3, 2, 1, 1}, {2, 2, 1, 1, 3, 2},
+	{2, 2, 1, 2, 3, 1}, {2, 1, 3, 2, 1, 2}, {2, 2, 3, 1, 1, 2}, {3, 1, 2, 1, 3, 1}, {3, 1, 1, 2, 2, 2},
+	{3, 2, 1, 1, 2, 2}, {3, 2, 1, 2, 2, 1}, {3, 1, 2, 2, 1, 2}, {3, 2, 2, 1, 1, 2}, {3, 2, 2, 2, 1, 1},
+	{2, 1, 2, 1, 2, 3}, {2, 1, 2, 3, 2, 1}, {2, 3, 2, 1, 2, 1}, {1, 1, 1, 3, 2, 3}, {1, 3, 1, 1, 2, 3},
+	{1, 3, 1, 3, 2, 1}, {1, 1, 2, 3, 1, 3}, {1, 3, 2, 1, 1, 3}, {1, 3, 2, 3, 1, 1}, {2, 1, 1, 3, 1, 3},
+	{2, 3, 1, 1, 1, 3}, {2, 3, 1, 3, 1, 1}, {1, 1, 2, 1, 3, 3}, {1, 1, 2, 3, 3, 1}, {1, 3, 2, 1, 3, 1},
+	{1, 1, 3, 1, 2, 3}, {1, 1, 3, 3, 2, 1}, {1, 3, 3, 1, 2, 1}, {3, 1, 3, 1, 2, 1}, {2, 1, 1, 3, 3, 1},
+	{2, 3, 1, 1, 3, 1}, {2, 1, 3, 1, 1, 3}, {2, 1, 3, 3, 1, 1}, {2, 1, 3, 1, 3, 1}, {3, 1, 1, 1, 2, 3},
+	{3, 1, 1, 3, 2, 1}, {3, 3, 1, 1, 2, 1}, {3, 1, 2, 1, 1, 3}, {3, 1, 2, 3, 1, 1}, {3, 3, 2, 1, 1, 1},
+	{3, 1, 4, 1, 1, 1}, {2, 2, 1, 4, 1, 1}, {4, 3, 1, 1, 1, 1}, {1, 1, 1, 2, 2, 4}, {1, 1, 1, 4, 2, 2},
+	{1, 2, 1, 1, 2, 4}, {1, 2, 1, 4, 2, 1}, {1, 4, 1, 1, 2, 2}, {1, 4, 1, 2, 2, 1}, {1, 1, 2, 2, 1, 4},
+	{1, 1, 2, 4, 1, 2}, {1, 2, 2, 1, 1, 4}, {1, 2, 2, 4, 1, 1}, {1, 4, 2, 1, 1, 2}, {1, 4, 2, 2, 1, 1},
+	{2, 4, 1, 2, 1, 1}, {2, 2, 1, 1, 1, 4}, {4, 1, 3, 1, 1, 1}, {2, 4, 1, 1, 1, 2}, {1, 3, 4, 1, 1, 1},
+	{1, 1, 1, 2, 4, 2}, {1, 2, 1, 1, 4, 2}, {1, 2, 1, 2, 4, 1}, {1, 1, 4, 2, 1, 2}, {1, 2, 4, 1, 1, 2},
+	{1, 2, 4, 2, 1, 1}, {4, 1, 1, 2, 1, 2}, {4, 2, 1, 1, 1, 2}, {4, 2, 1, 2, 1, 1}, {2, 1, 2, 1, 4, 1},
+	{2, 1, 4, 1, 2, 1}, {4, 1, 2, 1, 2, 1}, {1, 1, 1, 1, 4, 3}, {1, 1, 1, 3, 4, 1}, {1, 3, 1, 1, 4, 1},
+	{1, 1, 4, 1, 1, 3}, {1, 1, 4, 3, 1, 1}, {4, 1, 1, 1, 1, 3}, {4, 1, 1, 3, 1, 1}, {1, 1, 3, 1, 4, 1},
+	{1, 1, 4, 1, 3, 1}, {3, 1, 1, 1, 4, 1}, {4, 1, 1, 1, 3, 1}, {2, 1, 1, 4, 1, 2}, {2, 1, 1, 2, 1, 4},
+	{2, 1, 1, 2, 3, 2}, {2, 3, 3, 1, 1, 1, 2},
+}
+
+// decodeCode128 decodes Code 128 barcodes in code sets A, B and C, FNC1 is read as a GS separator except in first
+// position.
+func decodeCode128(runs []int, start int, enabled map[string]bool) (string, string, int, bool) {
+	if !enabled[Code128] || start+6 > len(runs) {
+		return "", "", 0, false
+	}
+
+	code, ok := code128Symbol(runs[start : start+6])
+	if !ok || code < code128StartA || code > code128StartC {
+		return "", "", 0, false
+	}
+	if !quiet(runs, start, float64(sum(runs[start:start+6]))/2) {
+		return "", "", 0, false
+	}
+
+	symbols := []int{code}
+	position := start + 6
+	for {
+		if position+6 > len(runs) {
+			return "", "", 0, false
+		}
+		code, ok := code128Symbol(runs[position : position+6])
+		if !ok {
+			return "", "", 0, false
+		}
+		position += 6
+		if code == code128Stop {
+			break
+		}
+		symbols = append(symbols, code)
+	}
+
+	// the stop symbol ends with a dark run
+	if position >= len(runs) {
+		return "", "", 0, false
+	}
+	end := position + 1
+
+	if len(symbols) < 2 {
+		return "", "", 0, false
+	}
+	checksum := symbols[0]
+	for i, code := range symbols[1 : len(symbols)-1] {
+		checksum += (i + 1) * code
+	}
+	if checksum%103 != symbols[len(symbols)-1] {
+		return "", "", 0, false
+	}
+
+	text, err := code128Text(symbols[:len(symbols)-1])
+	if err != nil {
+		return "", "", 0, false
+	}
+
+	return Code128, text, end, true
+}
+
+// code128Symbol return the symbol matching runs best
+func code128Symbol(runs []int) (int, bool) {
+	best, bestVariance := -1, code128MaxVariance
+	for code, pattern := range code128Patterns {
+		if v := variance(runs, pattern, code128MaxIndividual); v < bestVariance {
+			best, bestVariance = code, v
+		}
+	}
+	return best, best >= 0
+}
+
+// code128Text return the text of symbols, starting with the start symbol and without the checksum.
+func code128Text(symbols []int) (string, error) {
+	var text strings.Builder
+	set := symbols[0] - code128StartA + 'A'
+	shifted := false
+
+	for i, code := range symbols[1:] {
+		current := set
+		if shifted {
+			current = 'A' + 'B' - set
+			shifted = false
+		}
+
+		if code == code128FNC1 {
+			if i > 0 {
+				text.WriteByte(0x1D)
+			}
+			continue
+		}
+
+		switch current {
+		case 'C':
+			switch {
+			case code < 100:
+				text.WriteString(fmt.Sprintf("%02d", code))
+			case code == code128CodeB:
+				set = 'B'
+			case code == code128CodeA:
+				set = 'A'
+			default:
+				return "", fmt.Errorf("invalid code set C symbol %d", code)
+			}
+		case 'A', 'B':
+			switch {
+			case current == 'A' && code < 64:
+				text.WriteByte(byte(' ' + code))
+			case current == 'A' && code < 96:
+				text.WriteByte(byte(code - 64))
+			case current == 'B' && code < 96:
+				text.WriteByte(byte(' ' + code))
+			case code == code128Shift:
+				shifted = true
+			case code == code128CodeC:
+				set = 'C'
+			case current == 'A' && code == code128CodeB, current == 'B' && code == code128CodeA:
+				set = 'A' + 'B' - current
+			default:
+				// FNC2, FNC3 and FNC4 carry no text
+			}
+		}
+	}
+
+	return text.String(), nil
+}
@@ -0,0 +1,98 @@
+package barcode
+
+import (
+	"math"
+	"strings"
+)
+
+const code39Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%"
+
+// wide elements of the Code 39 characters, from the first run in the most significant of 9 bits.
+var code39Encodings = [...]int{
+	0x034, 0x121, 0x061, 0x160, 0x031, 0x130, 0x070, 0x025, 0x124, 0x064,
+	0x109, 0x049, 0x148, 0x019, 0x118, 0x058, 0x00D, 0x10C, 0x04C, 0x01C,
+	0x103, 0x043, 0x142, 0x013, 0x112, 0x052, 0x007, 0x106, 0x046, 0x016,
+	0x181, 0x0C1, 0x1C0, 0x091, 0x190, 0x0D0, 0x085, 0x184, 0x0C4, 0x0A8,
+	0x0A2, 0x08A, 0x02A,
+}
+
+// the start and stop character
+const code39Asterisk = 0x094
+
+// decodeCode39 decodes Code 39 barcodes without check digit or full ASCII interpretation.
+func decodeCode39(runs []int, start int, enabled map[string]bool) (string, string, int, bool) {
+	if !enabled[Code39] || start+9 > len(runs) || narrowWide(runs[start:start+9]) != code39Asterisk {
+		return "", "", 0, false
+	}
+	if !quiet(runs, start, float64(sum(runs[start:start+9]))/2) {
+		return "", "", 0, false
+	}
+
+	var text strings.Builder
+	position := start + 10
+	for {
+		if position+9 > len(runs) {
+			return "", "", 0, false
+		}
+
+		pattern := narrowWide(runs[position : position+9])
+		if pattern == code39Asterisk {
+			break
+		}
+
+		index := -1
+		for i, encoding := range code39Encodings {
+			if encoding == pattern {
+				index = i
+			}
+		}
+		if index < 0 {
+			return "", "", 0, false
+		}
+		text.WriteByte(code39Alphabet[index])
+		position += 10
+	}
+
+	if text.Len() == 0 {
+		return "", "", 0, false
+	}
+
+	return Code39, text.String(), position + 9, true
+}
+
+// narrowWide return the wide elements of a character as bits, -1 if there aren't exactly 3 wide elements of similar
+// widths.
+func narrowWide(runs []int) int {
+	maxNarrow := 0
+	for {
+		minRun := math.MaxInt32
+		for _, run := range runs {
+			if run < minRun && run > maxNarrow {
+				minRun = run
+			}
+		}
+		maxNarrow = minRun
+
+		wide, totalWide, pattern := 0, 0, 0
+		for i, run := range runs {
+			if run > maxNarrow {
+				pattern |= 1 << uint(len(runs)-1-i)
+				wide++
+				totalWide += run
+			}
+		}
+
+		if wide == 3 {
+			for _, run := range runs {
+				if run > maxNarrow && run*2 >= totalWide {
+					return -1
+				}
+			}
+			return pattern
+		}
+
+		if wide < 3 {
+			return -1
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package barcode
+
+import (
+	"math"
+)
+
+const (
+	eanMaxVariance   = 0.48
+	eanMaxIndividual = 0.7
+)
+
+// widths of the digits L codes starting with a light run, G codes are their reverse and R codes are L codes starting
+// with a dark run.
+var eanDigits = [10][]int{
+	{3, 2, 1, 1},
+	{2, 2, 2, 1},
+	{2, 1, 2, 2},
+	{1, 4, 1, 1},
+	{1, 1, 3, 2},
+	{1, 2, 3, 1},
+	{1, 1, 1, 4},
+	{1, 3, 1, 2},
+	{1, 2, 1, 3},
+	{3, 1, 1, 2},
+}
+
+// parities of the left digits of EAN-13 encoding its first digit
+var eanParities = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+var (
+	eanGuard  = []int{1, 1, 1}
+	eanMiddle = []int{1, 1, 1, 1, 1}
+)
+
+// decodeEAN decodes EAN-13, UPC-A (EAN-13 starting with 0) and EAN-8 barcodes.
+func decodeEAN(runs []int, start int, enabled map[string]bool) (string, string, int, bool) {
+	if start+3 > len(runs) || variance(runs[start:start+3], eanGuard, eanMaxIndividual) >= eanMaxVariance {
+		return "", "", 0, false
+	}
+	if !quiet(runs, start, float64(sum(runs[start:start+3]))) {
+		return "", "", 0, false
+	}
+
+	if enabled[EAN13] || enabled[UPCA] {
+		if digits, end, ok := decodeEAN13(runs, start); ok {
+			if digits[0] == '0' && enabled[UPCA] {
+				return UPCA, digits[1:], end, true
+			}
+			if enabled[EAN13] {
+				return EAN13, digits, end, true
+			}
+		}
+	}
+
+	if enabled[EAN8] {
+		if digits, end, ok := decodeEAN8(runs, start); ok {
+			return EAN8, digits, end, true
+		}
+	}
+
+	return "", "", 0, false
+}
+
+func decodeEAN13(runs []int, start int) (string, int, bool) {
+	end := start + 59
+	if end > len(runs) {
+		return "", 0, false
+	}
+
+	digits := make([]byte, 13)
+	parity := make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		digit, g, ok := eanDigit(runs[start+3+4*i:start+7+4*i], true)
+		if !ok {
+			return "", 0, false
+		}
+		digits[i+1] = '0' + byte(digit)
+		parity[i] = 'L'
+		if g {
+			parity[i] = 'G'
+		}
+	}
+
+	first := -1
+	for digit, expected := range eanParities {
+		if string(parity) == expected {
+			first = digit
+		}
+	}
+	if first < 0 {
+		return "", 0, false
+	}
+	digits[0] = '0' + byte(first)
+
+	if variance(runs[start+27:start+32], eanMiddle, eanMaxIndividual) >= eanMaxVariance {
+		return "", 0, false
+	}
+
+	for i := 0; i < 6; i++ {
+		digit, _, ok := eanDigit(runs[start+32+4*i:start+36+4*i], false)
+		if !ok {
+			return "", 0, false
+		}
+		digits[i+7] = '0' + byte(digit)
+	}
+
+	if !eanEnd(runs, start+56) || !eanChecksum(digits) {
+		return "", 0, false
+	}
+
+	return string(digits), end, true
+}
+
+func decodeEAN8(runs []int, start int) (string, int, bool) {
+	end := start + 43
+	if end > len(runs) {
+		return "", 0, false
+	}
+
+	digits := make([]byte, 8)
+	for i := 0; i < 4; i++ {
+		digit, g, ok := eanDigit(runs[start+3+4*i:start+7+4*i], false)
+		if !ok || g {
+			return "", 0, false
+		}
+		digits[i] = '0' + byte(digit)
+	}
+
+	if variance(runs[start+19:start+24], eanMiddle, eanMaxIndividual) >= eanMaxVariance {
+		return "", 0, false
+	}
+
+	for i := 0; i < 4; i++ {
+		digit, _, ok := eanDigit(runs[start+24+4*i:start+28+4*i], false)
+		if !ok {
+			return "", 0, false
+		}
+		digits[i+4] = '0' + byte(digit)
+	}
+
+	if !eanEnd(runs, start+40) || !eanChecksum(digits) {
+		return "", 0, false
+	}
+
+	return string(digits), end, true
+}
+
+// eanDigit return the digit matching runs best, and whether it's a G code
+func eanDigit(runs []int, withG bool) (int, bool, bool) {
+	best, bestG, bestVariance := -1, false, eanMaxVariance
+	for digit, pattern := range eanDigits {
+		if v := variance(runs, pattern, eanMaxIndividual); v < bestVariance {
+			best, bestG, bestVariance = digit, false, v
+		}
+		if !withG {
+			continue
+		}
+		reversed := []int{pattern[3], pattern[2], pattern[1], pattern[0]}
+		if v := variance(runs, reversed, eanMaxIndividual); v < bestVariance {
+			best, bestG, bestVariance = digit, true, v
+		}
+	}
+	return best, bestG, best >= 0
+}
+
+// eanEnd return whether the end guard is at guard followed by a quiet zone or the end of the line
+func eanEnd(runs []int, guard int) bool {
+	if variance(runs[guard:guard+3], eanGuard, eanMaxIndividual) >= eanMaxVariance {
+		return false
+	}
+	return guard+3 == len(runs) || float64(runs[guard+3]) >= math.Floor(float64(sum(runs[guard:guard+3]))/2)
+}
+
+// eanChecksum validates the last digit, digits are weighted 3 and 1 alternately from the right.
+func eanChecksum(digits []byte) bool {
+	total := 0
+	for i := len(digits) - 2; i >= 0; i-- {
+		weight := 1
+		if (len(digits)-2-i)%2 == 0 {
+			weight = 3
+		}
+		total += int(digits[i]-'0') * weight
+	}
+	return (10-total%10)%10 == int(digits[len(digits)-1]-'0')
+}
@@ -0,0 +1,183 @@
+package barcode
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// most rows and columns scanned for 1D barcodes in each direction
+	maxLines = 48
+
+	// 1D barcodes must be read the same on this many lines to be reported
+	linearQuorum = 2
+)
+
+// linearDecoder decodes a barcode starting at the dark run start, and return the index after its last run.
+type linearDecoder func(runs []int, start int, enabled map[string]bool) (format, text string, end int, ok bool)
+
+var linearDecoders = []linearDecoder{decodeEAN, decodeCode128, decodeCode39}
+
+// scanLine is a row or column of the matrix as alternating light and dark run lengths, starting with a light run
+// that may be empty.
+type scanLine struct {
+	runs   []int
+	starts []int
+}
+
+func newScanLine(pixels []bool) scanLine {
+	line := scanLine{runs: []int{0}, starts: []int{0}}
+	for i, dark := range pixels {
+		if dark != (len(line.runs)%2 == 0) {
+			line.runs = append(line.runs, 0)
+			line.starts = append(line.starts, i)
+		}
+		line.runs[len(line.runs)-1]++
+	}
+	line.starts = append(line.starts, len(pixels))
+	return line
+}
+
+// linearResult gathers the lines a barcode was read on and their extent
+type linearResult struct {
+	format, text string
+	lines        map[int]bool
+	bounds       image.Rectangle
+}
+
+// scanLinear return the enabled 1D barcodes found on rows and columns of the matrix
+func scanLinear(matrix *bitmap, enabled map[string]bool) []Code {
+	if !enabled[EAN13] && !enabled[UPCA] && !enabled[EAN8] && !enabled[Code128] && !enabled[Code39] {
+		return nil
+	}
+
+	results := make(map[string]*linearResult)
+	order := make([]string, 0)
+	record := func(id int, format, text string, segment image.Rectangle) {
+		key := format + "\x00" + text
+		result, ok := results[key]
+		if !ok {
+			result = &linearResult{format: format, text: text, lines: make(map[int]bool), bounds: segment}
+			results[key] = result
+			order = append(order, key)
+		}
+		result.lines[id] = true
+		result.bounds = result.bounds.Union(segment)
+	}
+
+	rowStep := int(math.Max(1, float64(matrix.height/maxLines)))
+	for y := rowStep / 2; y < matrix.height; y += rowStep {
+		pixels := make([]bool, matrix.width)
+		for x := range pixels {
+			pixels[x] = matrix.get(x, y)
+		}
+		for _, segment := range decodeLine(pixels, enabled) {
+			record(y, segment.format, segment.text, image.Rect(segment.from, y, segment.to, y+1))
+		}
+	}
+
+	columnStep := int(math.Max(1, float64(matrix.width/maxLines)))
+	for x := columnStep / 2; x < matrix.width; x += columnStep {
+		pixels := make([]bool, matrix.height)
+		for y := range pixels {
+			pixels[y] = matrix.get(x, y)
+		}
+		for _, segment := range decodeLine(pixels, enabled) {
+			record(-1-x, segment.format, segment.text, image.Rect(x, segment.from, x+1, segment.to))
+		}
+	}
+
+	codes := make([]Code, 0)
+	for _, key := range order {
+		result := results[key]
+		if len(result.lines) < linearQuorum {
+			continue
+		}
+		b := result.bounds
+		codes = append(codes, Code{
+			Format: result.format,
+			Text:   result.text,
+			Points: []image.Point{b.Min, image.Pt(b.Max.X, b.Min.Y), b.Max, image.Pt(b.Min.X, b.Max.Y)},
+		})
+	}
+
+	return codes
+}
+
+// lineSegment is a barcode read on a line between the pixels from and to
+type lineSegment struct {
+	format, text string
+	from, to     int
+}
+
+// decodeLine return the barcodes read on pixels in both directions
+func decodeLine(pixels []bool, enabled map[string]bool) []lineSegment {
+	segments := decodeRuns(newScanLine(pixels), enabled)
+
+	reversed := make([]bool, len(pixels))
+	for i, dark := range pixels {
+		reversed[len(pixels)-1-i] = dark
+	}
+	for _, segment := range decodeRuns(newScanLine(reversed), enabled) {
+		segment.from, segment.to = len(pixels)-segment.to, len(pixels)-segment.from
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+func decodeRuns(line scanLine, enabled map[string]bool) []lineSegment {
+	segments := make([]lineSegment, 0)
+	for start := 1; start < len(line.runs); start += 2 {
+		for _, decoder := range linearDecoders {
+			format, text, end, ok := decoder(line.runs, start, enabled)
+			if !ok {
+				continue
+			}
+			segments = append(segments, lineSegment{format: format, text: text, from: line.starts[start], to: line.starts[end]})
+			start = end - 1
+			break
+		}
+	}
+	return segments
+}
+
+// variance return how much runs deviate from the pattern relative to their total, +Inf if a single run deviates more
+// than maxIndividual modules.
+func variance(runs []int, pattern []int, maxIndividual float64) float64 {
+	total, patternTotal := 0, 0
+	for i := range runs {
+		total += runs[i]
+		patternTotal += pattern[i]
+	}
+	if total < patternTotal {
+		return math.Inf(1)
+	}
+
+	unit := float64(total) / float64(patternTotal)
+	maxIndividual *= unit
+
+	sum := 0.0
+	for i, run := range runs {
+		deviation := math.Abs(float64(run) - float64(pattern[i])*unit)
+		if deviation > maxIndividual {
+			return math.Inf(1)
+		}
+		sum += deviation
+	}
+
+	return sum / float64(total)
+}
+
+// quiet return whether the light run before start is at least width wide
+func quiet(runs []int, start int, width float64) bool {
+	return start > 0 && float64(runs[start-1]) >= width
+}
+
+func sum(runs []int) int {
+	total := 0
+	for _, run := range runs {
+		total += run
+	}
+	return total
+}
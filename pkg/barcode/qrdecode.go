@@ -0,0 +1,353 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+var errFormat = errors.New("unreadable format information")
+
+// qrGrid is a sampled QR code, true modules are dark
+type qrGrid [][]bool
+
+func (g qrGrid) size() int {
+	return len(g)
+}
+
+func (g qrGrid) bit(x, y int) int {
+	if g[y][x] {
+		return 1
+	}
+	return 0
+}
+
+// format return the error correction level (index in qrBlocks) and data mask of the grid
+func (g qrGrid) format() (int, int, error) {
+	size := g.size()
+	first, second := 0, 0
+	for i := 0; i <= 5; i++ {
+		first |= g.bit(8, i) << uint(i)
+	}
+	first |= g.bit(8, 7)<<6 | g.bit(8, 8)<<7 | g.bit(7, 8)<<8
+	for i := 9; i < 15; i++ {
+		first |= g.bit(14-i, 8) << uint(i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= g.bit(size-1-i, 8) << uint(i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= g.bit(8, size-15+i) << uint(i)
+	}
+
+	best, distance := 0, 4
+	for data, code := range formatInfo() {
+		for _, read := range []int{first, second} {
+			if d := bits.OnesCount(uint(read ^ code)); d < distance {
+				best, distance = data, d
+			}
+		}
+	}
+	if distance > 3 {
+		return 0, 0, errFormat
+	}
+
+	return qrLevels[best>>3], best & 7, nil
+}
+
+// version return the version of the grid read from its version information, versions below 7 are inferred by size.
+func (g qrGrid) version() (int, error) {
+	size := g.size()
+	version := (size - 17) / 4
+	if version < 7 {
+		return version, nil
+	}
+
+	first, second := 0, 0
+	for i := 0; i < 18; i++ {
+		a, b := size-11+i%3, i/3
+		first |= g.bit(a, b) << uint(i)
+		second |= g.bit(b, a) << uint(i)
+	}
+
+	best, distance := 0, 4
+	for v := 7; v <= 40; v++ {
+		code := versionInfo(v)
+		for _, read := range []int{first, second} {
+			if d := bits.OnesCount(uint(read ^ code)); d < distance {
+				best, distance = v, d
+			}
+		}
+	}
+	if distance > 3 {
+		return 0, errors.New("unreadable version information")
+	}
+
+	return best, nil
+}
+
+// decode return the text encoded in the grid
+func (g qrGrid) decode() (string, error) {
+	size := g.size()
+	if size < 21 || size > 177 || (size-17)%4 != 0 {
+		return "", fmt.Errorf("invalid size %d", size)
+	}
+	version := (size - 17) / 4
+
+	level, mask, err := g.format()
+	if err != nil {
+		return "", err
+	}
+
+	blocks := blocks(version, level)
+	total := 0
+	for _, block := range blocks {
+		total += block.total
+	}
+
+	codewords := g.codewords(version, mask, total)
+
+	data, err := deinterleave(codewords, blocks)
+	if err != nil {
+		return "", err
+	}
+
+	return parseSegments(data, version)
+}
+
+// codewords read the unmasked codewords in their zigzag placement
+func (g qrGrid) codewords(version, mask, count int) []int {
+	size := g.size()
+	function := functions(version)
+	codewords := make([]int, count)
+
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = size - 1 - vertical
+				}
+				if function[y][x] || i >= count*8 {
+					continue
+				}
+				if g[y][x] != masked(mask, x, y) {
+					codewords[i/8] |= 1 << uint(7-i%8)
+				}
+				i++
+			}
+		}
+	}
+
+	return codewords
+}
+
+// deinterleave split codewords into their blocks, correct them, and return their data codewords.
+func deinterleave(codewords []int, blocks []qrBlock) ([]int, error) {
+	contents := make([][]int, len(blocks))
+	maxData := 0
+	for i, block := range blocks {
+		contents[i] = make([]int, block.total)
+		if block.data > maxData {
+			maxData = block.data
+		}
+	}
+
+	next := 0
+	for i := 0; i < maxData; i++ {
+		for j, block := range blocks {
+			if i < block.data {
+				contents[j][i] = codewords[next]
+				next++
+			}
+		}
+	}
+	ecCount := blocks[0].total - blocks[0].data
+	for i := 0; i < ecCount; i++ {
+		for j, block := range blocks {
+			contents[j][block.data+i] = codewords[next]
+			next++
+		}
+	}
+
+	data := make([]int, 0, len(codewords))
+	for i, block := range blocks {
+		if err := correct(contents[i], ecCount); err != nil {
+			return nil, err
+		}
+		data = append(data, contents[i][:block.data]...)
+	}
+
+	return data, nil
+}
+
+// bitReader reads bits of codewords from the most significant
+type bitReader struct {
+	codewords []int
+	offset    int
+}
+
+func (r *bitReader) available() int {
+	return len(r.codewords)*8 - r.offset
+}
+
+func (r *bitReader) read(count int) (int, error) {
+	if count > r.available() {
+		return 0, errors.New("unexpected end of data")
+	}
+
+	value := 0
+	for i := 0; i < count; i++ {
+		bit := r.codewords[r.offset/8] >> uint(7-r.offset%8) & 1
+		value = value<<1 | bit
+		r.offset++
+	}
+	return value, nil
+}
+
+// countBits return the character count length of a mode in a version
+func countBits(mode, version int) int {
+	group := 0
+	if version >= 27 {
+		group = 2
+	} else if version >= 10 {
+		group = 1
+	}
+
+	switch mode {
+	case 1:
+		return [3]int{10, 12, 14}[group]
+	case 2:
+		return [3]int{9, 11, 13}[group]
+	case 4:
+		return [3]int{8, 16, 16}[group]
+	default:
+		return [3]int{8, 10, 12}[group]
+	}
+}
+
+// parseSegments return the text of data codewords, byte segments are read as UTF-8 when valid and ISO-8859-1 otherwise.
+func parseSegments(data []int, version int) (string, error) {
+	reader := &bitReader{codewords: data}
+	var text strings.Builder
+	raw := make([]byte, 0)
+
+	flush := func() {
+		if utf8.Valid(raw) {
+			text.Write(raw)
+		} else {
+			for _, b := range raw {
+				text.WriteRune(rune(b))
+			}
+		}
+		raw = raw[:0]
+	}
+
+	for reader.available() >= 4 {
+		mode, _ := reader.read(4)
+		switch mode {
+		case 0:
+			flush()
+			return text.String(), nil
+		case 1:
+			flush()
+			count, err := reader.read(countBits(mode, version))
+			if err != nil {
+				return "", err
+			}
+			for count > 0 {
+				digits := 3
+				if count < 3 {
+					digits = count
+				}
+				value, err := reader.read(digits*3 + 1)
+				if err != nil {
+					return "", err
+				}
+				text.WriteString(fmt.Sprintf("%0*d", digits, value))
+				count -= digits
+			}
+		case 2:
+			flush()
+			count, err := reader.read(countBits(mode, version))
+			if err != nil {
+				return "", err
+			}
+			for ; count >= 2; count -= 2 {
+				value, err := reader.read(11)
+				if err != nil {
+					return "", err
+				}
+				if value/45 >= len(alphanumeric) {
+					return "", errors.New("invalid alphanumeric value")
+				}
+				text.WriteByte(alphanumeric[value/45])
+				text.WriteByte(alphanumeric[value%45])
+			}
+			if count == 1 {
+				value, err := reader.read(6)
+				if err != nil {
+					return "", err
+				}
+				if value >= len(alphanumeric) {
+					return "", errors.New("invalid alphanumeric value")
+				}
+				text.WriteByte(alphanumeric[value])
+			}
+		case 4:
+			count, err := reader.read(countBits(mode, version))
+			if err != nil {
+				return "", err
+			}
+			for i := 0; i < count; i++ {
+				value, err := reader.read(8)
+				if err != nil {
+					return "", err
+				}
+				raw = append(raw, byte(value))
+			}
+		case 7:
+			// ECI designator, text is decoded by validity regardless
+			first, err := reader.read(8)
+			if err != nil {
+				return "", err
+			}
+			if first&0x80 != 0 {
+				extra := 8
+				if first&0xC0 == 0xC0 {
+					extra = 16
+				}
+				if _, err := reader.read(extra); err != nil {
+					return "", err
+				}
+			}
+		case 3:
+			// structured append header
+			if _, err := reader.read(16); err != nil {
+				return "", err
+			}
+		case 5:
+			// FNC1 in first position
+		case 9:
+			// FNC1 in second position, followed by the application indicator
+			if _, err := reader.read(8); err != nil {
+				return "", err
+			}
+		default:
+			return "", errors.New("unsupported mode " + strconv.Itoa(mode))
+		}
+	}
+
+	flush()
+	return text.String(), nil
+}
@@ -0,0 +1,518 @@
+package barcode
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+const (
+	// finder patterns must be seen on this many rows to be considered
+	finderQuorum = 2
+
+	// most finder patterns considered when pairing them into codes
+	maxFinders = 16
+)
+
+// finder is a candidate finder pattern, the 7x7 squares at three corners of QR codes.
+type finder struct {
+	point
+	size  float64
+	count int
+}
+
+// scanQR return the QR codes found in the matrix, any three finder patterns laid out as a code's corners are tried.
+func scanQR(matrix *bitmap) []Code {
+	candidates := make([]finder, 0)
+	for _, f := range findFinders(matrix) {
+		if f.count >= finderQuorum {
+			candidates = append(candidates, f)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].count > candidates[j].count
+	})
+	if len(candidates) > maxFinders {
+		candidates = candidates[:maxFinders]
+	}
+
+	codes := make([]Code, 0)
+	used := make([]bool, len(candidates))
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates) && !used[i]; j++ {
+			for k := j + 1; k < len(candidates) && !used[i] && !used[j]; k++ {
+				if used[k] {
+					continue
+				}
+
+				bottomLeft, topLeft, topRight := orderFinders(candidates[i], candidates[j], candidates[k])
+				if !plausible(bottomLeft, topLeft, topRight) {
+					continue
+				}
+
+				code, err := detectQR(matrix, bottomLeft, topLeft, topRight)
+				if err != nil {
+					continue
+				}
+
+				codes = append(codes, code)
+				used[i], used[j], used[k] = true, true, true
+			}
+		}
+	}
+
+	return codes
+}
+
+// findFinders scans rows for the 1:1:3:1:1 dark/light ratio of finder patterns, and confirms them vertically and
+// horizontally through their center.
+func findFinders(matrix *bitmap) []finder {
+	finders := make([]finder, 0)
+
+	step := 3 * matrix.height / (4 * 177)
+	if step < 2 {
+		step = 2
+	}
+
+	for y := step - 1; y < matrix.height; y += step {
+		var state [5]int
+		current := 0
+		for x := 0; x < matrix.width; x++ {
+			if matrix.get(x, y) {
+				if current&1 == 1 {
+					current++
+				}
+				state[current]++
+				continue
+			}
+
+			if current&1 == 1 {
+				state[current]++
+				continue
+			}
+
+			if current != 4 {
+				current++
+				state[current]++
+				continue
+			}
+
+			if finderRatio(state) && handleFinder(matrix, &finders, state, x, y) {
+				state = [5]int{}
+				current = 0
+				continue
+			}
+
+			state = [5]int{state[2], state[3], state[4], 1, 0}
+			current = 3
+		}
+
+		if finderRatio(state) {
+			handleFinder(matrix, &finders, state, matrix.width, y)
+		}
+	}
+
+	return finders
+}
+
+// finderRatio return whether run lengths are close to 1:1:3:1:1
+func finderRatio(state [5]int) bool {
+	total := 0
+	for _, count := range state {
+		if count == 0 {
+			return false
+		}
+		total += count
+	}
+	if total < 7 {
+		return false
+	}
+
+	module := float64(total) / 7
+	variance := module / 2
+	return math.Abs(module-float64(state[0])) < variance &&
+		math.Abs(module-float64(state[1])) < variance &&
+		math.Abs(3*module-float64(state[2])) < 3*variance &&
+		math.Abs(module-float64(state[3])) < variance &&
+		math.Abs(module-float64(state[4])) < variance
+}
+
+// handleFinder cross checks a possible finder pattern ending at x in row y and adds it to finders
+func handleFinder(matrix *bitmap, finders *[]finder, state [5]int, end, y int) bool {
+	total := 0
+	for _, count := range state {
+		total += count
+	}
+
+	centerX := float64(end-state[4]-state[3]) - float64(state[2])/2
+	offset, ok := crossCheck(matrix, int(centerX), y, 0, 1, state[2], total)
+	if !ok {
+		return false
+	}
+	centerY := float64(y) + offset
+
+	offset, ok = crossCheck(matrix, int(centerX), int(centerY), 1, 0, state[2], total)
+	if !ok {
+		return false
+	}
+	centerX = float64(int(centerX)) + offset
+
+	size := float64(total) / 7
+	for i, f := range *finders {
+		if math.Abs(centerY-f.y) > size || math.Abs(centerX-f.x) > size {
+			continue
+		}
+		if diff := math.Abs(size - f.size); diff > 1 && diff > f.size {
+			continue
+		}
+
+		count := float64(f.count)
+		(*finders)[i] = finder{
+			point: point{x: (count*f.x + centerX) / (count + 1), y: (count*f.y + centerY) / (count + 1)},
+			size:  (count*f.size + size) / (count + 1),
+			count: f.count + 1,
+		}
+		return true
+	}
+
+	*finders = append(*finders, finder{point: point{x: centerX, y: centerY}, size: size, count: 1})
+	return true
+}
+
+// crossCheck counts the finder pattern runs along the direction dx, dy through x, y, and return the offset of the
+// pattern center from the pixel x, y.
+func crossCheck(matrix *bitmap, x, y, dx, dy, maxCount, originalTotal int) (float64, bool) {
+	inside := func(t int) bool {
+		px, py := x+dx*t, y+dy*t
+		return px >= 0 && py >= 0 && px < matrix.width && py < matrix.height
+	}
+	dark := func(t int) bool {
+		return matrix.get(x+dx*t, y+dy*t)
+	}
+
+	var state [5]int
+	t := 0
+	for ; inside(t) && dark(t); t-- {
+		state[2]++
+	}
+	if !inside(t) {
+		return 0, false
+	}
+	for ; inside(t) && !dark(t) && state[1] <= maxCount; t-- {
+		state[1]++
+	}
+	if !inside(t) || state[1] > maxCount {
+		return 0, false
+	}
+	for ; inside(t) && dark(t) && state[0] <= maxCount; t-- {
+		state[0]++
+	}
+	if state[0] > maxCount {
+		return 0, false
+	}
+
+	t = 1
+	for ; inside(t) && dark(t); t++ {
+		state[2]++
+	}
+	if !inside(t) {
+		return 0, false
+	}
+	for ; inside(t) && !dark(t) && state[3] < maxCount; t++ {
+		state[3]++
+	}
+	if !inside(t) || state[3] >= maxCount {
+		return 0, false
+	}
+	for ; inside(t) && dark(t) && state[4] < maxCount; t++ {
+		state[4]++
+	}
+	if state[4] >= maxCount {
+		return 0, false
+	}
+
+	total := 0
+	for _, count := range state {
+		total += count
+	}
+	if 5*abs(total-originalTotal) >= 2*originalTotal || !finderRatio(state) {
+		return 0, false
+	}
+
+	return float64(t-state[4]-state[3]) - float64(state[2])/2, true
+}
+
+// orderFinders return the finders as bottom-left, top-left and top-right corners, top-left is opposite the longest
+// side, the others are ordered by orientation.
+func orderFinders(a, b, c finder) (finder, finder, finder) {
+	ab, bc, ac := distance(a.point, b.point), distance(b.point, c.point), distance(a.point, c.point)
+
+	var bottomLeft, topLeft, topRight finder
+	switch {
+	case bc >= ab && bc >= ac:
+		bottomLeft, topLeft, topRight = b, a, c
+	case ac >= ab && ac >= bc:
+		bottomLeft, topLeft, topRight = a, b, c
+	default:
+		bottomLeft, topLeft, topRight = a, c, b
+	}
+
+	cross := (topRight.x-topLeft.x)*(bottomLeft.y-topLeft.y) - (topRight.y-topLeft.y)*(bottomLeft.x-topLeft.x)
+	if cross < 0 {
+		bottomLeft, topRight = topRight, bottomLeft
+	}
+
+	return bottomLeft, topLeft, topRight
+}
+
+// plausible return whether finders are laid out as the corners of a code, similar sizes and a right isosceles
+// triangle.
+func plausible(bottomLeft, topLeft, topRight finder) bool {
+	sizes := []float64{bottomLeft.size, topLeft.size, topRight.size}
+	sort.Float64s(sizes)
+	if (sizes[2]-sizes[0])/sizes[2] > 0.5 {
+		return false
+	}
+
+	top, left := distance(topLeft.point, topRight.point), distance(topLeft.point, bottomLeft.point)
+	if math.Abs(top-left)/math.Min(top, left) > 0.25 {
+		return false
+	}
+
+	diagonal := distance(bottomLeft.point, topRight.point)
+	expected := math.Sqrt(top*top + left*left)
+	if math.Abs(diagonal-expected)/expected > 0.15 {
+		return false
+	}
+
+	modules := (top + left) / 2 / sizes[1]
+	return modules >= 8 && modules <= 180
+}
+
+// detectQR samples and decodes the code whose finder patterns are given
+func detectQR(matrix *bitmap, bottomLeft, topLeft, topRight finder) (Code, error) {
+	moduleSize := (moduleSizeBetween(matrix, topLeft.point, topRight.point) +
+		moduleSizeBetween(matrix, topLeft.point, bottomLeft.point)) / 2
+	if math.IsNaN(moduleSize) || moduleSize < 1 {
+		moduleSize = (bottomLeft.size + topLeft.size + topRight.size) / 3
+	}
+
+	top := int(math.Round(distance(topLeft.point, topRight.point) / moduleSize))
+	left := int(math.Round(distance(topLeft.point, bottomLeft.point) / moduleSize))
+	dimension := (top+left)/2 + 7
+	switch dimension & 3 {
+	case 0:
+		dimension++
+	case 2:
+		dimension--
+	case 3:
+		dimension += 2
+	}
+
+	err := errors.New("no QR code found")
+	for _, candidate := range []int{dimension, dimension - 4, dimension + 4} {
+		if candidate < 21 || candidate > 177 {
+			continue
+		}
+
+		grid, corners, e := sampleQR(matrix, bottomLeft, topLeft, topRight, moduleSize, candidate)
+		if e != nil {
+			err = e
+			continue
+		}
+
+		// trust version information over the estimated dimension
+		if version, e := grid.version(); e == nil && 17+4*version != candidate {
+			grid, corners, e = sampleQR(matrix, bottomLeft, topLeft, topRight, moduleSize, 17+4*version)
+			if e != nil {
+				err = e
+				continue
+			}
+		}
+
+		text, e := grid.decode()
+		if e != nil {
+			err = e
+			continue
+		}
+
+		return Code{Format: QR, Text: text, Points: corners}, nil
+	}
+
+	return Code{}, err
+}
+
+// sampleQR return the modules of a code of dimension modules and its corners
+func sampleQR(matrix *bitmap, bottomLeft, topLeft, topRight finder, moduleSize float64, dimension int) (qrGrid, []image.Point, error) {
+	size := float64(dimension)
+	bottomRight := point{x: topRight.x - topLeft.x + bottomLeft.x, y: topRight.y - topLeft.y + bottomLeft.y}
+	sourceBottomRight := size - 3.5
+
+	if (dimension-17)/4 >= 2 {
+		correction := 1 - 3/(size-7)
+		estimate := point{
+			x: topLeft.x + correction*(bottomRight.x-topLeft.x),
+			y: topLeft.y + correction*(bottomRight.y-topLeft.y),
+		}
+		u := point{x: (topRight.x - topLeft.x) / (size - 7), y: (topRight.y - topLeft.y) / (size - 7)}
+		v := point{x: (bottomLeft.x - topLeft.x) / (size - 7), y: (bottomLeft.y - topLeft.y) / (size - 7)}
+		for allowance := 4.0; allowance <= 16; allowance *= 2 {
+			if alignment, ok := findAlignment(matrix, estimate, u, v, moduleSize*allowance); ok {
+				bottomRight = alignment
+				sourceBottomRight -= 3
+				break
+			}
+		}
+	}
+
+	transform := quadrilateralToQuadrilateral(
+		[4]point{{3.5, 3.5}, {size - 3.5, 3.5}, {sourceBottomRight, sourceBottomRight}, {3.5, size - 3.5}},
+		[4]point{topLeft.point, topRight.point, bottomRight, bottomLeft.point},
+	)
+
+	grid := make(qrGrid, dimension)
+	for y := range grid {
+		grid[y] = make([]bool, dimension)
+		for x := range grid[y] {
+			p := transform.transform(float64(x)+0.5, float64(y)+0.5)
+			px, py := int(math.Floor(p.x)), int(math.Floor(p.y))
+			if px < -1 || py < -1 || px > matrix.width || py > matrix.height {
+				return nil, nil, errors.New("code is out of the image")
+			}
+			grid[y][x] = matrix.get(clamp(px, 0, matrix.width-1), clamp(py, 0, matrix.height-1))
+		}
+	}
+
+	corners := make([]image.Point, 0, 4)
+	for _, corner := range []point{{0, 0}, {size, 0}, {size, size}, {0, size}} {
+		p := transform.transform(corner.x, corner.y)
+		corners = append(corners, image.Pt(int(math.Round(p.x)), int(math.Round(p.y))))
+	}
+
+	return grid, corners, nil
+}
+
+// findAlignment return the center of the alignment pattern nearest to estimate within radius, u and v are a module
+// along the code's axes.
+func findAlignment(matrix *bitmap, estimate, u, v point, radius float64) (point, bool) {
+	dark := func(x, y float64) bool {
+		px, py := int(math.Floor(x)), int(math.Floor(y))
+		if px < 0 || py < 0 || px >= matrix.width || py >= matrix.height {
+			return false
+		}
+		return matrix.get(px, py)
+	}
+
+	// a dark module inside a light ring inside a dark ring
+	pattern := func(x, y float64) bool {
+		if !dark(x, y) {
+			return false
+		}
+		mismatches := 0
+		for i := -2; i <= 2; i++ {
+			for j := -2; j <= 2; j++ {
+				ring := abs(i)
+				if abs(j) > ring {
+					ring = abs(j)
+				}
+				if ring == 0 {
+					continue
+				}
+				fi, fj := float64(i), float64(j)
+				if dark(x+fi*u.x+fj*v.x, y+fi*u.y+fj*v.y) != (ring == 2) {
+					mismatches++
+				}
+			}
+		}
+		return mismatches <= 2
+	}
+
+	matches := make([]point, 0)
+	for y := math.Floor(estimate.y-radius) + 0.5; y <= estimate.y+radius; y++ {
+		for x := math.Floor(estimate.x-radius) + 0.5; x <= estimate.x+radius; x++ {
+			if pattern(x, y) {
+				matches = append(matches, point{x: x, y: y})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return point{}, false
+	}
+
+	nearest := matches[0]
+	for _, match := range matches[1:] {
+		if distance(match, estimate) < distance(nearest, estimate) {
+			nearest = match
+		}
+	}
+
+	// center of the matches around the nearest one
+	module := math.Hypot(u.x, u.y)
+	center, count := point{}, 0.0
+	for _, match := range matches {
+		if distance(match, nearest) <= module {
+			center.x += match.x
+			center.y += match.y
+			count++
+		}
+	}
+
+	return point{x: center.x / count, y: center.y / count}, true
+}
+
+// moduleSizeBetween estimates the module size from the width of the finder patterns at a and b along the line
+// between them.
+func moduleSizeBetween(matrix *bitmap, a, b point) float64 {
+	return (finderWidth(matrix, a, b) + finderWidth(matrix, b, a)) / 14
+}
+
+// finderWidth return the width of the finder pattern centered at from, along the line to "to".
+func finderWidth(matrix *bitmap, from, to point) float64 {
+	forward := halfWidth(matrix, from, to)
+	backward := halfWidth(matrix, from, point{x: 2*from.x - to.x, y: 2*from.y - to.y})
+	switch {
+	case math.IsNaN(forward):
+		return 2 * backward
+	case math.IsNaN(backward):
+		return 2 * forward
+	default:
+		return forward + backward
+	}
+}
+
+// halfWidth walks from the center of a finder pattern toward "to" until it leaves its outer dark ring.
+func halfWidth(matrix *bitmap, from, to point) float64 {
+	length := distance(from, to)
+	if length == 0 {
+		return math.NaN()
+	}
+	ux, uy := (to.x-from.x)/length, (to.y-from.y)/length
+
+	state := 0
+	for t := 0.0; t < length; t += 0.5 {
+		x, y := int(math.Floor(from.x+ux*t)), int(math.Floor(from.y+uy*t))
+		if x < 0 || y < 0 || x >= matrix.width || y >= matrix.height {
+			break
+		}
+		if matrix.get(x, y) != (state != 1) {
+			state++
+			if state == 3 {
+				return t
+			}
+		}
+	}
+
+	return math.NaN()
+}
+
+func distance(a, b point) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
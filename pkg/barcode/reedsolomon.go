@@ -0,0 +1,256 @@
+package barcode
+
+import (
+	"errors"
+)
+
+var errUncorrectable = errors.New("too many errors to correct")
+
+// GF(256) with the QR code primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+var gfExp, gfLog = func() ([512]int, [256]int) {
+	var exp [512]int
+	var log [256]int
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = i
+		x <<= 1
+		if x >= 256 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMultiply(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfInverse(a int) int {
+	return gfExp[255-gfLog[a]]
+}
+
+// poly is a polynomial over GF(256), coefficients are ordered from the highest degree.
+type poly []int
+
+func newPoly(coefficients []int) poly {
+	for len(coefficients) > 1 && coefficients[0] == 0 {
+		coefficients = coefficients[1:]
+	}
+	return poly(coefficients)
+}
+
+// monomial return coefficient * x^degree
+func monomial(degree, coefficient int) poly {
+	if coefficient == 0 {
+		return poly{0}
+	}
+	p := make(poly, degree+1)
+	p[0] = coefficient
+	return p
+}
+
+func (p poly) degree() int {
+	return len(p) - 1
+}
+
+func (p poly) zero() bool {
+	return p[0] == 0
+}
+
+func (p poly) coefficient(degree int) int {
+	return p[len(p)-1-degree]
+}
+
+func (p poly) evaluate(a int) int {
+	if a == 0 {
+		return p.coefficient(0)
+	}
+
+	result := 0
+	for _, coefficient := range p {
+		result = gfMultiply(a, result) ^ coefficient
+	}
+	return result
+}
+
+func (p poly) add(other poly) poly {
+	if p.zero() {
+		return other
+	}
+	if other.zero() {
+		return p
+	}
+
+	small, large := p, other
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+
+	sum := make([]int, len(large))
+	offset := len(large) - len(small)
+	copy(sum, large[:offset])
+	for i := offset; i < len(large); i++ {
+		sum[i] = small[i-offset] ^ large[i]
+	}
+	return newPoly(sum)
+}
+
+func (p poly) multiply(other poly) poly {
+	if p.zero() || other.zero() {
+		return poly{0}
+	}
+
+	product := make([]int, len(p)+len(other)-1)
+	for i, a := range p {
+		for j, b := range other {
+			product[i+j] ^= gfMultiply(a, b)
+		}
+	}
+	return newPoly(product)
+}
+
+func (p poly) scale(scalar int) poly {
+	if scalar == 0 {
+		return poly{0}
+	}
+
+	product := make([]int, len(p))
+	for i, coefficient := range p {
+		product[i] = gfMultiply(coefficient, scalar)
+	}
+	return newPoly(product)
+}
+
+func (p poly) multiplyByMonomial(degree, coefficient int) poly {
+	if coefficient == 0 {
+		return poly{0}
+	}
+
+	product := make([]int, len(p)+degree)
+	for i, c := range p {
+		product[i] = gfMultiply(c, coefficient)
+	}
+	return newPoly(product)
+}
+
+// correct corrects errors of a block of data followed by ecCount error correction codewords in place.
+func correct(block []int, ecCount int) error {
+	received := newPoly(append([]int{}, block...))
+
+	syndromes := make([]int, ecCount)
+	clean := true
+	for i := 0; i < ecCount; i++ {
+		value := received.evaluate(gfExp[i])
+		syndromes[ecCount-1-i] = value
+		if value != 0 {
+			clean = false
+		}
+	}
+	if clean {
+		return nil
+	}
+
+	sigma, omega, err := euclidean(monomial(ecCount, 1), newPoly(syndromes), ecCount)
+	if err != nil {
+		return err
+	}
+
+	locations, err := errorLocations(sigma)
+	if err != nil {
+		return err
+	}
+
+	magnitudes := errorMagnitudes(omega, locations)
+	for i, location := range locations {
+		position := len(block) - 1 - gfLog[location]
+		if position < 0 {
+			return errUncorrectable
+		}
+		block[position] ^= magnitudes[i]
+	}
+
+	return nil
+}
+
+// euclidean return the error locator and evaluator polynomials.
+func euclidean(a, b poly, ecCount int) (poly, poly, error) {
+	if a.degree() < b.degree() {
+		a, b = b, a
+	}
+
+	rLast, r := a, b
+	tLast, t := poly{0}, poly{1}
+
+	for 2*r.degree() >= ecCount {
+		rLastLast, tLastLast := rLast, tLast
+		rLast, tLast = r, t
+
+		if rLast.zero() {
+			return nil, nil, errUncorrectable
+		}
+
+		r = rLastLast
+		q := poly{0}
+		inverse := gfInverse(rLast.coefficient(rLast.degree()))
+		for r.degree() >= rLast.degree() && !r.zero() {
+			diff := r.degree() - rLast.degree()
+			scale := gfMultiply(r.coefficient(r.degree()), inverse)
+			q = q.add(monomial(diff, scale))
+			r = r.add(rLast.multiplyByMonomial(diff, scale))
+		}
+
+		t = q.multiply(tLast).add(tLastLast)
+		if r.degree() >= rLast.degree() {
+			return nil, nil, errUncorrectable
+		}
+	}
+
+	sigmaAtZero := t.coefficient(0)
+	if sigmaAtZero == 0 {
+		return nil, nil, errUncorrectable
+	}
+
+	inverse := gfInverse(sigmaAtZero)
+	return t.scale(inverse), r.scale(inverse), nil
+}
+
+func errorLocations(locator poly) ([]int, error) {
+	count := locator.degree()
+	if count == 1 {
+		return []int{locator.coefficient(1)}, nil
+	}
+
+	locations := make([]int, 0, count)
+	for i := 1; i < 256 && len(locations) < count; i++ {
+		if locator.evaluate(i) == 0 {
+			locations = append(locations, gfInverse(i))
+		}
+	}
+
+	if len(locations) != count {
+		return nil, errUncorrectable
+	}
+	return locations, nil
+}
+
+func errorMagnitudes(evaluator poly, locations []int) []int {
+	magnitudes := make([]int, len(locations))
+	for i, location := range locations {
+		inverse := gfInverse(location)
+		denominator := 1
+		for j, other := range locations {
+			if i != j {
+				denominator = gfMultiply(denominator, gfMultiply(other, inverse)^1)
+			}
+		}
+		magnitudes[i] = gfMultiply(evaluator.evaluate(inverse), gfInverse(denominator))
+	}
+	return magnitudes
+}
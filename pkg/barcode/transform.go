@@ -0,0 +1,70 @@
+package barcode
+
+// point is a sub-pixel position in the image
+type point struct {
+	x, y float64
+}
+
+// perspective maps points from one quadrilateral to another
+type perspective struct {
+	a11, a21, a31, a12, a22, a32, a13, a23, a33 float64
+}
+
+// quadrilateralToQuadrilateral return the perspective mapping the corners of src to the corners of dst, corners are
+// ordered top-left, top-right, bottom-right and bottom-left.
+func quadrilateralToQuadrilateral(src, dst [4]point) perspective {
+	return squareToQuadrilateral(dst).times(squareToQuadrilateral(src).adjoint())
+}
+
+func squareToQuadrilateral(q [4]point) perspective {
+	dx3 := q[0].x - q[1].x + q[2].x - q[3].x
+	dy3 := q[0].y - q[1].y + q[2].y - q[3].y
+	if dx3 == 0 && dy3 == 0 {
+		return perspective{
+			q[1].x - q[0].x, q[2].x - q[1].x, q[0].x,
+			q[1].y - q[0].y, q[2].y - q[1].y, q[0].y,
+			0, 0, 1,
+		}
+	}
+
+	dx1, dx2 := q[1].x-q[2].x, q[3].x-q[2].x
+	dy1, dy2 := q[1].y-q[2].y, q[3].y-q[2].y
+	denominator := dx1*dy2 - dx2*dy1
+	a13 := (dx3*dy2 - dx2*dy3) / denominator
+	a23 := (dx1*dy3 - dx3*dy1) / denominator
+	return perspective{
+		q[1].x - q[0].x + a13*q[1].x, q[3].x - q[0].x + a23*q[3].x, q[0].x,
+		q[1].y - q[0].y + a13*q[1].y, q[3].y - q[0].y + a23*q[3].y, q[0].y,
+		a13, a23, 1,
+	}
+}
+
+func (p perspective) adjoint() perspective {
+	return perspective{
+		p.a22*p.a33 - p.a23*p.a32, p.a23*p.a31 - p.a21*p.a33, p.a21*p.a32 - p.a22*p.a31,
+		p.a13*p.a32 - p.a12*p.a33, p.a11*p.a33 - p.a13*p.a31, p.a12*p.a31 - p.a11*p.a32,
+		p.a12*p.a23 - p.a13*p.a22, p.a13*p.a21 - p.a11*p.a23, p.a11*p.a22 - p.a12*p.a21,
+	}
+}
+
+func (p perspective) times(o perspective) perspective {
+	return perspective{
+		p.a11*o.a11 + p.a21*o.a12 + p.a31*o.a13,
+		p.a11*o.a21 + p.a21*o.a22 + p.a31*o.a23,
+		p.a11*o.a31 + p.a21*o.a32 + p.a31*o.a33,
+		p.a12*o.a11 + p.a22*o.a12 + p.a32*o.a13,
+		p.a12*o.a21 + p.a22*o.a22 + p.a32*o.a23,
+		p.a12*o.a31 + p.a22*o.a32 + p.a32*o.a33,
+		p.a13*o.a11 + p.a23*o.a12 + p.a33*o.a13,
+		p.a13*o.a21 + p.a23*o.a22 + p.a33*o.a23,
+		p.a13*o.a31 + p.a23*o.a32 + p.a33*o.a33,
+	}
+}
+
+func (p perspective) transform(x, y float64) point {
+	denominator := p.a13*x + p.a23*y + p.a33
+	return point{
+		x: (p.a11*x + p.a21*y + p.a31) / denominator,
+		y: (p.a12*x + p.a22*y + p.a32) / denominator,
+	}
+}
@@ -0,0 +1,145 @@
+package colors
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// blurhash is computed on a thumbnail up to this many pixels per dimension
+const blurhashSize = 64
+
+// BlurHash encodes the image as a BlurHash (https://blurha.sh) with x by y components (1 to 9 each).
+func BlurHash(img image.Image, x, y int) (string, error) {
+	if x < 1 || x > 9 || y < 1 || y > 9 {
+		return "", errors.New("blurhash components must be between 1 and 9")
+	}
+
+	pixels, width, height := linearPixels(img)
+	if width == 0 || height == 0 {
+		return "", errors.New("empty image")
+	}
+
+	factors := make([][3]float64, 0, x*y)
+	for j := 0; j < y; j++ {
+		for i := 0; i < x; i++ {
+			factors = append(factors, basis(pixels, width, height, i, j))
+		}
+	}
+
+	hash := &strings.Builder{}
+	encode83(hash, (x-1)+(y-1)*9, 1)
+
+	maximum := 1.0
+	if len(factors) > 1 {
+		actual := 0.0
+		for _, factor := range factors[1:] {
+			for _, value := range factor {
+				actual = math.Max(actual, math.Abs(value))
+			}
+		}
+
+		quantised := int(math.Max(0, math.Min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+		encode83(hash, quantised, 1)
+	} else {
+		encode83(hash, 0, 1)
+	}
+
+	dc := factors[0]
+	encode83(hash, linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4)
+
+	for _, factor := range factors[1:] {
+		value := 0
+		for _, channel := range factor {
+			quantised := int(math.Max(0, math.Min(18, math.Floor(signPow(channel/maximum, 0.5)*9+9.5))))
+			value = value*19 + quantised
+		}
+		encode83(hash, value, 2)
+	}
+
+	return hash.String(), nil
+}
+
+// basis return the cosine transform factor of component (i, j)
+func basis(pixels [][3]float64, width, height, i, j int) [3]float64 {
+	var factor [3]float64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			weight := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+				math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+			for c := 0; c < 3; c++ {
+				factor[c] += weight * pixels[y*width+x][c]
+			}
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1
+	}
+
+	scale := normalisation / float64(width*height)
+	for c := 0; c < 3; c++ {
+		factor[c] *= scale
+	}
+
+	return factor
+}
+
+// linearPixels return linear RGB pixels of a nearest neighbour thumbnail of the image
+func linearPixels(img image.Image) ([][3]float64, int, int) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > blurhashSize || height > blurhashSize {
+		scale := math.Max(float64(width), float64(height)) / blurhashSize
+		width, height = int(float64(width)/scale), int(float64(height)/scale)
+	}
+	if width < 1 || height < 1 {
+		return nil, 0, 0
+	}
+
+	pixels := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(
+				bounds.Min.X+x*bounds.Dx()/width,
+				bounds.Min.Y+y*bounds.Dy()/height,
+			)).(color.NRGBA)
+			pixels[y*width+x] = [3]float64{sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)}
+		}
+	}
+
+	return pixels, width, height
+}
+
+func encode83(hash *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		hash.WriteByte(base83[digit])
+	}
+}
+
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
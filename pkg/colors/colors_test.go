@@ -0,0 +1,101 @@
+package colors
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+	gray = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+)
+
+// split returns an image with its left quarter filled with left and the rest with right
+func split(left, right color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	draw.Draw(img, img.Bounds(), image.NewUniform(right), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, 16, 32), image.NewUniform(left), image.Point{}, draw.Src)
+	return img
+}
+
+func TestPalette(t *testing.T) {
+	threeColors := split(red, blue)
+	draw.Draw(threeColors, image.Rect(16, 0, 32, 32), image.NewUniform(gray), image.Point{}, draw.Src)
+
+	tests := []struct {
+		name string
+		img  image.Image
+		n    int
+		want []color.RGBA
+	}{
+		{name: "most used first", img: split(red, blue), n: 2, want: []color.RGBA{blue, red}},
+		{name: "fewer colours than n", img: split(red, blue), n: 5, want: []color.RGBA{blue, red}},
+		{name: "three colours", img: threeColors, n: 3, want: []color.RGBA{blue, red, gray}},
+		{name: "merged", img: split(red, blue), n: 1, want: []color.RGBA{{R: 64, B: 191, A: 255}}},
+		{name: "transparent pixels are ignored", img: split(color.Transparent, red), n: 2, want: []color.RGBA{red}},
+		{name: "transparent", img: image.NewRGBA(image.Rect(0, 0, 8, 8)), n: 2, want: []color.RGBA{}},
+		{name: "none", img: split(red, blue), n: 0, want: []color.RGBA{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Palette(tt.img, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Palette() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDominant(t *testing.T) {
+	got, ok := Dominant(split(red, gray))
+	if !ok || got != gray || Hex(got) != "#808080" {
+		t.Errorf("Dominant() = %v (%s), %v, want %v", got, Hex(got), ok, gray)
+	}
+
+	if _, ok := Dominant(image.NewRGBA(image.Rect(0, 0, 8, 8))); ok {
+		t.Error("Dominant() of a transparent image ok = true, want false")
+	}
+}
+
+func TestBlurHash(t *testing.T) {
+	gradient := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			gradient.Set(x, y, color.RGBA{R: uint8(x * 255 / 7), G: uint8(y * 255 / 5), B: 128, A: 255})
+		}
+	}
+
+	solid := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	draw.Draw(solid, solid.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+
+	tests := []struct {
+		name string
+		img  image.Image
+		x, y int
+		want string
+		err  bool
+	}{
+		// expected hashes are from the reference encoder
+		{name: "solid", img: solid, x: 1, y: 1, want: "00TI:j"},
+		{name: "gradient", img: gradient, x: 4, y: 3, want: "LyI5er3AfQxtz4NKfQnSeXf7fQf7"},
+		{name: "too many components", img: solid, x: 10, y: 3, err: true},
+		{name: "no components", img: solid, x: 0, y: 3, err: true},
+		{name: "empty", img: image.NewRGBA(image.Rect(0, 0, 0, 0)), x: 4, y: 3, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BlurHash(tt.img, tt.x, tt.y)
+			if (err != nil) != tt.err {
+				t.Fatalf("BlurHash() error = %v, want error %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("BlurHash() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package colors extracts the dominant colours, palette and BlurHash placeholder of an image.
+package colors
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// images are sampled up to this many pixels per dimension
+const sampleSize = 128
+
+// refinement iterations of median cut clusters
+const iterations = 5
+
+// Hex formats a color as #rrggbb
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+type cluster struct {
+	center [3]float64
+	count  int
+}
+
+// Palette return up to n colours of the image ordered from the most to least used, transparent pixels are ignored.
+// Colours are found by median cut, then refined with k-means.
+func Palette(img image.Image, n int) []color.RGBA {
+	pixels := sample(img)
+	if len(pixels) == 0 || n <= 0 {
+		return []color.RGBA{}
+	}
+
+	clusters := medianCut(pixels, n)
+
+	assignments := make([]int, len(pixels))
+	for i := 0; i < iterations; i++ {
+		sums := make([][3]float64, len(clusters))
+		for c := range clusters {
+			clusters[c].count = 0
+		}
+
+		for p, pixel := range pixels {
+			nearest := nearest(clusters, pixel)
+			assignments[p] = nearest
+			clusters[nearest].count++
+			for channel := 0; channel < 3; channel++ {
+				sums[nearest][channel] += pixel[channel]
+			}
+		}
+
+		for c := range clusters {
+			if clusters[c].count == 0 {
+				continue
+			}
+			for channel := 0; channel < 3; channel++ {
+				clusters[c].center[channel] = sums[c][channel] / float64(clusters[c].count)
+			}
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].count > clusters[j].count
+	})
+
+	palette := make([]color.RGBA, 0, len(clusters))
+	for _, c := range clusters {
+		if c.count == 0 {
+			continue
+		}
+		palette = append(palette, color.RGBA{
+			R: uint8(c.center[0] + 0.5),
+			G: uint8(c.center[1] + 0.5),
+			B: uint8(c.center[2] + 0.5),
+			A: 255,
+		})
+	}
+
+	return palette
+}
+
+// Dominant return the most used colour of the image, ok is false if the image is fully transparent.
+func Dominant(img image.Image) (color.RGBA, bool) {
+	palette := Palette(img, 5)
+	if len(palette) == 0 {
+		return color.RGBA{}, false
+	}
+	return palette[0], true
+}
+
+// sample return RGB of opaque pixels of a grid over the image
+func sample(img image.Image) [][3]float64 {
+	bounds := img.Bounds()
+	stepX, stepY := bounds.Dx()/sampleSize+1, bounds.Dy()/sampleSize+1
+
+	pixels := make([][3]float64, 0, (bounds.Dx()/stepX+1)*(bounds.Dy()/stepY+1))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			pixels = append(pixels, [3]float64{float64(c.R), float64(c.G), float64(c.B)})
+		}
+	}
+
+	return pixels
+}
+
+// medianCut splits pixels into n boxes, always splitting the box with the widest channel range at its median.
+func medianCut(pixels [][3]float64, n int) []cluster {
+	boxes := [][][3]float64{pixels}
+
+	for len(boxes) < n {
+		widest, channel, best := -1, 0, 0.0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				min, max := box[0][c], box[0][c]
+				for _, pixel := range box {
+					if pixel[c] < min {
+						min = pixel[c]
+					}
+					if pixel[c] > max {
+						max = pixel[c]
+					}
+				}
+				if max-min > best {
+					widest, channel, best = i, c, max-min
+				}
+			}
+		}
+
+		// no box can be split anymore
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		sort.Slice(box, func(i, j int) bool {
+			return box[i][channel] < box[j][channel]
+		})
+
+		median := len(box) / 2
+		boxes[widest] = box[:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	clusters := make([]cluster, len(boxes))
+	for i, box := range boxes {
+		for _, pixel := range box {
+			for c := 0; c < 3; c++ {
+				clusters[i].center[c] += pixel[c]
+			}
+		}
+		for c := 0; c < 3; c++ {
+			clusters[i].center[c] /= float64(len(box))
+		}
+	}
+
+	return clusters
+}
+
+func nearest(clusters []cluster, pixel [3]float64) int {
+	nearest, distance := 0, -1.0
+	for i, c := range clusters {
+		d := 0.0
+		for channel := 0; channel < 3; channel++ {
+			diff := c.center[channel] - pixel[channel]
+			d += diff * diff
+		}
+		if distance < 0 || d < distance {
+			nearest, distance = i, d
+		}
+	}
+	return nearest
+}
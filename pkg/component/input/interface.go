@@ -14,3 +14,16 @@ type Input interface {
 
 	component.Base
 }
+
+// Finite is an Input that runs out of jobs (e.g reading from standard input).
+type Finite interface {
+	// Done returns a channel that is closed once this input has no
+	// more jobs to send and all its sent jobs are done.
+	Done() <-chan struct{}
+
+	// Err returns why some of the jobs sent by this input failed or weren't
+	// acknowledged, nil if all of them were. valid once Done is closed.
+	Err() error
+
+	Input
+}
@@ -70,3 +70,9 @@ type ProcessReadWrite interface {
 type ProcessReadOnly interface {
 	Process(in payload.DecodedImage, data payload.Data) response.Response
 }
+
+//Completer A read-only base component notified of the final response of jobs it acknowledged, once every next
+//node responded (e.g to keep state only for jobs that succeeded).
+type Completer interface {
+	Complete(data payload.Data, response response.Response)
+}
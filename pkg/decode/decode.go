@@ -0,0 +1,42 @@
+// Package decode decodes images with the Go decoders of the formats read-only processors accept (JPEG, PNG, GIF, WebP
+// and TIFF).
+package decode
+
+import (
+	"fmt"
+	"image"
+	// register GIF to decode function
+	_ "image/gif"
+	// register JPEG to decode function
+	_ "image/jpeg"
+	// register PNG to decode function
+	_ "image/png"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+	"github.com/sherifabdlnaby/prism/pkg/response"
+
+	// register tiff to decode function
+	_ "golang.org/x/image/tiff"
+	// register webp to decode function
+	_ "golang.org/x/image/webp"
+)
+
+// Image decodes the image of a stream, input that can't be decoded is not acknowledged as retrying it won't help.
+func Image(in payload.Stream) (image.Image, response.Response) {
+	img, _, err := image.Decode(in)
+	if err != nil {
+		return nil, response.NoAck(fmt.Errorf("unsupported format: %s", err.Error()))
+	}
+
+	return img, response.Ack()
+}
+
+// Config decodes the config and format of the image of a stream, input that can't be decoded is not acknowledged.
+func Config(in payload.Stream) (image.Config, string, response.Response) {
+	config, format, err := image.DecodeConfig(in)
+	if err != nil {
+		return image.Config{}, "", response.NoAck(fmt.Errorf("unsupported format: %s", err.Error()))
+	}
+
+	return config, format, response.Ack()
+}
@@ -0,0 +1,58 @@
+package decode
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	if err := png.Encode(encoded, image.NewGray(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		ack  bool
+	}{
+		{name: "png", in: encoded.Bytes(), ack: true},
+		{name: "truncated png", in: encoded.Bytes()[:20]},
+		{name: "unknown format", in: []byte("not an image")},
+		{name: "empty", in: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, Response := Image(bytes.NewReader(tt.in))
+			if Response.Error != nil {
+				t.Fatalf("Error = %v, want nil", Response.Error)
+			}
+			if Response.Ack != tt.ack || (Response.AckErr != nil) == tt.ack {
+				t.Fatalf("Ack = %v, AckErr = %v, want Ack %v", Response.Ack, Response.AckErr, tt.ack)
+			}
+			if tt.ack && img.Bounds() != image.Rect(0, 0, 3, 2) {
+				t.Errorf("bounds = %v, want 3x2", img.Bounds())
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	if err := png.Encode(encoded, image.NewGray(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	config, format, Response := Config(bytes.NewReader(encoded.Bytes()))
+	if !Response.Ack || config.Width != 3 || config.Height != 2 || format != "png" {
+		t.Errorf("Config() = %v, %q, %v, want 3x2 png acknowledged", config, format, Response)
+	}
+
+	_, _, Response = Config(bytes.NewReader([]byte("not an image")))
+	if Response.Ack || Response.AckErr == nil || Response.Error != nil {
+		t.Errorf("Config() of an unknown format = %v, want not acknowledged", Response)
+	}
+}
@@ -0,0 +1,71 @@
+// Package digest computes content digests of payloads, used to address images by their content.
+package digest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/sherifabdlnaby/prism/pkg/payload"
+)
+
+// Field is the payload.Data key under which a payload's SHA-256 digest is reported.
+const Field = "_sha256"
+
+// Reader wraps a reader and computes the SHA-256 digest of everything read through it.
+type Reader struct {
+	reader io.Reader
+	hash   hash.Hash
+}
+
+// NewReader Returns a new Reader that hashes the bytes read from reader
+func NewReader(reader io.Reader) *Reader {
+	return &Reader{
+		reader: reader,
+		hash:   sha256.New(),
+	}
+}
+
+// Read reads from the underlying reader and adds the read bytes to the digest.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	if n > 0 {
+		_, _ = r.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// Sum returns the hex encoded digest of all bytes read so far.
+func (r *Reader) Sum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
+
+// Bytes returns the hex encoded SHA-256 digest of buffer.
+func Bytes(buffer []byte) string {
+	sum := sha256.Sum256(buffer)
+	return hex.EncodeToString(sum[:])
+}
+
+// From returns the digest in data, or the digest reported by its collected outputs if they all agree on one.
+func From(data map[string]interface{}) (string, bool) {
+	if sum, ok := data[Field].(string); ok {
+		return sum, true
+	}
+
+	outputs, _ := data[payload.OutputsField].(map[string]interface{})
+	found := ""
+	for _, output := range outputs {
+		output, _ := output.(map[string]interface{})
+		sum, ok := output[Field].(string)
+		if !ok {
+			continue
+		}
+		if found != "" && found != sum {
+			return "", false
+		}
+		found = sum
+	}
+
+	return found, found != ""
+}
@@ -0,0 +1,210 @@
+package focus
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+// detection parameters
+const (
+	shiftFactor  = 0.1
+	scaleFactor  = 1.1
+	iouThreshold = 0.2
+	minFaceSize  = 20
+	// minimum score of clustered detections to be considered a face
+	minScore = 5.0
+)
+
+var errInvalidCascade = errors.New("invalid cascade file")
+
+// Cascade is a pixel intensity comparison based object detector (pico), it loads cascade files in the format used by
+// pigo (e.g its "facefinder" face detection cascade).
+type Cascade struct {
+	depth       int
+	trees       int
+	codes       []int8
+	predictions []float32
+	thresholds  []float32
+}
+
+// Face is a detected face, centered at (X, Y) with Size width and height, Score is the detection confidence.
+type Face struct {
+	X, Y, Size int
+	Score      float32
+}
+
+// LoadCascade decodes a cascade file
+func LoadCascade(buf []byte) (*Cascade, error) {
+	// skip version and input size
+	pos := 8
+	if len(buf) < pos+8 {
+		return nil, errInvalidCascade
+	}
+
+	c := &Cascade{
+		depth: int(binary.LittleEndian.Uint32(buf[pos:])),
+		trees: int(binary.LittleEndian.Uint32(buf[pos+4:])),
+	}
+	pos += 8
+
+	if c.depth <= 0 || c.depth > 16 || c.trees <= 0 {
+		return nil, errInvalidCascade
+	}
+
+	leaves := 1 << uint(c.depth)
+	for t := 0; t < c.trees; t++ {
+		if pos+4*(leaves-1)+4*leaves+4 > len(buf) {
+			return nil, errInvalidCascade
+		}
+
+		// index 0 is unused, nodes are indexed from 1
+		c.codes = append(c.codes, 0, 0, 0, 0)
+		for _, code := range buf[pos : pos+4*(leaves-1)] {
+			c.codes = append(c.codes, int8(code))
+		}
+		pos += 4 * (leaves - 1)
+
+		for i := 0; i < leaves; i++ {
+			c.predictions = append(c.predictions, math.Float32frombits(binary.LittleEndian.Uint32(buf[pos:])))
+			pos += 4
+		}
+
+		c.thresholds = append(c.thresholds, math.Float32frombits(binary.LittleEndian.Uint32(buf[pos:])))
+		pos += 4
+	}
+
+	return c, nil
+}
+
+// Detect returns faces found in the image sorted by score, faces are clustered so each face is reported once.
+func (c *Cascade) Detect(gray *image.Gray) []Face {
+	bounds := gray.Bounds()
+	rows, columns := bounds.Dy(), bounds.Dx()
+
+	maxSize := rows
+	if columns < maxSize {
+		maxSize = columns
+	}
+
+	detections := make([]Face, 0)
+	for size := minFaceSize; size <= maxSize; size = int(float64(size) * scaleFactor) {
+		step := int(math.Max(shiftFactor*float64(size), 1))
+		offset := size/2 + 1
+
+		for row := offset; row <= rows-offset; row += step {
+			for column := offset; column <= columns-offset; column += step {
+				score := c.classify(row, column, size, gray)
+				if score > 0 {
+					detections = append(detections, Face{X: column, Y: row, Size: size, Score: score})
+				}
+			}
+		}
+	}
+
+	faces := make([]Face, 0)
+	for _, face := range cluster(detections) {
+		if face.Score > minScore {
+			face.X += bounds.Min.X
+			face.Y += bounds.Min.Y
+			faces = append(faces, face)
+		}
+	}
+
+	return faces
+}
+
+// classify runs the cascade on a square region, returns a positive score if it's a detection.
+func (c *Cascade) classify(row, column, size int, gray *image.Gray) float32 {
+	leaves := 1 << uint(c.depth)
+	root := 0
+	score := float32(0)
+
+	row, column = row*256, column*256
+	for t := 0; t < c.trees; t++ {
+		index := 1
+		for d := 0; d < c.depth; d++ {
+			code := c.codes[root+4*index:]
+			x1 := gray.Pix[((row+int(code[0])*size)>>8)*gray.Stride+((column+int(code[1])*size)>>8)]
+			x2 := gray.Pix[((row+int(code[2])*size)>>8)*gray.Stride+((column+int(code[3])*size)>>8)]
+
+			index *= 2
+			if x1 <= x2 {
+				index++
+			}
+		}
+
+		score += c.predictions[leaves*t+index-leaves]
+		if score <= c.thresholds[t] {
+			return -1
+		}
+		root += 4 * leaves
+	}
+
+	return score - c.thresholds[c.trees-1]
+}
+
+// cluster merges overlapping detections into their average, scores are summed.
+func cluster(detections []Face) []Face {
+	sort.Slice(detections, func(i, j int) bool {
+		return detections[i].Score > detections[j].Score
+	})
+
+	assigned := make([]bool, len(detections))
+	clusters := make([]Face, 0)
+
+	for i := range detections {
+		if assigned[i] {
+			continue
+		}
+
+		var x, y, size, n int
+		var score float32
+		for j := range detections {
+			if iou(detections[i], detections[j]) > iouThreshold {
+				assigned[j] = true
+				x += detections[j].X
+				y += detections[j].Y
+				size += detections[j].Size
+				score += detections[j].Score
+				n++
+			}
+		}
+
+		if n > 0 {
+			clusters = append(clusters, Face{X: x / n, Y: y / n, Size: size / n, Score: score})
+		}
+	}
+
+	return clusters
+}
+
+// iou is the intersection over union of two detections
+func iou(a, b Face) float64 {
+	overlapX := math.Max(0, math.Min(float64(a.X+a.Size/2), float64(b.X+b.Size/2))-
+		math.Max(float64(a.X-a.Size/2), float64(b.X-b.Size/2)))
+	overlapY := math.Max(0, math.Min(float64(a.Y+a.Size/2), float64(b.Y+b.Size/2))-
+		math.Max(float64(a.Y-a.Size/2), float64(b.Y-b.Size/2)))
+
+	overlap := overlapX * overlapY
+	return overlap / (float64(a.Size*a.Size+b.Size*b.Size) - overlap)
+}
+
+// Faces return the center of detected faces weighted by their size, ok is false if there are none.
+func Faces(faces []Face, width, height int) (Point, bool) {
+	if len(faces) == 0 || width <= 0 || height <= 0 {
+		return Point{}, false
+	}
+
+	var x, y, total float64
+	for _, face := range faces {
+		weight := float64(face.Size * face.Size)
+		x += float64(face.X) * weight
+		y += float64(face.Y) * weight
+		total += weight
+	}
+
+	return Point{X: x / total / float64(width), Y: y / total / float64(height)}, true
+}
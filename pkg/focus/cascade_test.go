@@ -0,0 +1,107 @@
+package focus
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLoadCascade(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		ok   bool
+	}{
+		{name: "facefinder", buf: facefinder, ok: true},
+		{name: "empty", buf: nil},
+		{name: "header only", buf: facefinder[:16]},
+		{name: "truncated tree", buf: facefinder[:len(facefinder)-1]},
+		{name: "invalid depth", buf: append([]byte{0, 0, 0, 0, 0, 0, 0, 0, 32, 0, 0, 0, 1, 0, 0, 0}, facefinder[16:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCascade(tt.buf)
+			if (err == nil) != tt.ok {
+				t.Errorf("LoadCascade() error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		faces []Face
+	}{
+		{name: "no faces"},
+		{name: "face", faces: []Face{{X: 90, Y: 80, Size: 60}}},
+		{name: "small face", faces: []Face{{X: 200, Y: 60, Size: 32}}},
+		{name: "two faces", faces: []Face{{X: 70, Y: 90, Size: 56}, {X: 200, Y: 80, Size: 64}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gray := image.NewGray(image.Rect(0, 0, 280, 180))
+			for y := 0; y < 180; y++ {
+				for x := 0; x < 280; x++ {
+					gray.Pix[y*gray.Stride+x] = uint8(100 + x/4)
+				}
+			}
+			for _, face := range tt.faces {
+				drawFace(gray, face)
+			}
+
+			// sensor noise, flat regions don't compare as they do in photos
+			rng := rand.New(rand.NewSource(1))
+			for i, value := range gray.Pix {
+				gray.Pix[i] = uint8(math.Max(0, math.Min(255, float64(value)+6*rng.NormFloat64())))
+			}
+
+			got := DefaultCascade().Detect(gray)
+			if len(got) != len(tt.faces) {
+				t.Fatalf("Detect() = %+v, want %d faces", got, len(tt.faces))
+			}
+
+			for _, want := range tt.faces {
+				found := false
+				for _, face := range got {
+					found = found || math.Abs(float64(face.X-want.X)) < float64(want.Size)/4 &&
+						math.Abs(float64(face.Y-want.Y)) < float64(want.Size)/4 &&
+						math.Abs(float64(face.Size-want.Size)) < float64(want.Size)/3
+				}
+				if !found {
+					t.Errorf("Detect() = %+v, want a face at %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+// drawFace draws a face with hair, shaded eye sockets, dark eyes, brows and mouth
+func drawFace(gray *image.Gray, face Face) {
+	s := float64(face.Size)
+	fill := func(cx, cy, rx, ry float64, value uint8) {
+		for y := int(cy - ry); y <= int(cy+ry); y++ {
+			for x := int(cx - rx); x <= int(cx+rx); x++ {
+				dx, dy := (float64(x)-cx)/rx, (float64(y)-cy)/ry
+				if dx*dx+dy*dy <= 1 {
+					gray.Pix[y*gray.Stride+x] = value
+				}
+			}
+		}
+	}
+
+	x, y := float64(face.X), float64(face.Y)
+	fill(x, y-0.05*s, 0.48*s, 0.62*s, 40)
+	fill(x, y+0.02*s, 0.47*s, 0.56*s, 130)
+	fill(x, y+0.02*s, 0.4*s, 0.52*s, 190)
+	for _, side := range []float64{-1, 1} {
+		fill(x+side*0.17*s, y-0.1*s, 0.13*s, 0.08*s, 160)
+		fill(x+side*0.17*s, y-0.22*s, 0.09*s, 0.02*s, 90)
+		fill(x+side*0.17*s, y-0.1*s, 0.065*s, 0.035*s, 50)
+	}
+	fill(x, y+0.14*s, 0.08*s, 0.03*s, 140)
+	fill(x, y+0.27*s, 0.13*s, 0.035*s, 110)
+}
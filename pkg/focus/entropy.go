@@ -0,0 +1,77 @@
+package focus
+
+import (
+	"image"
+	"math"
+)
+
+// number of cells along the shorter side of the image used to measure entropy
+const entropyCells = 16
+
+// Entropy return the center of the most detailed area of the image, measured as the 3x3 cells window with the highest
+// sum of grayscale histogram entropy.
+func Entropy(gray *image.Gray) Point {
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	cell := int(math.Min(float64(width), float64(height))) / entropyCells
+	if cell < 4 {
+		return Center
+	}
+
+	columns, rows := width/cell, height/cell
+
+	entropies := make([][]float64, rows)
+	for row := range entropies {
+		entropies[row] = make([]float64, columns)
+		for column := range entropies[row] {
+			x, y := bounds.Min.X+column*cell, bounds.Min.Y+row*cell
+			entropies[row][column] = entropy(gray, image.Rect(x, y, x+cell, y+cell))
+		}
+	}
+
+	best, point := -1.0, Center
+	for row := 0; row+3 <= rows; row++ {
+		for column := 0; column+3 <= columns; column++ {
+			sum := 0.0
+			for i := row; i < row+3; i++ {
+				for j := column; j < column+3; j++ {
+					sum += entropies[i][j]
+				}
+			}
+
+			if sum > best {
+				best = sum
+				point = Point{
+					X: (float64(column) + 1.5) * float64(cell) / float64(width),
+					Y: (float64(row) + 1.5) * float64(cell) / float64(height),
+				}
+			}
+		}
+	}
+
+	return point
+}
+
+// entropy of a 32 bins histogram of the region
+func entropy(gray *image.Gray, region image.Rectangle) float64 {
+	var histogram [32]int
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		row := gray.Pix[gray.PixOffset(region.Min.X, y):gray.PixOffset(region.Max.X, y)]
+		for _, value := range row {
+			histogram[value>>3]++
+		}
+	}
+
+	total := float64(region.Dx() * region.Dy())
+	result := 0.0
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / total
+		result -= p * math.Log2(p)
+	}
+
+	return result
+}
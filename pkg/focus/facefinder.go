@@ -0,0 +1,29 @@
+package focus
+
+import (
+	// embeds the built-in cascade
+	_ "embed"
+)
+
+//go:generate go run gen.go -o facefinder
+
+// facefinder is a small face detection cascade trained by gen.go on rendered faces, a cascade trained on photos as
+// pigo's facefinder detects faces better.
+//
+//go:embed facefinder
+var facefinder []byte
+
+var defaultCascade *Cascade
+
+func init() {
+	var err error
+	defaultCascade, err = LoadCascade(facefinder)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DefaultCascade return the built-in face detection cascade, cascades are safe for concurrent use.
+func DefaultCascade() *Cascade {
+	return defaultCascade
+}
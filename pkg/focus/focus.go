@@ -0,0 +1,109 @@
+// Package focus finds the point of interest of an image (faces or the most detailed region), used to crop around it.
+package focus
+
+import (
+	"image"
+	"math"
+	"strconv"
+)
+
+// Fields are the payload.Data keys clients use to supply their own focal point, relative to the image size (0 to 1).
+const (
+	XField = "focal_x"
+	YField = "focal_y"
+)
+
+// Point is a focal point relative to image size, (0.5, 0.5) is the center.
+type Point struct {
+	X, Y float64
+}
+
+// Center of the image
+var Center = Point{X: 0.5, Y: 0.5}
+
+// FromData return the focal point supplied in data, ok is false if missing or invalid.
+func FromData(data map[string]interface{}) (Point, bool) {
+	x, ok := number(data[XField])
+	if !ok {
+		return Point{}, false
+	}
+
+	y, ok := number(data[YField])
+	if !ok {
+		return Point{}, false
+	}
+
+	if x < 0 || x > 1 || y < 0 || y > 1 {
+		return Point{}, false
+	}
+
+	return Point{X: x, Y: y}, true
+}
+
+// number accepts numbers, or strings as sent by form inputs.
+func number(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case string:
+		number, err := strconv.ParseFloat(value, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Region return the largest region of the image with the aspect ratio of width x height, centered around the focal
+// point as much as the image bounds allow.
+func Region(imageWidth, imageHeight, width, height int, point Point) image.Rectangle {
+	if width <= 0 || height <= 0 || imageWidth <= 0 || imageHeight <= 0 {
+		return image.Rect(0, 0, imageWidth, imageHeight)
+	}
+
+	aspect := float64(width) / float64(height)
+
+	regionWidth, regionHeight := imageWidth, imageHeight
+	if float64(imageWidth)/float64(imageHeight) > aspect {
+		regionWidth = int(math.Round(float64(imageHeight) * aspect))
+	} else {
+		regionHeight = int(math.Round(float64(imageWidth) / aspect))
+	}
+
+	left := clamp(int(math.Round(point.X*float64(imageWidth)))-regionWidth/2, 0, imageWidth-regionWidth)
+	top := clamp(int(math.Round(point.Y*float64(imageHeight)))-regionHeight/2, 0, imageHeight-regionHeight)
+
+	return image.Rect(left, top, left+regionWidth, top+regionHeight)
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// Gray converts an image to grayscale
+func Gray(img image.Image) *image.Gray {
+	if gray, ok := img.(*image.Gray); ok {
+		return gray
+	}
+
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x-bounds.Min.X, y-bounds.Min.Y, img.At(x, y))
+		}
+	}
+
+	return gray
+}
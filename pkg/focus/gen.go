@@ -0,0 +1,502 @@
+//go:build ignore
+
+// gen trains the built-in face detection cascade, it's a pico cascade of regression trees trained on rendered faces
+// and scenes so it needs no dataset, run it with go generate.
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"image"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// training parameters
+const (
+	depth      = 6
+	candidates = 256
+	positives  = 5000
+	negatives  = 6000
+)
+
+// stages are trained until their false positive rate is below maxFPR or they reach their trees
+var stages = []struct {
+	trees  int
+	minTPR float64
+	maxFPR float64
+}{
+	{2, 0.995, 0.3}, {4, 0.995, 0.3}, {6, 0.995, 0.3}, {8, 0.995, 0.3}, {10, 0.997, 0.3}, {12, 0.997, 0.3},
+	{14, 0.997, 0.3}, {16, 0.997, 0.3}, {20, 0.997, 0.3}, {24, 0.997, 0.3}, {28, 0.997, 0.3}, {32, 0.997, 0.3},
+	{32, 0.998, 0.3}, {32, 0.998, 0.3}, {32, 0.998, 0.3}, {32, 0.998, 0.3},
+}
+
+type sample struct {
+	img     *image.Gray
+	r, c, s int
+	y       float64
+}
+
+type cascade struct {
+	codes       [][4]int8
+	predictions []float32
+	thresholds  []float32
+}
+
+func main() {
+	output := flag.String("o", "facefinder", "output file")
+	seed := flag.Int64("seed", 1, "random seed")
+	flag.Parse()
+	log.SetFlags(0)
+
+	rng := rand.New(rand.NewSource(*seed))
+
+	samples := make([]sample, 0, positives+negatives)
+	for i := 0; i < positives; i++ {
+		samples = append(samples, face(rng))
+	}
+
+	c := &cascade{}
+	outputs := make([]float64, 0, positives+negatives)
+	for range samples {
+		outputs = append(outputs, 0)
+	}
+
+	for i, stage := range stages {
+		samples, outputs = mine(rng, c, samples, outputs)
+
+		var tpr, fpr float64
+		for t := 0; t < stage.trees; t++ {
+			c.grow(rng, samples, outputs)
+
+			// trees of a stage but its last one don't reject
+			threshold := c.threshold(samples, outputs, stage.minTPR)
+			tpr, fpr = rates(samples, outputs, threshold)
+			if fpr <= stage.maxFPR || t == stage.trees-1 {
+				c.thresholds = append(c.thresholds, threshold)
+				break
+			}
+			c.thresholds = append(c.thresholds, -1e9)
+		}
+
+		samples, outputs = reject(samples, outputs, c.thresholds[len(c.thresholds)-1])
+		log.Printf("stage %d: %d trees, tpr %.4f, fpr %.4f", i+1, len(c.thresholds), tpr, fpr)
+	}
+
+	err := ioutil.WriteFile(*output, c.encode(), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// mine fills negatives with windows of rendered scenes that the cascade doesn't reject
+func mine(rng *rand.Rand, c *cascade, samples []sample, outputs []float64) ([]sample, []float64) {
+	count := 0
+	for _, s := range samples {
+		if s.y < 0 {
+			count++
+		}
+	}
+
+	mined, attempts := 0, 0
+	for count < negatives && attempts < 1000*negatives {
+		img := scene(rng, 256, 256)
+		for i := 0; i < 10000 && count < negatives; i++ {
+			attempts++
+			s := 20 + rng.Intn(100)
+			r := s/2 + 1 + rng.Intn(256-s-2)
+			c0 := s/2 + 1 + rng.Intn(256-s-2)
+			n := sample{img: img, r: r, c: c0, s: s, y: -1}
+
+			output, ok := c.classify(n)
+			if ok {
+				samples = append(samples, n)
+				outputs = append(outputs, output)
+				count++
+				mined++
+			}
+		}
+	}
+
+	log.Printf("mined %d negatives, false positive rate %.6f", mined, float64(mined)/float64(attempts))
+
+	return samples, outputs
+}
+
+// reject removes the samples rejected by the last threshold
+func reject(samples []sample, outputs []float64, threshold float32) ([]sample, []float64) {
+	kept, keptOutputs := samples[:0], outputs[:0]
+	for i := range samples {
+		if outputs[i] > float64(threshold) {
+			kept = append(kept, samples[i])
+			keptOutputs = append(keptOutputs, outputs[i])
+		}
+	}
+	return kept, keptOutputs
+}
+
+// classify runs the cascade on a sample
+func (c *cascade) classify(s sample) (float64, bool) {
+	leaves := 1 << depth
+	score := float32(0)
+	for t := range c.thresholds {
+		index := 1
+		for d := 0; d < depth; d++ {
+			index *= 2
+			if test(c.codes[t*(leaves-1)+index/2-1], s) {
+				index++
+			}
+		}
+
+		score += c.predictions[leaves*t+index-leaves]
+		if score <= c.thresholds[t] {
+			return 0, false
+		}
+	}
+
+	return float64(score), true
+}
+
+// test compares the intensity of two pixels of the sample, as done by Cascade.classify
+func test(code [4]int8, s sample) bool {
+	row, column := s.r*256, s.c*256
+	x1 := s.img.Pix[((row+int(code[0])*s.s)>>8)*s.img.Stride+((column+int(code[1])*s.s)>>8)]
+	x2 := s.img.Pix[((row+int(code[2])*s.s)>>8)*s.img.Stride+((column+int(code[3])*s.s)>>8)]
+	return x1 <= x2
+}
+
+// grow fits a regression tree to the samples weighted by how bad the cascade classifies them
+func (c *cascade) grow(rng *rand.Rand, samples []sample, outputs []float64) {
+	weights := make([]float64, len(samples))
+	var positive, negative float64
+	for i, s := range samples {
+		weights[i] = math.Exp(-s.y * outputs[i])
+		if s.y > 0 {
+			positive += weights[i]
+		} else {
+			negative += weights[i]
+		}
+	}
+	// classes weigh the same
+	for i, s := range samples {
+		if s.y > 0 {
+			weights[i] /= 2 * positive
+		} else {
+			weights[i] /= 2 * negative
+		}
+	}
+
+	leaves := 1 << depth
+	codes := make([][4]int8, leaves)
+	predictions := make([]float32, leaves)
+
+	indices := make([]int, len(samples))
+	for i := range indices {
+		indices[i] = i
+	}
+	split(rng, samples, weights, indices, codes, predictions, 1, 0)
+
+	c.codes = append(c.codes, codes[1:]...)
+	c.predictions = append(c.predictions, predictions...)
+
+	for i, s := range samples {
+		index := 1
+		for d := 0; d < depth; d++ {
+			index *= 2
+			if test(codes[index/2], s) {
+				index++
+			}
+		}
+		outputs[i] += float64(predictions[index-leaves])
+	}
+}
+
+// split chooses the test of the node minimizing the weighted squared error of its children
+func split(rng *rand.Rand, samples []sample, weights []float64, indices []int, codes [][4]int8, predictions []float32,
+	node, level int) {
+	if level == depth {
+		var sum, total float64
+		for _, i := range indices {
+			sum += weights[i] * samples[i].y
+			total += weights[i]
+		}
+		if total > 0 {
+			predictions[node-len(predictions)] = float32(sum / total)
+		}
+		return
+	}
+
+	best, bestError := [4]int8{}, math.Inf(1)
+	for k := 0; k < candidates; k++ {
+		code := [4]int8{random(rng), random(rng), random(rng), random(rng)}
+
+		var w, wy [2]float64
+		for _, i := range indices {
+			side := 0
+			if test(code, samples[i]) {
+				side = 1
+			}
+			w[side] += weights[i]
+			wy[side] += weights[i] * samples[i].y
+		}
+
+		// targets are +-1, so the weighted sum of squares is the weight
+		e := 0.0
+		for side := 0; side < 2; side++ {
+			if w[side] > 0 {
+				e += w[side] - wy[side]*wy[side]/w[side]
+			}
+		}
+
+		if e < bestError {
+			best, bestError = code, e
+		}
+	}
+	codes[node] = best
+
+	var left, right []int
+	for _, i := range indices {
+		if test(best, samples[i]) {
+			right = append(right, i)
+		} else {
+			left = append(left, i)
+		}
+	}
+
+	split(rng, samples, weights, left, codes, predictions, 2*node, level+1)
+	split(rng, samples, weights, right, codes, predictions, 2*node+1, level+1)
+}
+
+// random returns a pixel offset of a test, tests points are inside the window
+func random(rng *rand.Rand) int8 {
+	return int8(rng.Intn(255) - 127)
+}
+
+// threshold returns the highest threshold that keeps minTPR of positives
+func (c *cascade) threshold(samples []sample, outputs []float64, minTPR float64) float32 {
+	scores := make([]float64, 0, len(samples))
+	for i, s := range samples {
+		if s.y > 0 {
+			scores = append(scores, outputs[i])
+		}
+	}
+	sort.Float64s(scores)
+
+	index := int(float64(len(scores)) * (1 - minTPR))
+	return float32(scores[index] - 1e-6)
+}
+
+// rates returns the true and false positive rates of a threshold
+func rates(samples []sample, outputs []float64, threshold float32) (float64, float64) {
+	var tp, fp, p, n float64
+	for i, s := range samples {
+		passed := outputs[i] > float64(threshold)
+		if s.y > 0 {
+			p++
+			if passed {
+				tp++
+			}
+		} else {
+			n++
+			if passed {
+				fp++
+			}
+		}
+	}
+	return tp / p, fp / n
+}
+
+// encode writes the cascade in pigo format
+func (c *cascade) encode() []byte {
+	leaves := 1 << depth
+	buf := make([]byte, 16)
+	binary.LittleEndian.PutUint32(buf[8:], depth)
+	binary.LittleEndian.PutUint32(buf[12:], uint32(len(c.thresholds)))
+
+	for t := range c.thresholds {
+		for _, code := range c.codes[t*(leaves-1) : (t+1)*(leaves-1)] {
+			buf = append(buf, byte(code[0]), byte(code[1]), byte(code[2]), byte(code[3]))
+		}
+		for _, prediction := range c.predictions[t*leaves : (t+1)*leaves] {
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(prediction))
+		}
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(c.thresholds[t]))
+	}
+
+	return buf
+}
+
+// face renders a frontal face centered in a window of a scene, the face is jittered so near windows detect it too.
+func face(rng *rand.Rand) sample {
+	s := 24 + rng.Intn(48)
+	size := 2 * s
+	img := scene(rng, size, size)
+
+	scale := float64(s) * (0.85 + 0.3*rng.Float64())
+	cx := float64(size)/2 + float64(s)*0.1*(2*rng.Float64()-1)
+	cy := float64(size)/2 + float64(s)*0.1*(2*rng.Float64()-1)
+	angle := 0.2 * (2*rng.Float64() - 1)
+	sin, cos := math.Sin(angle), math.Cos(angle)
+
+	skin := 70 + 170*rng.Float64()
+	hair := 255 * rng.Float64()
+	eyes := skin * (0.2 + 0.4*rng.Float64())
+	brows := skin * (0.3 + 0.5*rng.Float64())
+	mouth := skin * (0.4 + 0.4*rng.Float64())
+	light := 0.6 * (2*rng.Float64() - 1)
+	glasses := rng.Intn(5) == 0
+	beard := rng.Intn(6) == 0
+	noise := 0.0
+	if rng.Intn(3) != 0 {
+		noise = 10 * rng.Float64()
+	}
+
+	// geometry and shading vary between faces
+	width, height := uniform(rng, 0.36, 0.44), uniform(rng, 0.48, 0.56)
+	eyeX, eyeY := uniform(rng, 0.14, 0.2), uniform(rng, -0.14, -0.06)
+	browY := eyeY - uniform(rng, 0.09, 0.14)
+	mouthY, mouthWidth := uniform(rng, 0.22, 0.32), uniform(rng, 0.09, 0.16)
+	socket, nose, bridge, sides := uniform(rng, 0.7, 1), uniform(rng, 0.6, 1), uniform(rng, 1, 1.15), uniform(rng, 0.5, 0.9)
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// coordinates relative to the face
+			dx, dy := float64(x)-cx, float64(y)-cy
+			u := (cos*dx + sin*dy) / scale
+			v := (-sin*dx + cos*dy) / scale
+
+			value, ok := 0.0, false
+			switch {
+			case ellipse(u, v, 0, 0.02, width, height):
+				value, ok = skin*(1+light*u), true
+				// eye sockets are shaded
+				if ellipse(u, v, -eyeX, eyeY, 0.13, 0.08) || ellipse(u, v, eyeX, eyeY, 0.13, 0.08) {
+					value *= socket
+				}
+				// nose bridge catches the light and its tip casts a shadow
+				if math.Abs(u) < 0.04 && v > eyeY && v < mouthY-0.12 {
+					value *= bridge
+				}
+				if ellipse(u, v, 0, mouthY-0.12, 0.08, 0.03) {
+					value *= nose
+				}
+				if beard && v > mouthY-0.08 {
+					value = hair
+				}
+			case v < eyeY && ellipse(u, v, 0, -0.05, width+0.08, height+0.1):
+				value, ok = hair, true
+			case v >= eyeY && ellipse(u, v, 0, 0.02, width+0.07, height+0.04):
+				// ears and shaded sides of the head
+				value, ok = skin*sides, true
+			}
+
+			if ellipse(u, v, -eyeX, eyeY, 0.065, 0.035) || ellipse(u, v, eyeX, eyeY, 0.065, 0.035) {
+				value, ok = eyes, true
+			}
+			if ellipse(u, v, -eyeX, browY, 0.09, 0.02) || ellipse(u, v, eyeX, browY, 0.09, 0.02) {
+				value, ok = brows, true
+			}
+			if ellipse(u, v, 0, mouthY, mouthWidth, 0.035) {
+				value, ok = mouth, true
+			}
+			if glasses && (ring(u, v, -eyeX, eyeY) || ring(u, v, eyeX, eyeY) || math.Abs(v-eyeY) < 0.015 && math.Abs(u) < eyeX-0.11) {
+				value, ok = 30, true
+			}
+
+			if ok {
+				img.Pix[y*img.Stride+x] = clamp(value + noise*rng.NormFloat64())
+			}
+		}
+	}
+
+	blur(img, rng.Intn(2))
+
+	return sample{img: img, r: size / 2, c: size / 2, s: s, y: 1}
+}
+
+// scene renders a background of gradients, shapes and noise
+func scene(rng *rand.Rand, width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	base, gx, gy := 255*rng.Float64(), 2*rng.Float64()-1, 2*rng.Float64()-1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Pix[y*img.Stride+x] = clamp(base + 100*(gx*float64(x)/float64(width)+gy*float64(y)/float64(height)))
+		}
+	}
+
+	shapes := rng.Intn(40)
+	for i := 0; i < shapes; i++ {
+		value := 255 * rng.Float64()
+		x0, y0 := rng.Float64()*float64(width), rng.Float64()*float64(height)
+		w, h := 2+rng.Float64()*float64(width)/3, 2+rng.Float64()*float64(height)/3
+		round := rng.Intn(2) == 0
+		for y := int(y0 - h); y < int(y0+h); y++ {
+			for x := int(x0 - w); x < int(x0+w); x++ {
+				if x < 0 || y < 0 || x >= width || y >= height {
+					continue
+				}
+				if round && !ellipse(float64(x), float64(y), x0, y0, w, h) {
+					continue
+				}
+				img.Pix[y*img.Stride+x] = clamp(value)
+			}
+		}
+	}
+
+	noise := 0.0
+	if rng.Intn(3) != 0 {
+		noise = 30 * rng.Float64()
+	}
+	for i := range img.Pix {
+		img.Pix[i] = clamp(float64(img.Pix[i]) + noise*rng.NormFloat64())
+	}
+
+	blur(img, rng.Intn(3))
+
+	return img
+}
+
+func uniform(rng *rand.Rand, min, max float64) float64 {
+	return min + (max-min)*rng.Float64()
+}
+
+func ellipse(x, y, cx, cy, rx, ry float64) bool {
+	x, y = (x-cx)/rx, (y-cy)/ry
+	return x*x+y*y <= 1
+}
+
+func ring(u, v, cx, cy float64) bool {
+	return ellipse(u, v, cx, cy, 0.12, 0.08) && !ellipse(u, v, cx, cy, 0.1, 0.065)
+}
+
+// blur applies a box blur of radius
+func blur(img *image.Gray, radius int) {
+	if radius == 0 {
+		return
+	}
+
+	bounds := img.Bounds()
+	src := append([]uint8(nil), img.Pix...)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			sum, n := 0, 0
+			for j := y - radius; j <= y+radius; j++ {
+				for i := x - radius; i <= x+radius; i++ {
+					if i >= 0 && j >= 0 && i < bounds.Dx() && j < bounds.Dy() {
+						sum += int(src[j*img.Stride+i])
+						n++
+					}
+				}
+			}
+			img.Pix[y*img.Stride+x] = uint8(sum / n)
+		}
+	}
+}
+
+func clamp(value float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, value)))
+}
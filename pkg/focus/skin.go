@@ -0,0 +1,85 @@
+package focus
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// minimum share of skin pixels in the best window for it to be considered a face
+const minSkin = 0.25
+
+// Skin return the center of the 3x3 cells windows with the most skin toned pixels, it locates faces without a cascade
+// file, ok is false if no window has enough skin.
+func Skin(img image.Image) (Point, bool) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	cell := int(math.Min(float64(width), float64(height))) / entropyCells
+	if cell < 2 {
+		return Point{}, false
+	}
+
+	columns, rows := width/cell, height/cell
+
+	counts := make([][]int, rows)
+	for row := range counts {
+		counts[row] = make([]int, columns)
+	}
+
+	for y := 0; y < rows*cell; y++ {
+		for x := 0; x < columns*cell; x++ {
+			if isSkin(img.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+				counts[y/cell][x/cell]++
+			}
+		}
+	}
+
+	sums := make([][]int, rows)
+	best := 0
+	for row := 0; row+3 <= rows; row++ {
+		sums[row] = make([]int, columns)
+		for column := 0; column+3 <= columns; column++ {
+			for i := row; i < row+3; i++ {
+				for j := column; j < column+3; j++ {
+					sums[row][column] += counts[i][j]
+				}
+			}
+			if sums[row][column] > best {
+				best = sums[row][column]
+			}
+		}
+	}
+
+	if float64(best) < minSkin*float64(9*cell*cell) {
+		return Point{}, false
+	}
+
+	// a face covers several windows, so windows close to the best are averaged weighted by their skin.
+	var x, y, total float64
+	for row := 0; row+3 <= rows; row++ {
+		for column := 0; column+3 <= columns; column++ {
+			if sum := sums[row][column]; float64(sum) >= 0.9*float64(best) {
+				x += (float64(column) + 1.5) * float64(sum)
+				y += (float64(row) + 1.5) * float64(sum)
+				total += float64(sum)
+			}
+		}
+	}
+
+	return Point{X: x / total * float64(cell) / float64(width), Y: y / total * float64(cell) / float64(height)}, true
+}
+
+// isSkin classifies opaque pixels by their chroma, using the Cb and Cr ranges of Chai and Ngan.
+func isSkin(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	if a < 0x8000 {
+		return false
+	}
+
+	// un-premultiply and reduce to 8 bits
+	r, g, b = r*0xffff/a>>8, g*0xffff/a>>8, b*0xffff/a>>8
+	_, cb, cr := color.RGBToYCbCr(uint8(r), uint8(g), uint8(b))
+
+	return cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173
+}
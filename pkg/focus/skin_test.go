@@ -0,0 +1,44 @@
+package focus
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestSkin(t *testing.T) {
+	skin := color.RGBA{R: 224, G: 172, B: 140, A: 255}
+	sky := color.RGBA{R: 80, G: 140, B: 220, A: 255}
+
+	tests := []struct {
+		name  string
+		patch image.Rectangle
+		color color.Color
+		want  Point
+		ok    bool
+	}{
+		{name: "face on the left", patch: image.Rect(20, 40, 80, 100), color: skin, want: Point{X: 0.208, Y: 0.583}, ok: true},
+		{name: "face on the top right", patch: image.Rect(150, 0, 210, 60), color: skin, want: Point{X: 0.75, Y: 0.25}, ok: true},
+		{name: "too small", patch: image.Rect(20, 40, 30, 50), color: skin},
+		{name: "transparent", patch: image.Rect(20, 40, 80, 100), color: color.RGBA{}},
+		{name: "no skin", patch: image.Rect(0, 0, 240, 120), color: sky},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 240, 120))
+			draw.Draw(img, img.Bounds(), image.NewUniform(sky), image.Point{}, draw.Src)
+			draw.Draw(img, tt.patch, image.NewUniform(tt.color), image.Point{}, draw.Src)
+
+			got, ok := Skin(img)
+			if ok != tt.ok {
+				t.Fatalf("Skin() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && (math.Abs(got.X-tt.want.X) > 0.05 || math.Abs(got.Y-tt.want.Y) > 0.05) {
+				t.Errorf("Skin() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
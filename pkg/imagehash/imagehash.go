@@ -0,0 +1,151 @@
+// Package imagehash computes 64 bit perceptual hashes of images, similar images have hashes with a small hamming
+// distance.
+package imagehash
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// Hash is a 64 bit image hash
+type Hash uint64
+
+// String return the hash as 16 hex digits
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Parse parses a hash formatted by String
+func Parse(value string) (Hash, error) {
+	hash, err := strconv.ParseUint(value, 16, 64)
+	return Hash(hash), err
+}
+
+// Distance is the hamming distance between two hashes (0 to 64), 0 is identical.
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Average (aHash) sets a bit for every pixel of an 8x8 grayscale thumbnail brighter than the mean.
+func Average(img image.Image) Hash {
+	pixels := shrink(img, 8, 8)
+
+	mean := 0.0
+	for _, value := range pixels {
+		mean += value
+	}
+	mean /= float64(len(pixels))
+
+	var hash Hash
+	for i, value := range pixels {
+		if value > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+// Difference (dHash) sets a bit for every pixel of a 9x8 grayscale thumbnail brighter than its right neighbour.
+func Difference(img image.Image) Hash {
+	pixels := shrink(img, 9, 8)
+
+	var hash Hash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				hash |= 1 << uint(y*8+x)
+			}
+		}
+	}
+
+	return hash
+}
+
+// Perceptual (pHash) sets a bit for every low frequency DCT coefficient of a 32x32 grayscale thumbnail above their
+// median.
+func Perceptual(img image.Image) Hash {
+	const size = 32
+	pixels := shrink(img, size, size)
+
+	// separable 2D DCT-II, only the 8x8 lowest frequencies are needed
+	rows := make([]float64, size*8)
+	for y := 0; y < size; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < size; x++ {
+				sum += pixels[y*size+x] * dctCos[u][x]
+			}
+			rows[y*8+u] = sum
+		}
+	}
+
+	coefficients := make([]float64, 64)
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for y := 0; y < size; y++ {
+				sum += rows[y*8+u] * dctCos[v][y]
+			}
+			coefficients[v*8+u] = sum
+		}
+	}
+
+	// median without DC coefficient, which is only the average brightness
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[31] + sorted[32]) / 2
+
+	var hash Hash
+	for i, value := range coefficients {
+		if value > median {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+// dctCos[u][x] = cos((2x+1)uπ/64)
+var dctCos = func() [8][32]float64 {
+	var table [8][32]float64
+	for u := 0; u < 8; u++ {
+		for x := 0; x < 32; x++ {
+			table[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 64)
+		}
+	}
+	return table
+}()
+
+// shrink return luminance of a width x height thumbnail, every pixel is the average of the area it covers. Large
+// images are sampled up to 256 pixels per dimension.
+func shrink(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	sums := make([]float64, width*height)
+	counts := make([]float64, width*height)
+
+	stepX, stepY := bounds.Dx()/256+1, bounds.Dy()/256+1
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		row := (y - bounds.Min.Y) * height / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			column := (x - bounds.Min.X) * width / bounds.Dx()
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			sums[row*width+column] += float64(gray.Y)
+			counts[row*width+column]++
+		}
+	}
+
+	for i := range sums {
+		if counts[i] > 0 {
+			sums[i] /= counts[i]
+		}
+	}
+
+	return sums
+}
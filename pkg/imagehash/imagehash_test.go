@@ -0,0 +1,121 @@
+package imagehash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// fill returns a width x height grayscale image with pixels set by value
+func fill(width, height int, value func(x, y int) uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: value(x, y)})
+		}
+	}
+	return img
+}
+
+func white(bright bool) uint8 {
+	if bright {
+		return 0xff
+	}
+	return 0
+}
+
+func TestHashes(t *testing.T) {
+	uniform := fill(64, 64, func(x, y int) uint8 { return 128 })
+	left := fill(64, 64, func(x, y int) uint8 { return white(x < 32) })
+	top := fill(64, 64, func(x, y int) uint8 { return white(y < 32) })
+	// 9 columns of 8px, the edge falls in the middle of the 5th column
+	edge := fill(72, 64, func(x, y int) uint8 { return white(x < 36) })
+	brighter := fill(72, 64, func(x, y int) uint8 { return uint8(x * 3) })
+	darker := fill(72, 64, func(x, y int) uint8 { return uint8(255 - x*3) })
+
+	tests := []struct {
+		name string
+		hash func(image.Image) Hash
+		img  image.Image
+		want Hash
+	}{
+		{name: "average uniform", hash: Average, img: uniform, want: 0},
+		{name: "average left", hash: Average, img: left, want: 0x0f0f0f0f0f0f0f0f},
+		{name: "average top", hash: Average, img: top, want: 0x00000000ffffffff},
+		{name: "difference uniform", hash: Difference, img: uniform, want: 0},
+		{name: "difference edge", hash: Difference, img: edge, want: 0x1818181818181818},
+		{name: "difference brighter to the right", hash: Difference, img: brighter, want: 0},
+		{name: "difference darker to the right", hash: Difference, img: darker, want: 0xffffffffffffffff},
+		{name: "perceptual zero", hash: Perceptual, img: fill(64, 64, func(x, y int) uint8 { return 0 }), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hash(tt.img); got != tt.want {
+				t.Errorf("hash = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerceptualSimilarity(t *testing.T) {
+	pattern := func(scale int) image.Image {
+		return fill(64*scale, 48*scale, func(x, y int) uint8 {
+			x, y = x/scale, y/scale
+			return uint8((x*x + y*3) % 256)
+		})
+	}
+	inverted := fill(64, 48, func(x, y int) uint8 { return 255 - uint8((x*x+y*3)%256) })
+
+	original := Perceptual(pattern(1))
+	if distance := Distance(original, Perceptual(pattern(4))); distance > 4 {
+		t.Errorf("scaled image distance = %d, want at most 4", distance)
+	}
+	if distance := Distance(original, Perceptual(inverted)); distance < 32 {
+		t.Errorf("inverted image distance = %d, want at least 32", distance)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Hash
+		err   bool
+	}{
+		{value: "0000000000000000", want: 0},
+		{value: "0f0f0f0f0f0f0f0f", want: 0x0f0f0f0f0f0f0f0f},
+		{value: "ffffffffffffffff", want: 0xffffffffffffffff},
+		{value: "not a hash", err: true},
+		{value: "1ffffffffffffffff", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("Parse() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && (got != tt.want || got.String() != tt.value) {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b Hash
+		want int
+	}{
+		{a: 0, b: 0, want: 0},
+		{a: 0, b: 0xffffffffffffffff, want: 64},
+		{a: 0x0f0f0f0f0f0f0f0f, b: 0x00000000ffffffff, want: 32},
+		{a: 1, b: 3, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// default minimum amplitude of the blur kernel, same as libvips.
+const defaultMinAmpl = 0.2
+
+// Blur applies a gaussian blur with standard deviation sigma, the kernel is cut where it drops below minAmpl (0 uses
+// the default 0.2), a lower minAmpl is a more accurate but slower blur.
+func Blur(img image.Image, sigma, minAmpl float64) *image.RGBA {
+	src := RGBA(img)
+	if sigma <= 0 {
+		return src
+	}
+
+	kernel := gaussian(sigma, minAmpl)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	// horizontal pass into a float buffer, then vertical pass back to pixels
+	buffer := make([]float64, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for k, weight := range kernel {
+				sx := clamp(x+k-len(kernel)/2, 0, width-1)
+				offset := src.PixOffset(sx, y)
+				for c := 0; c < 4; c++ {
+					sum[c] += weight * float64(src.Pix[offset+c])
+				}
+			}
+			copy(buffer[(y*width+x)*4:], sum[:])
+		}
+	}
+
+	blurred := image.NewRGBA(src.Rect)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for k, weight := range kernel {
+				sy := clamp(y+k-len(kernel)/2, 0, height-1)
+				offset := (sy*width + x) * 4
+				for c := 0; c < 4; c++ {
+					sum[c] += weight * buffer[offset+c]
+				}
+			}
+			offset := blurred.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				blurred.Pix[offset+c] = uint8(math.Max(0, math.Min(255, sum[c]+0.5)))
+			}
+		}
+	}
+
+	return blurred
+}
+
+// gaussian return a normalized 1D gaussian kernel
+func gaussian(sigma, minAmpl float64) []float64 {
+	if minAmpl <= 0 || minAmpl >= 1 {
+		minAmpl = defaultMinAmpl
+	}
+
+	radius := int(math.Ceil(sigma * math.Sqrt(-2*math.Log(minAmpl))))
+	if radius < 1 {
+		radius = 1
+	}
+
+	kernel := make([]float64, 2*radius+1)
+	total := 0.0
+	for i := range kernel {
+		x := float64(i - radius)
+		kernel[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		total += kernel[i]
+	}
+
+	for i := range kernel {
+		kernel[i] /= total
+	}
+
+	return kernel
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+// Package imaging implements basic image operations (resize, crop, embed, rotate, flip and blur) in pure Go, results
+// are RGBA images with bounds starting at (0, 0).
+package imaging
+
+import (
+	"image"
+	"image/draw"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// RGBA return a copy of the image as RGBA, images that are already RGBA starting at (0, 0) are returned as is.
+func RGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Resize scales the image to exactly width x height
+func Resize(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return RGBA(img)
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+// Crop extracts region of the image, region is relative to the image top left corner.
+func Crop(img image.Image, region image.Rectangle) *image.RGBA {
+	bounds := img.Bounds()
+	region = region.Add(bounds.Min).Intersect(bounds)
+
+	cropped := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, region.Min, draw.Src)
+	return cropped
+}
+
+// Embed centers the image in a transparent width x height canvas.
+func Embed(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	left, top := (width-bounds.Dx())/2, (height-bounds.Dy())/2
+
+	embedded := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(embedded, image.Rect(left, top, left+bounds.Dx(), top+bounds.Dy()), img, bounds.Min, draw.Src)
+	return embedded
+}
+
+// Rotate rotates the image clockwise by angle, a multiple of 90 degrees.
+func Rotate(img image.Image, angle int) *image.RGBA {
+	src := RGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	var rotated *image.RGBA
+	var position func(x, y int) (int, int)
+
+	switch ((angle % 360) + 360) % 360 {
+	case 90:
+		rotated = image.NewRGBA(image.Rect(0, 0, height, width))
+		position = func(x, y int) (int, int) { return height - 1 - y, x }
+	case 180:
+		rotated = image.NewRGBA(image.Rect(0, 0, width, height))
+		position = func(x, y int) (int, int) { return width - 1 - x, height - 1 - y }
+	case 270:
+		rotated = image.NewRGBA(image.Rect(0, 0, height, width))
+		position = func(x, y int) (int, int) { return y, width - 1 - x }
+	default:
+		return src
+	}
+
+	transform(src, rotated, position)
+	return rotated
+}
+
+// Flip mirrors the image top to bottom
+func Flip(img image.Image) *image.RGBA {
+	src := RGBA(img)
+	height := src.Rect.Dy()
+
+	flipped := image.NewRGBA(src.Rect)
+	transform(src, flipped, func(x, y int) (int, int) { return x, height - 1 - y })
+	return flipped
+}
+
+// Flop mirrors the image left to right
+func Flop(img image.Image) *image.RGBA {
+	src := RGBA(img)
+	width := src.Rect.Dx()
+
+	flopped := image.NewRGBA(src.Rect)
+	transform(src, flopped, func(x, y int) (int, int) { return width - 1 - x, y })
+	return flopped
+}
+
+// transform copies every pixel of src to its position in dst
+func transform(src, dst *image.RGBA, position func(x, y int) (int, int)) {
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := position(x, y)
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// numbered returns an opaque gray image of rows, each pixel value is its number
+func numbered(rows [][]uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, value := range row {
+			img.Set(x, y, color.RGBA{R: value, G: value, B: value, A: 255})
+		}
+	}
+	return img
+}
+
+// values returns the red channel of the image rows, transparent pixels are 0
+func values(img *image.RGBA) [][]uint8 {
+	rows := make([][]uint8, img.Rect.Dy())
+	for y := range rows {
+		rows[y] = make([]uint8, img.Rect.Dx())
+		for x := range rows[y] {
+			rows[y][x] = img.RGBAAt(img.Rect.Min.X+x, img.Rect.Min.Y+y).R
+		}
+	}
+	return rows
+}
+
+func TestTransforms(t *testing.T) {
+	src := [][]uint8{
+		{1, 2, 3},
+		{4, 5, 6},
+	}
+
+	tests := []struct {
+		name      string
+		transform func(image.Image) *image.RGBA
+		want      [][]uint8
+	}{
+		{name: "rotate 90", transform: func(img image.Image) *image.RGBA { return Rotate(img, 90) },
+			want: [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{name: "rotate 180", transform: func(img image.Image) *image.RGBA { return Rotate(img, 180) },
+			want: [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{name: "rotate 270", transform: func(img image.Image) *image.RGBA { return Rotate(img, 270) },
+			want: [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{name: "rotate -90", transform: func(img image.Image) *image.RGBA { return Rotate(img, -90) },
+			want: [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{name: "rotate 45 is ignored", transform: func(img image.Image) *image.RGBA { return Rotate(img, 45) },
+			want: src},
+		{name: "flip", transform: Flip, want: [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{name: "flop", transform: Flop, want: [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{name: "crop", transform: func(img image.Image) *image.RGBA { return Crop(img, image.Rect(1, 0, 3, 2)) },
+			want: [][]uint8{{2, 3}, {5, 6}}},
+		{name: "crop outside", transform: func(img image.Image) *image.RGBA { return Crop(img, image.Rect(2, 1, 5, 5)) },
+			want: [][]uint8{{6}}},
+		{name: "embed", transform: func(img image.Image) *image.RGBA { return Embed(img, 5, 4) },
+			want: [][]uint8{{0, 0, 0, 0, 0}, {0, 1, 2, 3, 0}, {0, 4, 5, 6, 0}, {0, 0, 0, 0, 0}}},
+		{name: "resize to same size", transform: func(img image.Image) *image.RGBA { return Resize(img, 3, 2) },
+			want: src},
+		{name: "pixelate", transform: func(img image.Image) *image.RGBA { return Pixelate(img, 2) },
+			want: [][]uint8{{3, 3, 4}, {3, 3, 4}}},
+		{name: "pixelate by 1", transform: func(img image.Image) *image.RGBA { return Pixelate(img, 1) },
+			want: src},
+		{name: "blur by 0", transform: func(img image.Image) *image.RGBA { return Blur(img, 0, 0) },
+			want: src},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := values(tt.transform(numbered(src))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubImage(t *testing.T) {
+	src := numbered([][]uint8{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	}).SubImage(image.Rect(1, 1, 3, 3))
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want [][]uint8
+	}{
+		{name: "rgba", img: RGBA(src), want: [][]uint8{{5, 6}, {8, 9}}},
+		{name: "crop is relative", img: Crop(src, image.Rect(1, 0, 2, 2)), want: [][]uint8{{6}, {9}}},
+		{name: "rotate", img: Rotate(src, 90), want: [][]uint8{{8, 5}, {9, 6}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.img.Rect.Min != (image.Point{}) {
+				t.Errorf("bounds = %v, want to start at (0, 0)", tt.img.Rect)
+			}
+			if got := values(tt.img); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniform(t *testing.T) {
+	uniform := numbered([][]uint8{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+
+	tests := []struct {
+		name  string
+		img   *image.RGBA
+		width int
+	}{
+		{name: "upscale", img: Resize(uniform, 8, 6), width: 8},
+		{name: "downscale", img: Resize(uniform, 2, 1), width: 2},
+		{name: "blur", img: Blur(uniform, 2, 0), width: 4},
+		{name: "pixelate", img: Pixelate(uniform, 3), width: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.img.Rect.Dx() != tt.width {
+				t.Errorf("width = %d, want %d", tt.img.Rect.Dx(), tt.width)
+			}
+			for _, row := range values(tt.img) {
+				for _, value := range row {
+					if value != 100 {
+						t.Fatalf("got %v, want every pixel 100", values(tt.img))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBlur(t *testing.T) {
+	// a single white pixel spreads symmetrically and keeps the total brightness
+	img := numbered([][]uint8{
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 250, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+	})
+
+	got := values(Blur(img, 1, 0.01))
+	sum := 0
+	for y, row := range got {
+		for x, value := range row {
+			sum += int(value)
+			if value != got[4-y][x] || value != got[y][4-x] || value != got[x][y] {
+				t.Fatalf("blur isn't symmetric: %v", got)
+			}
+		}
+	}
+
+	if got[2][2] >= 250 || got[2][2] <= got[2][1] || got[2][1] <= got[2][0] {
+		t.Errorf("blur doesn't fall off from the center: %v", got)
+	}
+	if math.Abs(float64(sum-250)) > 25 {
+		t.Errorf("blurred total = %d, want about 250", sum)
+	}
+}
+
+func TestGaussian(t *testing.T) {
+	tests := []struct {
+		sigma, minAmpl float64
+		size           int
+	}{
+		{sigma: 1, minAmpl: 0.2, size: 5},
+		{sigma: 1, minAmpl: 0, size: 5},
+		{sigma: 2, minAmpl: 0.2, size: 9},
+		{sigma: 1, minAmpl: 0.01, size: 9},
+		{sigma: 0.1, minAmpl: 0.2, size: 3},
+	}
+
+	for _, tt := range tests {
+		kernel := gaussian(tt.sigma, tt.minAmpl)
+		if len(kernel) != tt.size {
+			t.Errorf("gaussian(%v, %v) size = %d, want %d", tt.sigma, tt.minAmpl, len(kernel), tt.size)
+		}
+
+		total := 0.0
+		for _, weight := range kernel {
+			total += weight
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("gaussian(%v, %v) sums to %v, want 1", tt.sigma, tt.minAmpl, total)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package imaging
+
+import "image"
+
+// Pixelate replaces every size x size block of the image by its average colour.
+func Pixelate(img image.Image, size int) *image.RGBA {
+	src := RGBA(img)
+	if size <= 1 {
+		return src
+	}
+
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	pixelated := image.NewRGBA(src.Rect)
+
+	for top := 0; top < height; top += size {
+		for left := 0; left < width; left += size {
+			block := image.Rect(left, top, left+size, top+size).Intersect(src.Rect)
+
+			var sum [4]int
+			for y := block.Min.Y; y < block.Max.Y; y++ {
+				for x := block.Min.X; x < block.Max.X; x++ {
+					offset := src.PixOffset(x, y)
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+				}
+			}
+
+			count := block.Dx() * block.Dy()
+			for y := block.Min.Y; y < block.Max.Y; y++ {
+				for x := block.Min.X; x < block.Max.X; x++ {
+					offset := pixelated.PixOffset(x, y)
+					for c := 0; c < 4; c++ {
+						pixelated.Pix[offset+c] = uint8(sum[c] / count)
+					}
+				}
+			}
+		}
+	}
+
+	return pixelated
+}
@@ -0,0 +1,342 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// EXIF tags
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagArtist           = 0x013B
+	tagCopyright        = 0x8298
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920A
+	tagLensMake         = 0xA433
+	tagLensModel        = 0xA434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// maximum number of entries in a single IFD, guards against corrupt counts.
+const maxEntries = 1024
+
+var errInvalidExif = errors.New("invalid exif")
+
+// Exif holds the commonly used EXIF fields, missing fields have their zero value.
+type Exif struct {
+	Make             string
+	Model            string
+	Software         string
+	Artist           string
+	Copyright        string
+	Orientation      int
+	DateTime         string
+	DateTimeOriginal string
+	ExposureTime     float64
+	FNumber          float64
+	ISO              int
+	FocalLength      float64
+	LensMake         string
+	LensModel        string
+	GPS              *GPS
+}
+
+// GPS position, latitude and longitude in signed decimal degrees, altitude in meters.
+type GPS struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+}
+
+// Fields return non empty EXIF fields keyed as they're added to payload.Data
+func (e *Exif) Fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	strs := map[string]string{
+		"make":              e.Make,
+		"model":             e.Model,
+		"software":          e.Software,
+		"artist":            e.Artist,
+		"copyright":         e.Copyright,
+		"datetime":          e.DateTime,
+		"datetime_original": e.DateTimeOriginal,
+		"lens_make":         e.LensMake,
+		"lens_model":        e.LensModel,
+	}
+	for key, value := range strs {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+
+	nums := map[string]float64{
+		"exposure_time": e.ExposureTime,
+		"f_number":      e.FNumber,
+		"focal_length":  e.FocalLength,
+	}
+	for key, value := range nums {
+		if value != 0 {
+			fields[key] = value
+		}
+	}
+
+	if e.Orientation != 0 {
+		fields["orientation"] = e.Orientation
+	}
+
+	if e.ISO != 0 {
+		fields["iso"] = e.ISO
+	}
+
+	if e.GPS != nil {
+		fields["gps"] = map[string]interface{}{
+			"latitude":  e.GPS.Latitude,
+			"longitude": e.GPS.Longitude,
+			"altitude":  e.GPS.Altitude,
+		}
+	}
+
+	return fields
+}
+
+// ParseExif parses an EXIF TIFF structure
+func ParseExif(buf []byte) (*Exif, error) {
+	t, err := newTIFF(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	ifd0, err := t.ifd(t.first())
+	if err != nil {
+		return nil, err
+	}
+
+	exif := &Exif{
+		Make:        ifd0.string(tagMake),
+		Model:       ifd0.string(tagModel),
+		Software:    ifd0.string(tagSoftware),
+		Artist:      ifd0.string(tagArtist),
+		Copyright:   ifd0.string(tagCopyright),
+		Orientation: int(ifd0.uint(tagOrientation)),
+		DateTime:    exifTime(ifd0.string(tagDateTime)),
+	}
+
+	if offset := ifd0.uint(tagExifIFD); offset != 0 {
+		sub, err := t.ifd(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		exif.DateTimeOriginal = exifTime(sub.string(tagDateTimeOriginal))
+		exif.ExposureTime = sub.rational(tagExposureTime, 0)
+		exif.FNumber = sub.rational(tagFNumber, 0)
+		exif.ISO = int(sub.uint(tagISO))
+		exif.FocalLength = sub.rational(tagFocalLength, 0)
+		exif.LensMake = sub.string(tagLensMake)
+		exif.LensModel = sub.string(tagLensModel)
+	}
+
+	if offset := ifd0.uint(tagGPSIFD); offset != 0 {
+		gps, err := t.ifd(offset)
+		if err != nil {
+			return nil, err
+		}
+		exif.GPS = parseGPS(gps)
+	}
+
+	return exif, nil
+}
+
+func parseGPS(gps ifd) *GPS {
+	if gps.entries[tagGPSLatitude] == nil || gps.entries[tagGPSLongitude] == nil {
+		return nil
+	}
+
+	position := &GPS{
+		Latitude:  degrees(gps, tagGPSLatitude),
+		Longitude: degrees(gps, tagGPSLongitude),
+		Altitude:  gps.rational(tagGPSAltitude, 0),
+	}
+
+	if gps.string(tagGPSLatitudeRef) == "S" {
+		position.Latitude = -position.Latitude
+	}
+
+	if gps.string(tagGPSLongitudeRef) == "W" {
+		position.Longitude = -position.Longitude
+	}
+
+	// 1 is below sea level
+	if gps.uint(tagGPSAltitudeRef) == 1 {
+		position.Altitude = -position.Altitude
+	}
+
+	return position
+}
+
+// degrees converts degrees, minutes and seconds rationals into decimal degrees
+func degrees(gps ifd, tag uint16) float64 {
+	return gps.rational(tag, 0) + gps.rational(tag, 1)/60 + gps.rational(tag, 2)/3600
+}
+
+// exifTime formats EXIF "2006:01:02 15:04:05" time as "2006-01-02T15:04:05", unparsable values are kept as is.
+func exifTime(value string) string {
+	t, err := time.Parse("2006:01:02 15:04:05", value)
+	if err != nil {
+		return value
+	}
+	return t.Format("2006-01-02T15:04:05")
+}
+
+//------------------------------------------------------------------------------
+
+// tiff reads IFDs of a TIFF structure in its byte order.
+type tiff struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+type entry struct {
+	kind  uint16
+	count uint32
+	// pos is the position of the entry in the buffer
+	pos   int
+	value []byte
+}
+
+type ifd struct {
+	order   binary.ByteOrder
+	entries map[uint16]*entry
+}
+
+// sizes of TIFF types in bytes
+var typeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+func newTIFF(buf []byte) (*tiff, error) {
+	if len(buf) < 8 {
+		return nil, errInvalidExif
+	}
+
+	switch string(buf[0:2]) {
+	case "II":
+		return &tiff{buf: buf, order: binary.LittleEndian}, nil
+	case "MM":
+		return &tiff{buf: buf, order: binary.BigEndian}, nil
+	default:
+		return nil, errInvalidExif
+	}
+}
+
+// first return the offset of IFD0
+func (t *tiff) first() uint32 {
+	return t.order.Uint32(t.buf[4:])
+}
+
+func (t *tiff) ifd(offset uint32) (ifd, error) {
+	result := ifd{order: t.order, entries: make(map[uint16]*entry)}
+
+	pos := int(offset)
+	if pos < 8 || pos+2 > len(t.buf) {
+		return result, errInvalidExif
+	}
+
+	count := int(t.order.Uint16(t.buf[pos:]))
+	if count > maxEntries || pos+2+count*12 > len(t.buf) {
+		return result, errInvalidExif
+	}
+
+	for i := 0; i < count; i++ {
+		start := pos + 2 + i*12
+		e := &entry{
+			kind:  t.order.Uint16(t.buf[start+2:]),
+			count: t.order.Uint32(t.buf[start+4:]),
+			pos:   start,
+		}
+
+		size, ok := typeSizes[e.kind]
+		if !ok || e.count > uint32(len(t.buf)) {
+			// unknown types are skipped
+			continue
+		}
+
+		length := size * int(e.count)
+		switch {
+		case length <= 4:
+			e.value = t.buf[start+8 : start+8+length]
+		default:
+			valueOffset := int(t.order.Uint32(t.buf[start+8:]))
+			if valueOffset < 0 || valueOffset+length > len(t.buf) {
+				continue
+			}
+			e.value = t.buf[valueOffset : valueOffset+length]
+		}
+
+		result.entries[t.order.Uint16(t.buf[start:])] = e
+	}
+
+	return result, nil
+}
+
+func (i ifd) string(tag uint16) string {
+	e := i.entries[tag]
+	if e == nil || e.kind != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (i ifd) uint(tag uint16) uint32 {
+	e := i.entries[tag]
+	if e == nil || e.count == 0 {
+		return 0
+	}
+
+	switch e.kind {
+	case 1, 7:
+		return uint32(e.value[0])
+	case 3:
+		return uint32(i.order.Uint16(e.value))
+	case 4:
+		return i.order.Uint32(e.value)
+	}
+	return 0
+}
+
+func (i ifd) rational(tag uint16, index int) float64 {
+	e := i.entries[tag]
+	if e == nil || (e.kind != 5 && e.kind != 10) || index >= int(e.count) {
+		return 0
+	}
+
+	numerator := i.order.Uint32(e.value[index*8:])
+	denominator := i.order.Uint32(e.value[index*8+4:])
+	if denominator == 0 {
+		return 0
+	}
+
+	value := float64(numerator) / float64(denominator)
+	if e.kind == 10 {
+		value = float64(int32(numerator)) / float64(int32(denominator))
+	}
+
+	// rounded to avoid values like 0.0166666667 for 1/60
+	return math.Round(value*1e6) / 1e6
+}
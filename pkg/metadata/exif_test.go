@@ -0,0 +1,262 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// tag is an IFD entry written by buildExif, values longer than 4 bytes are written after the entries.
+type tag struct {
+	id    uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+// buildExif builds a TIFF structure with IFD0 at offset 8, followed by the EXIF and GPS IFDs if they have tags.
+func buildExif(order binary.ByteOrder, ifd0, exif, gps []tag) []byte {
+	buf := make([]byte, 8)
+	if order == binary.LittleEndian {
+		copy(buf, "II*\x00")
+	} else {
+		copy(buf, "MM\x00*")
+	}
+	order.PutUint32(buf[4:], 8)
+
+	// pointers are LONG entries, so IFD0 size is known before their values
+	ifd0 = append([]tag(nil), ifd0...)
+	pointers := 0
+	for _, sub := range [][]tag{exif, gps} {
+		if len(sub) > 0 {
+			pointers++
+		}
+	}
+	offset := 8 + ifdSize(ifd0) + pointers*12
+	for i, sub := range [][]tag{exif, gps} {
+		if len(sub) == 0 {
+			continue
+		}
+		id := uint16(tagExifIFD)
+		if i == 1 {
+			id = tagGPSIFD
+		}
+		ifd0 = append(ifd0, long(order, id, uint32(offset)))
+		offset += ifdSize(sub)
+	}
+
+	for _, tags := range [][]tag{ifd0, exif, gps} {
+		if len(tags) > 0 {
+			buf = appendIFD(buf, order, tags)
+		}
+	}
+
+	return buf
+}
+
+func ifdSize(tags []tag) int {
+	size := 6 + 12*len(tags)
+	for _, t := range tags {
+		if len(t.value) > 4 {
+			size += len(t.value)
+		}
+	}
+	return size
+}
+
+func appendIFD(buf []byte, order binary.ByteOrder, tags []tag) []byte {
+	start := len(buf)
+	data := start + 6 + 12*len(tags)
+
+	ifd := make([]byte, ifdSize(tags))
+	order.PutUint16(ifd, uint16(len(tags)))
+	for i, t := range tags {
+		entry := ifd[2+i*12:]
+		order.PutUint16(entry, t.id)
+		order.PutUint16(entry[2:], t.kind)
+		order.PutUint32(entry[4:], t.count)
+		if len(t.value) <= 4 {
+			copy(entry[8:], t.value)
+			continue
+		}
+		order.PutUint32(entry[8:], uint32(data))
+		copy(ifd[data-start:], t.value)
+		data += len(t.value)
+	}
+
+	return append(buf, ifd...)
+}
+
+func ascii(id uint16, value string) tag {
+	return tag{id: id, kind: 2, count: uint32(len(value) + 1), value: append([]byte(value), 0)}
+}
+
+func short(order binary.ByteOrder, id uint16, value uint16) tag {
+	buf := make([]byte, 2)
+	order.PutUint16(buf, value)
+	return tag{id: id, kind: 3, count: 1, value: buf}
+}
+
+func long(order binary.ByteOrder, id uint16, value uint32) tag {
+	buf := make([]byte, 4)
+	order.PutUint32(buf, value)
+	return tag{id: id, kind: 4, count: 1, value: buf}
+}
+
+// rational takes numerator and denominator pairs
+func rational(order binary.ByteOrder, id uint16, values ...uint32) tag {
+	buf := make([]byte, 4*len(values))
+	for i, value := range values {
+		order.PutUint32(buf[i*4:], value)
+	}
+	return tag{id: id, kind: 5, count: uint32(len(values) / 2), value: buf}
+}
+
+func TestParseExif(t *testing.T) {
+	le, be := binary.LittleEndian, binary.BigEndian
+
+	tests := []struct {
+		name string
+		buf  []byte
+		want *Exif
+		err  bool
+	}{
+		{
+			name: "camera",
+			buf: buildExif(le,
+				[]tag{
+					ascii(tagMake, "Canon"),
+					ascii(tagModel, "EOS 5D "),
+					short(le, tagOrientation, 6),
+					ascii(tagDateTime, "2020:01:02 03:04:05"),
+				},
+				[]tag{
+					rational(le, tagExposureTime, 1, 60),
+					rational(le, tagFNumber, 28, 10),
+					short(le, tagISO, 200),
+					ascii(tagDateTimeOriginal, "not a date"),
+				},
+				[]tag{
+					ascii(tagGPSLatitudeRef, "S"),
+					rational(le, tagGPSLatitude, 30, 1, 15, 1, 0, 1),
+					ascii(tagGPSLongitudeRef, "E"),
+					rational(le, tagGPSLongitude, 31, 1, 0, 1, 36, 1),
+					{id: tagGPSAltitudeRef, kind: 1, count: 1, value: []byte{1}},
+					rational(le, tagGPSAltitude, 100, 1),
+				}),
+			want: &Exif{
+				Make:             "Canon",
+				Model:            "EOS 5D",
+				Orientation:      6,
+				DateTime:         "2020-01-02T03:04:05",
+				DateTimeOriginal: "not a date",
+				ExposureTime:     0.016667,
+				FNumber:          2.8,
+				ISO:              200,
+				GPS:              &GPS{Latitude: -30.25, Longitude: 31.01, Altitude: -100},
+			},
+		},
+		{
+			name: "big endian",
+			buf:  buildExif(be, []tag{short(be, tagOrientation, 3), ascii(tagSoftware, "prism")}, nil, nil),
+			want: &Exif{Orientation: 3, Software: "prism"},
+		},
+		{
+			name: "gps without longitude",
+			buf:  buildExif(le, []tag{short(le, tagOrientation, 1)}, nil, []tag{rational(le, tagGPSLatitude, 1, 1)}),
+			want: &Exif{Orientation: 1},
+		},
+		{
+			name: "value out of bounds is skipped",
+			buf:  buildExif(le, []tag{{id: tagMake, kind: 2, count: 1 << 20}, short(le, tagOrientation, 8)}, nil, nil),
+			want: &Exif{Orientation: 8},
+		},
+		{
+			name: "empty orientation",
+			buf:  buildExif(le, []tag{{id: tagOrientation, kind: 3}}, nil, nil),
+			want: &Exif{},
+		},
+		{name: "byte order", buf: []byte("XX*\x00\x08\x00\x00\x00"), err: true},
+		{name: "truncated", buf: []byte("II*\x00"), err: true},
+		{name: "ifd out of bounds", buf: []byte("II*\x00\xFF\x00\x00\x00"), err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExif(tt.buf)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseExif() error = %v, want error %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExif() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteExif(t *testing.T) {
+	le := binary.LittleEndian
+
+	tests := []struct {
+		name  string
+		exif  []byte
+		strip Strip
+		reset bool
+		want  *Exif
+	}{
+		{
+			name:  "reset orientation",
+			exif:  buildExif(le, []tag{short(le, tagOrientation, 6), ascii(tagMake, "Canon")}, nil, nil),
+			reset: true,
+			want:  &Exif{Orientation: 1, Make: "Canon"},
+		},
+		{
+			name:  "keep orientation",
+			exif:  buildExif(le, []tag{short(le, tagOrientation, 6)}, nil, nil),
+			strip: Strip{XMP: true},
+			want:  &Exif{Orientation: 6},
+		},
+		{
+			name:  "empty orientation",
+			exif:  buildExif(le, []tag{{id: tagOrientation, kind: 3}}, nil, nil),
+			reset: true,
+			want:  &Exif{},
+		},
+		{
+			name:  "clear gps",
+			exif:  buildExif(le, []tag{short(le, tagOrientation, 1)}, nil, []tag{rational(le, tagGPSLatitude, 1, 1), rational(le, tagGPSLongitude, 2, 1)}),
+			strip: Strip{GPS: true},
+			want:  &Exif{Orientation: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := Rewrite(jpegWith(0xE1, append(append([]byte(nil), exifHeader...), tt.exif...)), tt.strip, tt.reset)
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+
+			segments, err := Read(buf)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			got, err := ParseExif(segments.EXIF)
+			if err != nil {
+				t.Fatalf("ParseExif() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExif() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// jpegWith returns a JPEG with a single segment and an empty scan
+func jpegWith(marker byte, data []byte) []byte {
+	buf := []byte{0xFF, 0xD8, 0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(buf[4:], uint16(len(data)+2))
+	buf = append(buf, data...)
+	return append(buf, 0xFF, 0xDA, 0, 2, 0xFF, 0xD9)
+}
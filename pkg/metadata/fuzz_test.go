@@ -0,0 +1,41 @@
+//go:build go1.18
+// +build go1.18
+
+package metadata
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// FuzzMetadata checks that reading, parsing and rewriting metadata of any input doesn't panic.
+func FuzzMetadata(f *testing.F) {
+	le := binary.LittleEndian
+	exif := buildExif(le, []tag{short(le, tagOrientation, 6), ascii(tagMake, "Canon")}, []tag{short(le, tagISO, 100)},
+		[]tag{rational(le, tagGPSLatitude, 1, 1), rational(le, tagGPSLongitude, 2, 1)})
+
+	f.Add(exif)
+	f.Add(jpegWith(0xE1, append(append([]byte(nil), exifHeader...), exif...)))
+	f.Add(jpegWith(0xE1, append(append([]byte(nil), xmpHeader...), `<rdf:Description xmlns:rdf="`+rdfNamespace+`"/>`...)))
+	f.Add(dataset(2, 25, "keyword"))
+	f.Add([]byte("\x89PNG\r\n\x1a\n"))
+	f.Add([]byte("RIFF\x00\x00\x00\x00WEBPVP8X"))
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		_, _ = ParseExif(buf)
+		_ = ParseIPTC(buf)
+		_, _ = ParseXMP(buf)
+		_ = ProfileDescription(buf)
+
+		segments, err := Read(buf)
+		if err != nil {
+			return
+		}
+		if segments.EXIF != nil {
+			_, _ = ParseExif(segments.EXIF)
+		}
+		_ = ParseIPTC(segments.IPTC)
+
+		_, _ = Rewrite(buf, Strip{GPS: true, XMP: true, IPTC: true, ICC: true}, true)
+	})
+}
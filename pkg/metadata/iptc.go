@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// iptcDatasets maps IPTC application record (2) datasets to their payload.Data keys
+var iptcDatasets = map[byte]string{
+	5:   "title",
+	25:  "keywords",
+	55:  "date_created",
+	80:  "creator",
+	90:  "city",
+	95:  "state",
+	101: "country",
+	105: "headline",
+	110: "credit",
+	115: "source",
+	116: "copyright",
+	120: "caption",
+}
+
+// ParseIPTC parses IIM records into fields keyed as they're added to payload.Data, keywords are a list.
+func ParseIPTC(buf []byte) map[string]interface{} {
+	fields := make(map[string]interface{})
+	keywords := make([]string, 0)
+
+	pos := 0
+	for pos+5 <= len(buf) && buf[pos] == 0x1C {
+		record, dataset := buf[pos+1], buf[pos+2]
+		size := int(binary.BigEndian.Uint16(buf[pos+3:]))
+
+		// extended sizes are only used for large binary datasets
+		if size&0x8000 != 0 || pos+5+size > len(buf) {
+			break
+		}
+
+		value := strings.TrimSpace(string(buf[pos+5 : pos+5+size]))
+		pos += 5 + size
+
+		key, ok := iptcDatasets[dataset]
+		if record != 2 || !ok || value == "" {
+			continue
+		}
+
+		if key == "keywords" {
+			keywords = append(keywords, value)
+			continue
+		}
+
+		fields[key] = value
+	}
+
+	if len(keywords) > 0 {
+		fields["keywords"] = keywords
+	}
+
+	return fields
+}
@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// dataset returns an IIM record
+func dataset(record, number byte, value string) []byte {
+	buf := []byte{0x1C, record, number, 0, 0}
+	binary.BigEndian.PutUint16(buf[3:], uint16(len(value)))
+	return append(buf, value...)
+}
+
+func join(parts ...[]byte) []byte {
+	var buf []byte
+	for _, part := range parts {
+		buf = append(buf, part...)
+	}
+	return buf
+}
+
+func TestParseIPTC(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want map[string]interface{}
+	}{
+		{
+			name: "fields",
+			buf: join(
+				dataset(2, 5, "Sunset"),
+				dataset(2, 80, " Jane Doe "),
+				dataset(2, 25, "sea"),
+				dataset(2, 25, "sky"),
+				dataset(2, 116, "(c) Jane"),
+			),
+			want: map[string]interface{}{
+				"title":     "Sunset",
+				"creator":   "Jane Doe",
+				"keywords":  []string{"sea", "sky"},
+				"copyright": "(c) Jane",
+			},
+		},
+		{
+			name: "other records and unknown datasets are skipped",
+			buf:  join(dataset(1, 90, "\x1b%G"), dataset(2, 200, "unknown"), dataset(2, 90, ""), dataset(2, 90, "Cairo")),
+			want: map[string]interface{}{"city": "Cairo"},
+		},
+		{
+			name: "truncated dataset",
+			buf:  join(dataset(2, 5, "Sunset"), dataset(2, 120, "caption")[:8]),
+			want: map[string]interface{}{"title": "Sunset"},
+		},
+		{
+			name: "extended size",
+			buf:  join(dataset(2, 5, "Sunset"), []byte{0x1C, 2, 120, 0x80, 0x04, 0, 0, 0, 1}),
+			want: map[string]interface{}{"title": "Sunset"},
+		},
+		{name: "not iim", buf: []byte("garbage"), want: map[string]interface{}{}},
+		{name: "empty", buf: nil, want: map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseIPTC(tt.buf)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIPTC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+// Package metadata reads and rewrites EXIF, IPTC, XMP and ICC metadata embedded in JPEG, PNG, WebP and TIFF images
+// without decoding their pixels.
+package metadata
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+)
+
+// Fields are the payload.Data keys under which extracted metadata is reported.
+const (
+	ExifField = "_exif"
+	IPTCField = "_iptc"
+	XMPField  = "_xmp"
+
+	// ColorspaceField is the colour space of the image, named as the vips colourspace operation names them (e.g srgb,
+	// cmyk, b-w).
+	ColorspaceField = "_colorspace"
+	// ProfileField is the description of the embedded ICC profile.
+	ProfileField = "_icc_profile"
+)
+
+var errTruncated = errors.New("truncated metadata")
+
+var (
+	exifHeader      = []byte("Exif\x00\x00")
+	xmpHeader       = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	photoshopHeader = []byte("Photoshop 3.0\x00")
+	iccHeader       = []byte("ICC_PROFILE\x00")
+	pngSignature    = []byte("\x89PNG\r\n\x1a\n")
+	pngXMPKeyword   = []byte("XML:com.adobe.xmp")
+)
+
+// Segments are the raw metadata blocks found in an image, a block is nil if the image doesn't have it.
+type Segments struct {
+	// EXIF is a TIFF structure (starts with the byte order mark).
+	EXIF []byte
+	// IPTC is a sequence of IIM records.
+	IPTC []byte
+	// XMP is an XML packet.
+	XMP []byte
+	// ICC is an ICC profile.
+	ICC []byte
+}
+
+// Read finds the metadata segments of a JPEG, PNG, WebP or TIFF image, other formats have no segments.
+func Read(buf []byte) (Segments, error) {
+	switch {
+	case bytes.HasPrefix(buf, []byte{0xFF, 0xD8}):
+		return readJPEG(buf)
+	case bytes.HasPrefix(buf, pngSignature):
+		return readPNG(buf)
+	case isWebP(buf):
+		return readWebP(buf)
+	case bytes.HasPrefix(buf, []byte("II*\x00")), bytes.HasPrefix(buf, []byte("MM\x00*")):
+		// a TIFF file is itself the EXIF structure
+		return Segments{EXIF: buf}, nil
+	default:
+		return Segments{}, nil
+	}
+}
+
+func isWebP(buf []byte) bool {
+	return len(buf) >= 12 && bytes.Equal(buf[0:4], []byte("RIFF")) && bytes.Equal(buf[8:12], []byte("WEBP"))
+}
+
+//------------------------------------------------------------------------------
+
+// jpegSegment is an APPn/COM segment before the image data, pos is where its marker starts.
+type jpegSegment struct {
+	marker byte
+	pos    int
+	data   []byte
+}
+
+// walkJPEG calls fn for every segment before the start of scan and returns where the image data starts.
+func walkJPEG(buf []byte, fn func(segment jpegSegment) error) (int, error) {
+	pos := 2
+	for pos+4 <= len(buf) {
+		if buf[pos] != 0xFF {
+			return 0, errors.New("invalid jpeg marker")
+		}
+
+		marker := buf[pos+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// standalone markers
+			pos += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// start of scan or end of image, no metadata after this
+			return pos, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(buf[pos+2:]))
+		if length < 2 || pos+2+length > len(buf) {
+			return 0, errTruncated
+		}
+
+		err := fn(jpegSegment{marker: marker, pos: pos, data: buf[pos+4 : pos+2+length]})
+		if err != nil {
+			return 0, err
+		}
+
+		pos += 2 + length
+	}
+
+	return 0, errTruncated
+}
+
+func readJPEG(buf []byte) (Segments, error) {
+	segments := Segments{}
+
+	_, err := walkJPEG(buf, func(segment jpegSegment) error {
+		data := segment.data
+		switch {
+		case segment.marker == 0xE1 && bytes.HasPrefix(data, exifHeader) && segments.EXIF == nil:
+			segments.EXIF = data[len(exifHeader):]
+		case segment.marker == 0xE1 && bytes.HasPrefix(data, xmpHeader) && segments.XMP == nil:
+			segments.XMP = data[len(xmpHeader):]
+		case segment.marker == 0xED && bytes.HasPrefix(data, photoshopHeader) && segments.IPTC == nil:
+			segments.IPTC = photoshopIPTC(data[len(photoshopHeader):])
+		case segment.marker == 0xE2 && bytes.HasPrefix(data, iccHeader) && len(data) > len(iccHeader)+2:
+			// profile can span multiple segments (ordered), skip sequence number and count.
+			segments.ICC = append(segments.ICC, data[len(iccHeader)+2:]...)
+		}
+		return nil
+	})
+
+	return segments, err
+}
+
+// photoshopIPTC returns the IPTC resource (0x0404) from Photoshop image resource blocks.
+func photoshopIPTC(buf []byte) []byte {
+	pos := 0
+	for pos+8 <= len(buf) && bytes.Equal(buf[pos:pos+4], []byte("8BIM")) {
+		id := binary.BigEndian.Uint16(buf[pos+4:])
+
+		// pascal string name, padded to even length
+		nameLength := int(buf[pos+6]) + 1
+		if nameLength%2 != 0 {
+			nameLength++
+		}
+
+		pos += 6 + nameLength
+		if pos+4 > len(buf) {
+			return nil
+		}
+
+		size := int(binary.BigEndian.Uint32(buf[pos:]))
+		pos += 4
+		if size < 0 || pos+size > len(buf) {
+			return nil
+		}
+
+		if id == 0x0404 {
+			return buf[pos : pos+size]
+		}
+
+		pos += size + size%2
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// pngChunk is a PNG chunk, pos is where its length starts.
+type pngChunk struct {
+	kind string
+	pos  int
+	data []byte
+}
+
+// walkPNG calls fn for every chunk of a PNG image.
+func walkPNG(buf []byte, fn func(chunk pngChunk) error) error {
+	pos := len(pngSignature)
+	for pos+12 <= len(buf) {
+		length := int(binary.BigEndian.Uint32(buf[pos:]))
+		if length < 0 || pos+12+length > len(buf) {
+			return errTruncated
+		}
+
+		err := fn(pngChunk{kind: string(buf[pos+4 : pos+8]), pos: pos, data: buf[pos+8 : pos+8+length]})
+		if err != nil {
+			return err
+		}
+
+		pos += 12 + length
+	}
+	return nil
+}
+
+func readPNG(buf []byte) (Segments, error) {
+	segments := Segments{}
+
+	err := walkPNG(buf, func(chunk pngChunk) error {
+		var err error
+		switch chunk.kind {
+		case "eXIf":
+			segments.EXIF = chunk.data
+		case "iTXt":
+			if isPNGXMP(chunk.data) {
+				segments.XMP, err = pngText(chunk.data)
+			}
+		case "iCCP":
+			// profile name, null separator, compression method then zlib stream
+			name := bytes.IndexByte(chunk.data, 0)
+			if name < 0 || name+2 > len(chunk.data) {
+				return errTruncated
+			}
+			segments.ICC, err = inflate(chunk.data[name+2:])
+		}
+		return err
+	})
+
+	return segments, err
+}
+
+func isPNGXMP(data []byte) bool {
+	return bytes.HasPrefix(data, pngXMPKeyword) && len(data) > len(pngXMPKeyword) && data[len(pngXMPKeyword)] == 0
+}
+
+// pngText returns the text of an iTXt chunk
+func pngText(data []byte) ([]byte, error) {
+	// keyword, null separator, compression flag and method
+	pos := bytes.IndexByte(data, 0) + 1
+	if pos+2 > len(data) {
+		return nil, errTruncated
+	}
+	compressed := data[pos] == 1
+	pos += 2
+
+	// language tag and translated keyword are null terminated
+	for i := 0; i < 2; i++ {
+		end := bytes.IndexByte(data[pos:], 0)
+		if end < 0 {
+			return nil, errTruncated
+		}
+		pos += end + 1
+	}
+
+	if compressed {
+		return inflate(data[pos:])
+	}
+	return data[pos:], nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+//------------------------------------------------------------------------------
+
+// riffChunk is a WebP chunk, pos is where its fourCC starts.
+type riffChunk struct {
+	fourCC string
+	pos    int
+	data   []byte
+}
+
+// walkWebP calls fn for every chunk of a WebP image.
+func walkWebP(buf []byte, fn func(chunk riffChunk) error) error {
+	pos := 12
+	for pos+8 <= len(buf) {
+		size := int(binary.LittleEndian.Uint32(buf[pos+4:]))
+		if size < 0 || pos+8+size > len(buf) {
+			return errTruncated
+		}
+
+		err := fn(riffChunk{fourCC: string(buf[pos : pos+4]), pos: pos, data: buf[pos+8 : pos+8+size]})
+		if err != nil {
+			return err
+		}
+
+		// chunks are padded to even size
+		pos += 8 + size + size%2
+	}
+	return nil
+}
+
+func readWebP(buf []byte) (Segments, error) {
+	segments := Segments{}
+
+	err := walkWebP(buf, func(chunk riffChunk) error {
+		switch chunk.fourCC {
+		case "EXIF":
+			// some encoders keep the jpeg app1 header
+			segments.EXIF = bytes.TrimPrefix(chunk.data, exifHeader)
+		case "XMP ":
+			segments.XMP = chunk.data
+		case "ICCP":
+			segments.ICC = chunk.data
+		}
+		return nil
+	})
+
+	return segments, err
+}
+
+//------------------------------------------------------------------------------
+
+// ProfileDescription returns the description of an ICC profile (e.g "Adobe RGB (1998)"), empty if it has none.
+func ProfileDescription(icc []byte) string {
+	if len(icc) < 132 {
+		return ""
+	}
+
+	count := int(binary.BigEndian.Uint32(icc[128:]))
+	for i := 0; i < count && 132+i*12+12 <= len(icc); i++ {
+		tag := icc[132+i*12:]
+		if string(tag[0:4]) != "desc" {
+			continue
+		}
+
+		offset := int(binary.BigEndian.Uint32(tag[4:]))
+		size := int(binary.BigEndian.Uint32(tag[8:]))
+		if offset < 0 || size < 12 || offset+size > len(icc) {
+			return ""
+		}
+
+		return textDescription(icc[offset : offset+size])
+	}
+
+	return ""
+}
+
+// ProfileColorspace returns the colour space of an ICC profile named as ColorspaceField, empty if it's unknown.
+func ProfileColorspace(icc []byte) string {
+	if len(icc) < 20 {
+		return ""
+	}
+
+	switch string(icc[16:20]) {
+	case "RGB ":
+		return "srgb"
+	case "GRAY":
+		return "b-w"
+	case "CMYK":
+		return "cmyk"
+	case "Lab ":
+		return "lab"
+	case "XYZ ":
+		return "xyz"
+	default:
+		return ""
+	}
+}
+
+// textDescription decodes ICC v2 textDescriptionType or the first record of ICC v4 multiLocalizedUnicodeType
+func textDescription(data []byte) string {
+	switch string(data[0:4]) {
+	case "desc":
+		length := int(binary.BigEndian.Uint32(data[8:]))
+		if length < 0 || 12+length > len(data) {
+			return ""
+		}
+		return strings.TrimRight(string(data[12:12+length]), "\x00")
+	case "mluc":
+		if len(data) < 28 || binary.BigEndian.Uint32(data[8:]) == 0 {
+			return ""
+		}
+		length := int(binary.BigEndian.Uint32(data[20:]))
+		offset := int(binary.BigEndian.Uint32(data[24:]))
+		if length < 0 || offset < 0 || offset+length > len(data) {
+			return ""
+		}
+
+		// UTF-16BE
+		runes := make([]uint16, length/2)
+		for i := range runes {
+			runes[i] = binary.BigEndian.Uint16(data[offset+i*2:])
+		}
+		return strings.TrimRight(string(utf16.Decode(runes)), "\x00")
+	}
+	return ""
+}
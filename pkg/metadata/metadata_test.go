@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// iccProfile returns an ICC v2 profile header of space with a single desc tag
+func iccProfile(space, description string) []byte {
+	buf := make([]byte, 144)
+	copy(buf[16:], space)
+	binary.BigEndian.PutUint32(buf[128:], 1)
+	copy(buf[132:], "desc")
+	binary.BigEndian.PutUint32(buf[136:], 144)
+	binary.BigEndian.PutUint32(buf[140:], uint32(12+len(description)+1))
+
+	desc := make([]byte, 12)
+	copy(desc, "desc")
+	binary.BigEndian.PutUint32(desc[8:], uint32(len(description)+1))
+	desc = append(append(desc, description...), 0)
+
+	return append(buf, desc...)
+}
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		icc         []byte
+		description string
+		space       string
+	}{
+		{name: "rgb", icc: iccProfile("RGB ", "Adobe RGB (1998)"), description: "Adobe RGB (1998)", space: "srgb"},
+		{name: "gray", icc: iccProfile("GRAY", "Dot Gain 20%"), description: "Dot Gain 20%", space: "b-w"},
+		{name: "cmyk", icc: iccProfile("CMYK", "U.S. Web Coated (SWOP) v2"), description: "U.S. Web Coated (SWOP) v2", space: "cmyk"},
+		{name: "unknown space", icc: iccProfile("YCbr", "YCbCr"), description: "YCbCr"},
+		{name: "tag out of bounds", icc: iccProfile("RGB ", "sRGB")[:150], space: "srgb"},
+		{name: "truncated", icc: []byte("short")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileDescription(tt.icc); got != tt.description {
+				t.Errorf("ProfileDescription() = %q, want %q", got, tt.description)
+			}
+			if got := ProfileColorspace(tt.icc); got != tt.space {
+				t.Errorf("ProfileColorspace() = %q, want %q", got, tt.space)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// Strip selects which metadata Rewrite removes, GPS removes only the location from EXIF keeping the rest of it.
+type Strip struct {
+	EXIF bool
+	GPS  bool
+	IPTC bool
+	XMP  bool
+	ICC  bool
+}
+
+// VP8X flags of metadata chunks
+const (
+	webpICCFlag  = 0x20
+	webpEXIFFlag = 0x08
+	webpXMPFlag  = 0x04
+)
+
+// Rewrite removes the selected metadata from a JPEG, PNG or WebP image, if resetOrientation is true EXIF orientation is
+// set to normal (used after the image is rotated). Other formats are returned as is.
+func Rewrite(buf []byte, strip Strip, resetOrientation bool) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(buf, []byte{0xFF, 0xD8}):
+		return rewriteJPEG(buf, strip, resetOrientation)
+	case bytes.HasPrefix(buf, pngSignature):
+		return rewritePNG(buf, strip, resetOrientation)
+	case isWebP(buf):
+		return rewriteWebP(buf, strip, resetOrientation)
+	default:
+		return buf, nil
+	}
+}
+
+func rewriteJPEG(buf []byte, strip Strip, resetOrientation bool) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(buf)))
+	out.Write(buf[0:2])
+
+	last := 2
+	scan, err := walkJPEG(buf, func(segment jpegSegment) error {
+		// copy fill bytes and standalone markers before the segment
+		out.Write(buf[last:segment.pos])
+		last = segment.pos + 4 + len(segment.data)
+
+		data := segment.data
+		switch {
+		case segment.marker == 0xE1 && bytes.HasPrefix(data, exifHeader):
+			if strip.EXIF {
+				return nil
+			}
+			exif, err := editExif(data[len(exifHeader):], strip.GPS, resetOrientation)
+			if err != nil {
+				return err
+			}
+			out.Write(buf[segment.pos : segment.pos+4])
+			out.Write(exifHeader)
+			out.Write(exif)
+			return nil
+		case segment.marker == 0xE1 && bytes.HasPrefix(data, xmpHeader) && strip.XMP,
+			segment.marker == 0xED && bytes.HasPrefix(data, photoshopHeader) && strip.IPTC,
+			segment.marker == 0xE2 && bytes.HasPrefix(data, iccHeader) && strip.ICC:
+			return nil
+		}
+
+		out.Write(buf[segment.pos:last])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out.Write(buf[last:scan])
+	out.Write(buf[scan:])
+	return out.Bytes(), nil
+}
+
+func rewritePNG(buf []byte, strip Strip, resetOrientation bool) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(buf)))
+	out.Write(pngSignature)
+
+	err := walkPNG(buf, func(chunk pngChunk) error {
+		end := chunk.pos + 12 + len(chunk.data)
+
+		switch {
+		case chunk.kind == "eXIf":
+			if strip.EXIF {
+				return nil
+			}
+			exif, err := editExif(chunk.data, strip.GPS, resetOrientation)
+			if err != nil {
+				return err
+			}
+			// edits keep the size, only crc changes
+			out.Write(buf[chunk.pos : chunk.pos+8])
+			out.Write(exif)
+			crc := crc32.NewIEEE()
+			_, _ = crc.Write(buf[chunk.pos+4 : chunk.pos+8])
+			_, _ = crc.Write(exif)
+			_ = binary.Write(out, binary.BigEndian, crc.Sum32())
+			return nil
+		case chunk.kind == "iTXt" && isPNGXMP(chunk.data) && strip.XMP,
+			chunk.kind == "iCCP" && strip.ICC,
+			(chunk.kind == "zTXt" || chunk.kind == "tEXt") && bytes.HasPrefix(chunk.data, []byte("Raw profile type iptc")) && strip.IPTC:
+			return nil
+		}
+
+		out.Write(buf[chunk.pos:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func rewriteWebP(buf []byte, strip Strip, resetOrientation bool) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(buf)))
+	out.Write(buf[0:12])
+
+	// position of VP8X flags in the output, only extended format webp can have metadata.
+	flags := -1
+	var removed byte
+
+	err := walkWebP(buf, func(chunk riffChunk) error {
+		end := chunk.pos + 8 + len(chunk.data) + len(chunk.data)%2
+		if end > len(buf) {
+			end = len(buf)
+		}
+
+		switch {
+		case chunk.fourCC == "VP8X":
+			flags = out.Len() + 8
+		case chunk.fourCC == "EXIF":
+			if strip.EXIF {
+				removed |= webpEXIFFlag
+				return nil
+			}
+			prefix := 0
+			if bytes.HasPrefix(chunk.data, exifHeader) {
+				prefix = len(exifHeader)
+			}
+			exif, err := editExif(chunk.data[prefix:], strip.GPS, resetOrientation)
+			if err != nil {
+				return err
+			}
+			out.Write(buf[chunk.pos : chunk.pos+8+prefix])
+			out.Write(exif)
+			out.Write(buf[chunk.pos+8+len(chunk.data) : end])
+			return nil
+		case chunk.fourCC == "XMP " && strip.XMP:
+			removed |= webpXMPFlag
+			return nil
+		case chunk.fourCC == "ICCP" && strip.ICC:
+			removed |= webpICCFlag
+			return nil
+		}
+
+		out.Write(buf[chunk.pos:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := out.Bytes()
+	if flags >= 0 && flags < len(result) {
+		result[flags] &^= removed
+	}
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+
+	return result, nil
+}
+
+// editExif returns a copy of the EXIF structure with GPS cleared and/or orientation reset, size is kept the same so
+// offsets in the structure stay valid.
+func editExif(buf []byte, clearGPS, resetOrientation bool) ([]byte, error) {
+	if !clearGPS && !resetOrientation {
+		return buf, nil
+	}
+
+	t, err := newTIFF(append([]byte(nil), buf...))
+	if err != nil {
+		return nil, err
+	}
+
+	ifd0, err := t.ifd(t.first())
+	if err != nil {
+		return nil, err
+	}
+
+	if orientation := ifd0.entries[tagOrientation]; resetOrientation && orientation != nil && orientation.kind == 3 &&
+		len(orientation.value) >= 2 {
+		t.order.PutUint16(orientation.value, 1)
+	}
+
+	if offset := ifd0.uint(tagGPSIFD); clearGPS && offset != 0 {
+		gps, err := t.ifd(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		// zero all values and entries, then leave an empty IFD.
+		for _, e := range gps.entries {
+			for i := range e.value {
+				e.value[i] = 0
+			}
+		}
+		count := int(t.order.Uint16(t.buf[offset:]))
+		for i := int(offset) + 2; i < int(offset)+2+count*12; i++ {
+			t.buf[i] = 0
+		}
+		t.order.PutUint16(t.buf[offset:], 0)
+	}
+
+	return t.buf, nil
+}
+
+// CopyPNG copies the metadata chunks (EXIF, ICC profile and text) of src into the PNG dst, after its header. It's used
+// when an image is re-encoded by an encoder that drops them.
+func CopyPNG(dst, src []byte) ([]byte, error) {
+	if !bytes.HasPrefix(dst, pngSignature) || !bytes.HasPrefix(src, pngSignature) {
+		return nil, errTruncated
+	}
+
+	chunks := &bytes.Buffer{}
+	err := walkPNG(src, func(chunk pngChunk) error {
+		switch chunk.kind {
+		case "eXIf", "iCCP", "iTXt", "tEXt", "zTXt":
+			chunks.Write(src[chunk.pos : chunk.pos+12+len(chunk.data)])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// IHDR is always first, the profile must come before the palette and image data.
+	header := len(pngSignature)
+	if header+12 > len(dst) {
+		return nil, errTruncated
+	}
+	header += 12 + int(binary.BigEndian.Uint32(dst[header:]))
+	if header > len(dst) {
+		return nil, errTruncated
+	}
+
+	out := make([]byte, 0, len(dst)+chunks.Len())
+	out = append(out, dst[:header]...)
+	out = append(out, chunks.Bytes()...)
+	return append(out, dst[header:]...), nil
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+// pngChunkOf return a PNG chunk with its length and crc
+func pngChunkOf(kind string, data []byte) []byte {
+	buf := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	buf = append(append(buf, kind...), data...)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf[4:]))
+}
+
+// encodePNG return a PNG of size pixels with chunks inserted after its header
+func encodePNG(t *testing.T, size int, chunks ...[]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	// signature and IHDR
+	header := len(pngSignature) + 12 + 13
+	out := append([]byte(nil), buf.Bytes()[:header]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, buf.Bytes()[header:]...)
+}
+
+func TestCopyPNG(t *testing.T) {
+	le := binary.LittleEndian
+	exif := buildExif(le, []tag{short(le, tagOrientation, 6)}, nil, nil)
+	text := pngChunkOf("tEXt", []byte("Comment\x00prism"))
+	src := encodePNG(t, 4, pngChunkOf("eXIf", exif), text, pngChunkOf("gAMA", []byte{0, 0, 0xB1, 0x8F}))
+
+	got, err := CopyPNG(encodePNG(t, 2), src)
+	if err != nil {
+		t.Fatalf("CopyPNG() error = %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if img.Bounds().Dx() != 2 {
+		t.Errorf("CopyPNG() image width = %d, want the destination image", img.Bounds().Dx())
+	}
+
+	segments, err := Read(got)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(segments.EXIF, exif) {
+		t.Errorf("CopyPNG() EXIF = %v, want %v", segments.EXIF, exif)
+	}
+	if !bytes.Contains(got, text) || bytes.Contains(got, []byte("gAMA")) {
+		t.Errorf("CopyPNG() must copy text chunks and only metadata chunks")
+	}
+
+	if _, err := CopyPNG([]byte("GIF89a"), src); err == nil {
+		t.Errorf("CopyPNG() into a GIF error = nil")
+	}
+}
@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// xmpNamespaces maps known XMP namespaces to the prefixes used in payload.Data keys, others are ignored.
+var xmpNamespaces = map[string]string{
+	"http://purl.org/dc/elements/1.1/":             "dc",
+	"http://ns.adobe.com/xap/1.0/":                 "xmp",
+	"http://ns.adobe.com/xap/1.0/rights/":          "xmpRights",
+	"http://ns.adobe.com/photoshop/1.0/":           "photoshop",
+	"http://ns.adobe.com/tiff/1.0/":                "tiff",
+	"http://ns.adobe.com/exif/1.0/":                "exif",
+	"http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/":  "Iptc4xmpCore",
+	"http://ns.adobe.com/lightroom/1.0/":           "lr",
+	"http://ns.adobe.com/xap/1.0/mm/":              "xmpMM",
+	"http://cipa.jp/exif/1.0/":                     "exifEX",
+	"http://ns.adobe.com/camera-raw-settings/1.0/": "crs",
+}
+
+// ParseXMP parses simple XMP properties into fields keyed as "prefix:Name", array values are joined by ", ".
+func ParseXMP(buf []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	decoder := xml.NewDecoder(bytes.NewReader(buf))
+
+	var property string
+	var values []string
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// keep what was parsed of a truncated packet
+			if err == io.EOF || len(fields) > 0 {
+				return fields, nil
+			}
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// properties in short form are attributes of rdf:Description
+			if t.Name.Space == rdfNamespace && t.Name.Local == "Description" {
+				for _, attr := range t.Attr {
+					if key, ok := xmpKey(attr.Name); ok && attr.Value != "" {
+						fields[key] = attr.Value
+					}
+				}
+				continue
+			}
+
+			if key, ok := xmpKey(t.Name); ok && property == "" {
+				property = key
+				values = values[:0]
+			}
+		case xml.CharData:
+			if value := strings.TrimSpace(string(t)); property != "" && value != "" {
+				values = append(values, value)
+			}
+		case xml.EndElement:
+			if key, ok := xmpKey(t.Name); ok && key == property {
+				if len(values) > 0 {
+					fields[property] = strings.Join(values, ", ")
+				}
+				property = ""
+			}
+		}
+	}
+}
+
+func xmpKey(name xml.Name) (string, bool) {
+	prefix, ok := xmpNamespaces[name.Space]
+	if !ok {
+		return "", false
+	}
+	return prefix + ":" + name.Local, true
+}
@@ -0,0 +1,54 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseXMP(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want map[string]interface{}
+		err  bool
+	}{
+		{
+			name: "attributes and elements",
+			buf: `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:other="http://example.com/ns/" xmp:CreatorTool="prism" xmp:Label="" other:Ignored="yes">
+	<dc:subject><rdf:Bag><rdf:li>sea</rdf:li><rdf:li> sky </rdf:li></rdf:Bag></dc:subject>
+	<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Sunset</rdf:li></rdf:Alt></dc:title>
+	<other:Value>ignored</other:Value>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>`,
+			want: map[string]interface{}{
+				"xmp:CreatorTool": "prism",
+				"dc:subject":      "sea, sky",
+				"dc:title":        "Sunset",
+			},
+		},
+		{
+			name: "truncated packet keeps parsed fields",
+			buf: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description xmlns:tiff="http://ns.adobe.com/tiff/1.0/" tiff:Make="Canon"><tiff:Model>EOS`,
+			want: map[string]interface{}{"tiff:Make": "Canon"},
+		},
+		{name: "empty", buf: "", want: map[string]interface{}{}},
+		{name: "invalid", buf: "<a></b>", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseXMP([]byte(tt.buf))
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseXMP() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseXMP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
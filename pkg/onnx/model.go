@@ -0,0 +1,385 @@
+// Package onnx evaluates ONNX models on the CPU in pure Go, it supports the float operators used by common image
+// classifiers (convolutions, pooling, normalization, activations and fully connected layers).
+package onnx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Model is an ONNX model graph evaluated on the CPU, it's immutable after loading so it can be run concurrently.
+type Model struct {
+	opset        int
+	nodes        []node
+	initializers map[string]*Tensor
+	inputs       []Value
+	outputs      []Value
+}
+
+// Value is a graph input or output, dynamic dimensions are -1.
+type Value struct {
+	Name  string
+	Shape []int
+}
+
+type node struct {
+	op      string
+	name    string
+	inputs  []string
+	outputs []string
+	attrs   map[string]attribute
+}
+
+type attribute struct {
+	f      float32
+	i      int64
+	s      string
+	t      *Tensor
+	floats []float32
+	ints   []int64
+}
+
+// Load decodes an ONNX model, it fails if the model uses operators that are not supported.
+func Load(buf []byte) (*Model, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Model{initializers: make(map[string]*Tensor)}
+	var graph []byte
+	for _, f := range message {
+		switch f.number {
+		case 7:
+			graph = f.bytes
+		case 8:
+			domain, version, err := decodeOpset(f.bytes)
+			if err != nil {
+				return nil, err
+			}
+			if domain == "" || domain == "ai.onnx" {
+				m.opset = version
+			}
+		}
+	}
+
+	if graph == nil {
+		return nil, fmt.Errorf("model has no graph")
+	}
+
+	err = m.decodeGraph(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range m.nodes {
+		if _, ok := operators[n.op]; !ok {
+			return nil, fmt.Errorf("operator %s (node %s) is not supported", n.op, n.name)
+		}
+	}
+
+	if len(m.inputs) == 0 || len(m.outputs) == 0 {
+		return nil, fmt.Errorf("model has no inputs or outputs")
+	}
+
+	return m, nil
+}
+
+// Inputs return the graph inputs that are not initializers
+func (m *Model) Inputs() []Value {
+	return m.inputs
+}
+
+// Outputs return the graph outputs
+func (m *Model) Outputs() []Value {
+	return m.outputs
+}
+
+// Run evaluates the graph on inputs keyed by name, returns the graph outputs keyed by name.
+func (m *Model) Run(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+	values := make(map[string]*Tensor, len(m.initializers)+len(m.nodes))
+	for name, tensor := range m.initializers {
+		values[name] = tensor
+	}
+
+	for _, input := range m.inputs {
+		tensor, ok := inputs[input.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing input %s", input.Name)
+		}
+		values[input.Name] = tensor
+	}
+
+	for _, n := range m.nodes {
+		args := make([]*Tensor, len(n.inputs))
+		for i, name := range n.inputs {
+			// empty names are omitted optional inputs
+			if name == "" {
+				continue
+			}
+			tensor, ok := values[name]
+			if !ok {
+				return nil, fmt.Errorf("node %s: input %s is not computed", n.name, name)
+			}
+			args[i] = tensor
+		}
+
+		results, err := operators[n.op](m, n, args)
+		if err != nil {
+			return nil, fmt.Errorf("node %s (%s): %s", n.name, n.op, err.Error())
+		}
+
+		for i, name := range n.outputs {
+			if i < len(results) && name != "" {
+				values[name] = results[i]
+			}
+		}
+	}
+
+	outputs := make(map[string]*Tensor, len(m.outputs))
+	for _, output := range m.outputs {
+		tensor, ok := values[output.Name]
+		if !ok {
+			return nil, fmt.Errorf("output %s is not computed", output.Name)
+		}
+		outputs[output.Name] = tensor
+	}
+
+	return outputs, nil
+}
+
+func decodeOpset(buf []byte) (string, int, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var domain string
+	var version int
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			domain = string(f.bytes)
+		case 2:
+			version = int(f.value)
+		}
+	}
+
+	return domain, version, nil
+}
+
+func (m *Model) decodeGraph(buf []byte) error {
+	message, err := fields(buf)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]Value, 0)
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			n, err := decodeNode(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.nodes = append(m.nodes, n)
+		case 5:
+			name, tensor, err := decodeTensor(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.initializers[name] = tensor
+		case 11, 12:
+			value, err := decodeValue(f.bytes)
+			if err != nil {
+				return err
+			}
+			if f.number == 11 {
+				inputs = append(inputs, value)
+			} else {
+				m.outputs = append(m.outputs, value)
+			}
+		}
+	}
+
+	// older models list initializers as inputs too
+	for _, input := range inputs {
+		if _, ok := m.initializers[input.Name]; !ok {
+			m.inputs = append(m.inputs, input)
+		}
+	}
+
+	return nil
+}
+
+func decodeNode(buf []byte) (node, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return node{}, err
+	}
+
+	n := node{attrs: make(map[string]attribute)}
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			n.inputs = append(n.inputs, string(f.bytes))
+		case 2:
+			n.outputs = append(n.outputs, string(f.bytes))
+		case 3:
+			n.name = string(f.bytes)
+		case 4:
+			n.op = string(f.bytes)
+		case 5:
+			name, attr, err := decodeAttribute(f.bytes)
+			if err != nil {
+				return node{}, err
+			}
+			n.attrs[name] = attr
+		case 7:
+			if domain := string(f.bytes); domain != "" && domain != "ai.onnx" {
+				return node{}, fmt.Errorf("operator domain %s is not supported", domain)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+func decodeAttribute(buf []byte) (string, attribute, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return "", attribute{}, err
+	}
+
+	var name string
+	var attr attribute
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			name = string(f.bytes)
+		case 2:
+			attr.f = math.Float32frombits(uint32(f.value))
+		case 3:
+			attr.i = int64(f.value)
+		case 4:
+			attr.s = string(f.bytes)
+		case 5:
+			_, attr.t, err = decodeTensor(f.bytes)
+		case 7:
+			var values []float32
+			values, err = f.floats()
+			attr.floats = append(attr.floats, values...)
+		case 8:
+			var values []int64
+			values, err = f.ints()
+			attr.ints = append(attr.ints, values...)
+		}
+		if err != nil {
+			return "", attribute{}, err
+		}
+	}
+
+	return name, attr, nil
+}
+
+// decodeValue decodes a ValueInfoProto, only tensor types are supported.
+func decodeValue(buf []byte) (Value, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return Value{}, err
+	}
+
+	value := Value{Shape: make([]int, 0)}
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			value.Name = string(f.bytes)
+		case 2:
+			value.Shape, err = decodeShape(f.bytes)
+			if err != nil {
+				return Value{}, err
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// decodeShape return the shape of a TypeProto (type.tensor_type.shape.dim), dynamic dimensions are -1.
+func decodeShape(buf []byte) ([]int, error) {
+	shape := make([]int, 0)
+
+	// TypeProto.tensor_type -> TypeProto.Tensor.shape -> TensorShapeProto.dim
+	path := []int{1, 2, 1}
+	var walk func(buf []byte, depth int) error
+	walk = func(buf []byte, depth int) error {
+		message, err := fields(buf)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range message {
+			if f.number != path[depth] || f.wire != wireBytes {
+				continue
+			}
+
+			if depth < len(path)-1 {
+				if err := walk(f.bytes, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+
+			dim, err := fields(f.bytes)
+			if err != nil {
+				return err
+			}
+
+			value := -1
+			for _, d := range dim {
+				if d.number == 1 && d.wire == wireVarint {
+					value = int(d.value)
+				}
+			}
+			shape = append(shape, value)
+		}
+
+		return nil
+	}
+
+	return shape, walk(buf, 0)
+}
+
+func (n node) int(name string, def int) int {
+	if attr, ok := n.attrs[name]; ok {
+		return int(attr.i)
+	}
+	return def
+}
+
+func (n node) float(name string, def float32) float32 {
+	if attr, ok := n.attrs[name]; ok {
+		return attr.f
+	}
+	return def
+}
+
+func (n node) string(name string, def string) string {
+	if attr, ok := n.attrs[name]; ok {
+		return attr.s
+	}
+	return def
+}
+
+func (n node) ints(name string, def []int) []int {
+	attr, ok := n.attrs[name]
+	if !ok {
+		return def
+	}
+
+	result := make([]int, len(attr.ints))
+	for i, value := range attr.ints {
+		result[i] = int(value)
+	}
+	return result
+}
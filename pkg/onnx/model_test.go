@@ -0,0 +1,309 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// message builds protobuf messages, fields are appended in order.
+type message []byte
+
+func (m message) varint(number int, value uint64) message {
+	m = binary.AppendUvarint(m, uint64(number<<3|wireVarint))
+	return binary.AppendUvarint(m, value)
+}
+
+func (m message) fixed32(number int, value uint32) message {
+	m = binary.AppendUvarint(m, uint64(number<<3|wireFixed32))
+	return binary.LittleEndian.AppendUint32(m, value)
+}
+
+func (m message) bytes(number int, value []byte) message {
+	m = binary.AppendUvarint(m, uint64(number<<3|wireBytes))
+	m = binary.AppendUvarint(m, uint64(len(value)))
+	return append(m, value...)
+}
+
+func (m message) string(number int, value string) message {
+	return m.bytes(number, []byte(value))
+}
+
+// tensor is a float TensorProto, raw stores data in raw_data instead of float_data.
+func tensor(name string, shape []int, data []float32, raw bool) message {
+	var m message
+	for _, dim := range shape {
+		m = m.varint(1, uint64(dim))
+	}
+	m = m.varint(2, typeFloat)
+
+	buf := make([]byte, 0, 4*len(data))
+	for _, value := range data {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(value))
+	}
+	if raw {
+		m = m.bytes(9, buf)
+	} else {
+		m = m.bytes(4, buf)
+	}
+
+	return m.string(8, name)
+}
+
+// int64s is an INT64 TensorProto
+func int64s(name string, data ...int) message {
+	m := message(nil).varint(1, uint64(len(data))).varint(2, typeInt64)
+	for _, value := range data {
+		m = m.varint(7, uint64(value))
+	}
+	return m.string(8, name)
+}
+
+// value is a float ValueInfoProto, dimensions of -1 are dynamic.
+func value(name string, shape ...int) message {
+	var dims message
+	for _, dim := range shape {
+		if dim < 0 {
+			dims = dims.bytes(1, message(nil).string(2, "N"))
+		} else {
+			dims = dims.bytes(1, message(nil).varint(1, uint64(dim)))
+		}
+	}
+	tensorType := message(nil).varint(1, typeFloat).bytes(2, dims)
+	return message(nil).string(1, name).bytes(2, message(nil).bytes(1, tensorType))
+}
+
+func nodeProto(op string, inputs, outputs []string, attributes ...message) message {
+	var m message
+	for _, name := range inputs {
+		m = m.string(1, name)
+	}
+	for _, name := range outputs {
+		m = m.string(2, name)
+	}
+	m = m.string(3, strings.ToLower(op)).string(4, op)
+	for _, attr := range attributes {
+		m = m.bytes(5, attr)
+	}
+	return m
+}
+
+func ints(name string, values ...int) message {
+	m := message(nil).string(1, name)
+	for _, value := range values {
+		m = m.varint(8, uint64(value))
+	}
+	return m.varint(20, 7)
+}
+
+func integer(name string, value int) message {
+	return message(nil).string(1, name).varint(3, uint64(value)).varint(20, 2)
+}
+
+type graph struct {
+	nodes, initializers, inputs, outputs []message
+}
+
+func (g graph) model(opset int) []byte {
+	var m message
+	for _, n := range g.nodes {
+		m = m.bytes(1, n)
+	}
+	m = m.string(2, "test")
+	for _, t := range g.initializers {
+		m = m.bytes(5, t)
+	}
+	for _, v := range g.inputs {
+		m = m.bytes(11, v)
+	}
+	for _, v := range g.outputs {
+		m = m.bytes(12, v)
+	}
+
+	opsetImport := message(nil).string(1, "").varint(2, uint64(opset))
+	return message(nil).varint(1, 7).bytes(7, m).bytes(8, opsetImport)
+}
+
+// classifier is global average pooling followed by a fully connected layer and softmax.
+var classifier = graph{
+	nodes: []message{
+		nodeProto("GlobalAveragePool", []string{"x"}, []string{"pooled"}),
+		nodeProto("Flatten", []string{"pooled"}, []string{"features"}),
+		nodeProto("Gemm", []string{"features", "weights", "bias"}, []string{"logits"}, integer("transB", 1)),
+		nodeProto("Softmax", []string{"logits"}, []string{"probabilities"}),
+	},
+	initializers: []message{
+		tensor("weights", []int{2, 3}, []float32{1, 0, 2, 0, 1, -1}, true),
+		tensor("bias", []int{2}, []float32{0.5, -1}, false),
+	},
+	inputs:  []message{value("x", -1, 3, 2, 2), value("weights", 2, 3)},
+	outputs: []message{value("logits", -1, 2), value("probabilities", -1, 2)},
+}
+
+func TestModel(t *testing.T) {
+	m, err := Load(classifier.model(13))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if want := []Value{{Name: "x", Shape: []int{-1, 3, 2, 2}}}; !reflect.DeepEqual(m.Inputs(), want) {
+		t.Errorf("Inputs() = %v, want %v", m.Inputs(), want)
+	}
+	if len(m.Outputs()) != 2 || m.Outputs()[0].Name != "logits" || m.Outputs()[1].Name != "probabilities" {
+		t.Errorf("Outputs() = %v", m.Outputs())
+	}
+
+	// channel means are 1, 3 and 0
+	x := &Tensor{Shape: []int{1, 3, 2, 2}, Data: []float32{1, 1, 1, 1, 0, 2, 4, 6, -1, -1, 1, 1}}
+	outputs, err := m.Run(map[string]*Tensor{"x": x})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	first := float32(1 / (1 + math.Exp(0.5)))
+	want := map[string]*Tensor{
+		"logits":        {Shape: []int{1, 2}, Data: []float32{1.5, 2}},
+		"probabilities": {Shape: []int{1, 2}, Data: []float32{first, 1 - first}},
+	}
+	for name, tensor := range want {
+		assertTensor(t, name, outputs[name], tensor)
+	}
+
+	if _, err := m.Run(map[string]*Tensor{}); err == nil {
+		t.Errorf("Run() without inputs error = nil")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	unsupported := classifier
+	unsupported.nodes = append([]message{nodeProto("NonMaxSuppression", []string{"x"}, []string{"y"})}, classifier.nodes...)
+
+	external := classifier
+	external.initializers = []message{tensor("weights", []int{2, 3}, nil, true).varint(14, 1)}
+
+	tests := []struct {
+		name string
+		buf  []byte
+		err  string
+	}{
+		{name: "unsupported operator", buf: unsupported.model(13), err: "operator NonMaxSuppression"},
+		{name: "external data", buf: external.model(13), err: "external data"},
+		{name: "no graph", buf: message(nil).varint(1, 7), err: "no graph"},
+		{name: "no inputs", buf: graph{}.model(13), err: "no inputs"},
+		{name: "truncated", buf: classifier.model(13)[:20], err: "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.buf)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	nine := &Tensor{Shape: []int{1, 1, 3, 3}, Data: []float32{1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	six := &Tensor{Shape: []int{2, 3}, Data: []float32{1, 2, 3, 4, 5, 6}}
+	ones := tensor("w", []int{1, 1, 2, 2}, []float32{1, 1, 1, 1}, false)
+
+	tests := []struct {
+		name         string
+		node         message
+		initializers []message
+		x            *Tensor
+		want         *Tensor
+	}{
+		{
+			name:         "conv",
+			node:         nodeProto("Conv", []string{"x", "w"}, []string{"y"}),
+			initializers: []message{ones},
+			x:            nine,
+			want:         &Tensor{Shape: []int{1, 1, 2, 2}, Data: []float32{12, 16, 24, 28}},
+		},
+		{
+			name:         "conv with padding and strides",
+			node:         nodeProto("Conv", []string{"x", "w", "b"}, []string{"y"}, ints("pads", 1, 1, 1, 1), ints("strides", 2, 2)),
+			initializers: []message{ones, tensor("b", []int{1}, []float32{-1}, false)},
+			x:            nine,
+			want:         &Tensor{Shape: []int{1, 1, 2, 2}, Data: []float32{0, 4, 10, 27}},
+		},
+		{
+			name: "max pool",
+			node: nodeProto("MaxPool", []string{"x"}, []string{"y"}, ints("kernel_shape", 2, 2)),
+			x:    nine,
+			want: &Tensor{Shape: []int{1, 1, 2, 2}, Data: []float32{5, 6, 8, 9}},
+		},
+		{
+			name: "average pool",
+			node: nodeProto("AveragePool", []string{"x"}, []string{"y"}, ints("kernel_shape", 2, 2)),
+			x:    nine,
+			want: &Tensor{Shape: []int{1, 1, 2, 2}, Data: []float32{3, 4, 6, 7}},
+		},
+		{
+			name: "relu",
+			node: nodeProto("Relu", []string{"x"}, []string{"y"}),
+			x:    &Tensor{Shape: []int{3}, Data: []float32{-1, 0, 2}},
+			want: &Tensor{Shape: []int{3}, Data: []float32{0, 0, 2}},
+		},
+		{
+			name: "transpose",
+			node: nodeProto("Transpose", []string{"x"}, []string{"y"}, ints("perm", 1, 0)),
+			x:    six,
+			want: &Tensor{Shape: []int{3, 2}, Data: []float32{1, 4, 2, 5, 3, 6}},
+		},
+		{
+			name:         "reshape",
+			node:         nodeProto("Reshape", []string{"x", "shape"}, []string{"y"}),
+			initializers: []message{int64s("shape", 3, -1)},
+			x:            six,
+			want:         &Tensor{Shape: []int{3, 2}, Data: []float32{1, 2, 3, 4, 5, 6}},
+		},
+		{
+			name:         "broadcast add",
+			node:         nodeProto("Add", []string{"x", "b"}, []string{"y"}),
+			initializers: []message{tensor("b", []int{3}, []float32{10, 20, 30}, false)},
+			x:            six,
+			want:         &Tensor{Shape: []int{2, 3}, Data: []float32{11, 22, 33, 14, 25, 36}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := graph{
+				nodes:        []message{tt.node},
+				initializers: tt.initializers,
+				inputs:       []message{value("x", tt.x.Shape...)},
+				outputs:      []message{value("y")},
+			}
+
+			m, err := Load(g.model(13))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			outputs, err := m.Run(map[string]*Tensor{"x": tt.x})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			assertTensor(t, "y", outputs["y"], tt.want)
+		})
+	}
+}
+
+func assertTensor(t *testing.T, name string, got, want *Tensor) {
+	t.Helper()
+	if got == nil || !reflect.DeepEqual(got.Shape, want.Shape) || len(got.Data) != len(want.Data) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for i := range got.Data {
+		if math.Abs(float64(got.Data[i]-want.Data[i])) > 1e-6 {
+			t.Errorf("%s = %v, want %v", name, got.Data, want.Data)
+			return
+		}
+	}
+}
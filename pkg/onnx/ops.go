@@ -0,0 +1,1124 @@
+package onnx
+
+import (
+	"fmt"
+	"math"
+)
+
+// operator evaluates a node, operators never modify their inputs.
+type operator func(m *Model, n node, args []*Tensor) ([]*Tensor, error)
+
+// operators supported, enough for common CNN classifiers exported with constant folding.
+var operators = map[string]operator{
+	"Identity": identity,
+	"Dropout":  identity,
+	"Constant": constant,
+	"Cast":     cast,
+
+	"Relu":        unary(func(x float64, _ node) float64 { return math.Max(x, 0) }),
+	"LeakyRelu":   unary(leakyRelu),
+	"Elu":         unary(elu),
+	"Sigmoid":     unary(func(x float64, _ node) float64 { return 1 / (1 + math.Exp(-x)) }),
+	"HardSigmoid": unary(hardSigmoid),
+	"HardSwish":   unary(func(x float64, _ node) float64 { return x * math.Max(0, math.Min(1, x/6+0.5)) }),
+	"Tanh":        unary(func(x float64, _ node) float64 { return math.Tanh(x) }),
+	"Exp":         unary(func(x float64, _ node) float64 { return math.Exp(x) }),
+	"Log":         unary(func(x float64, _ node) float64 { return math.Log(x) }),
+	"Sqrt":        unary(func(x float64, _ node) float64 { return math.Sqrt(x) }),
+	"Neg":         unary(func(x float64, _ node) float64 { return -x }),
+	"Abs":         unary(func(x float64, _ node) float64 { return math.Abs(x) }),
+	"Erf":         unary(func(x float64, _ node) float64 { return math.Erf(x) }),
+	"Reciprocal":  unary(func(x float64, _ node) float64 { return 1 / x }),
+	"Softplus":    unary(func(x float64, _ node) float64 { return math.Log1p(math.Exp(x)) }),
+	"Clip":        clip,
+
+	"Add": elementwise(func(x, y float32) float32 { return x + y }),
+	"Sub": elementwise(func(x, y float32) float32 { return x - y }),
+	"Mul": elementwise(func(x, y float32) float32 { return x * y }),
+	"Div": elementwise(func(x, y float32) float32 { return x / y }),
+	"Pow": elementwise(func(x, y float32) float32 { return float32(math.Pow(float64(x), float64(y))) }),
+	"Max": elementwise(func(x, y float32) float32 { return float32(math.Max(float64(x), float64(y))) }),
+	"Min": elementwise(func(x, y float32) float32 { return float32(math.Min(float64(x), float64(y))) }),
+	"Sum": elementwise(func(x, y float32) float32 { return x + y }),
+
+	"MatMul":             matMul,
+	"Gemm":               gemm,
+	"Conv":               conv,
+	"MaxPool":            pool(false, false),
+	"AveragePool":        pool(true, false),
+	"GlobalMaxPool":      pool(false, true),
+	"GlobalAveragePool":  pool(true, true),
+	"BatchNormalization": batchNormalization,
+	"ReduceMean":         reduceMean,
+	"Softmax":            softmax(false),
+	"LogSoftmax":         softmax(true),
+
+	"Flatten":   flatten,
+	"Reshape":   reshape,
+	"Squeeze":   squeeze,
+	"Unsqueeze": unsqueeze,
+	"Transpose": transpose,
+	"Concat":    concat,
+	"Gather":    gather,
+	"Shape":     shape,
+	"Pad":       pad,
+}
+
+// input return the i-th argument, fails if it's missing.
+func input(args []*Tensor, i int) (*Tensor, error) {
+	if i >= len(args) || args[i] == nil {
+		return nil, fmt.Errorf("missing input %d", i)
+	}
+	return args[i], nil
+}
+
+// optional return the i-th argument, nil if it's omitted.
+func optional(args []*Tensor, i int) *Tensor {
+	if i >= len(args) {
+		return nil
+	}
+	return args[i]
+}
+
+func identity(_ *Model, _ node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	return []*Tensor{x}, err
+}
+
+func constant(_ *Model, n node, _ []*Tensor) ([]*Tensor, error) {
+	if attr, ok := n.attrs["value"]; ok && attr.t != nil {
+		return []*Tensor{attr.t}, nil
+	}
+	if attr, ok := n.attrs["value_float"]; ok {
+		return []*Tensor{{Shape: []int{}, Data: []float32{attr.f}}}, nil
+	}
+	if attr, ok := n.attrs["value_int"]; ok {
+		return []*Tensor{{Shape: []int{}, Data: []float32{float32(attr.i)}}}, nil
+	}
+	if attr, ok := n.attrs["value_floats"]; ok {
+		return []*Tensor{{Shape: []int{len(attr.floats)}, Data: attr.floats}}, nil
+	}
+	if attr, ok := n.attrs["value_ints"]; ok {
+		data := make([]float32, len(attr.ints))
+		for i, value := range attr.ints {
+			data[i] = float32(value)
+		}
+		return []*Tensor{{Shape: []int{len(data)}, Data: data}}, nil
+	}
+	return nil, fmt.Errorf("unsupported constant value")
+}
+
+// cast truncates values cast to integer types, other casts are no-ops as values are held as float32.
+func cast(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.int("to", typeFloat) {
+	case typeUint8, typeInt8, typeInt32, typeInt64:
+		out := NewTensor(x.Shape...)
+		for i, value := range x.Data {
+			out.Data[i] = float32(math.Trunc(float64(value)))
+		}
+		return []*Tensor{out}, nil
+	case typeBool:
+		out := NewTensor(x.Shape...)
+		for i, value := range x.Data {
+			if value != 0 {
+				out.Data[i] = 1
+			}
+		}
+		return []*Tensor{out}, nil
+	}
+
+	return []*Tensor{x}, nil
+}
+
+func unary(fn func(x float64, n node) float64) operator {
+	return func(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+		x, err := input(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		out := NewTensor(x.Shape...)
+		for i, value := range x.Data {
+			out.Data[i] = float32(fn(float64(value), n))
+		}
+		return []*Tensor{out}, nil
+	}
+}
+
+func leakyRelu(x float64, n node) float64 {
+	if x < 0 {
+		return x * float64(n.float("alpha", 0.01))
+	}
+	return x
+}
+
+func elu(x float64, n node) float64 {
+	if x < 0 {
+		return float64(n.float("alpha", 1)) * (math.Exp(x) - 1)
+	}
+	return x
+}
+
+func hardSigmoid(x float64, n node) float64 {
+	return math.Max(0, math.Min(1, float64(n.float("alpha", 0.2))*x+float64(n.float("beta", 0.5))))
+}
+
+// clip bounds are attributes before opset 11 and inputs after.
+func clip(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	min, max := n.float("min", -math.MaxFloat32), n.float("max", math.MaxFloat32)
+	if bound := optional(args, 1); bound != nil && len(bound.Data) > 0 {
+		min = bound.Data[0]
+	}
+	if bound := optional(args, 2); bound != nil && len(bound.Data) > 0 {
+		max = bound.Data[0]
+	}
+
+	out := NewTensor(x.Shape...)
+	for i, value := range x.Data {
+		switch {
+		case value < min:
+			out.Data[i] = min
+		case value > max:
+			out.Data[i] = max
+		default:
+			out.Data[i] = value
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+// elementwise applies fn with numpy broadcasting, variadic operators (Max, Min, Sum) are applied pairwise.
+func elementwise(fn func(x, y float32) float32) operator {
+	return func(_ *Model, _ node, args []*Tensor) ([]*Tensor, error) {
+		out, err := input(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 1; i < len(args); i++ {
+			y, err := input(args, i)
+			if err != nil {
+				return nil, err
+			}
+
+			out, err = broadcast(out, y, fn)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return []*Tensor{out}, nil
+	}
+}
+
+func broadcast(a, b *Tensor, fn func(x, y float32) float32) (*Tensor, error) {
+	// same shape
+	if equal(a.Shape, b.Shape) {
+		out := NewTensor(a.Shape...)
+		for i := range out.Data {
+			out.Data[i] = fn(a.Data[i], b.Data[i])
+		}
+		return out, nil
+	}
+
+	rank := len(a.Shape)
+	if len(b.Shape) > rank {
+		rank = len(b.Shape)
+	}
+
+	shapeA, shapeB := padShape(a.Shape, rank), padShape(b.Shape, rank)
+	outShape := make([]int, rank)
+	for i := range outShape {
+		switch {
+		case shapeA[i] == shapeB[i] || shapeB[i] == 1:
+			outShape[i] = shapeA[i]
+		case shapeA[i] == 1:
+			outShape[i] = shapeB[i]
+		default:
+			return nil, fmt.Errorf("shapes %v and %v can't be broadcast", a.Shape, b.Shape)
+		}
+	}
+
+	// broadcast dimensions don't advance
+	stridesA, stridesB := strides(shapeA), strides(shapeB)
+	for i := range outShape {
+		if shapeA[i] == 1 {
+			stridesA[i] = 0
+		}
+		if shapeB[i] == 1 {
+			stridesB[i] = 0
+		}
+	}
+
+	out := NewTensor(outShape...)
+	index := make([]int, rank)
+	offsetA, offsetB := 0, 0
+	for i := range out.Data {
+		out.Data[i] = fn(a.Data[offsetA], b.Data[offsetB])
+
+		for d := rank - 1; d >= 0; d-- {
+			index[d]++
+			offsetA += stridesA[d]
+			offsetB += stridesB[d]
+			if index[d] < outShape[d] {
+				break
+			}
+			offsetA -= stridesA[d] * index[d]
+			offsetB -= stridesB[d] * index[d]
+			index[d] = 0
+		}
+	}
+
+	return out, nil
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// padShape prepends ones to a shape up to rank
+func padShape(shape []int, rank int) []int {
+	result := make([]int, rank)
+	for i := range result {
+		result[i] = 1
+	}
+	copy(result[rank-len(shape):], shape)
+	return result
+}
+
+// matmul multiplies a (rows x inner) by b (inner x columns) into out
+func matmul(a, b, out []float32, rows, inner, columns int) {
+	for i := 0; i < rows; i++ {
+		row := out[i*columns : (i+1)*columns]
+		for k := 0; k < inner; k++ {
+			value := a[i*inner+k]
+			if value == 0 {
+				continue
+			}
+			for j, bValue := range b[k*columns : (k+1)*columns] {
+				row[j] += value * bValue
+			}
+		}
+	}
+}
+
+// matMul supports a matrix or a batch of matrices with the same batch dimensions as the right-hand side.
+func matMul(_ *Model, _ node, args []*Tensor) ([]*Tensor, error) {
+	a, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := input(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a.Shape) < 2 || len(b.Shape) < 2 {
+		return nil, fmt.Errorf("matmul of vectors is not supported")
+	}
+
+	rows, inner := a.Shape[len(a.Shape)-2], a.Shape[len(a.Shape)-1]
+	columns := b.Shape[len(b.Shape)-1]
+	if b.Shape[len(b.Shape)-2] != inner {
+		return nil, fmt.Errorf("shapes %v and %v can't be multiplied", a.Shape, b.Shape)
+	}
+
+	outShape := append(append([]int{}, a.Shape[:len(a.Shape)-1]...), columns)
+
+	// a 2d right-hand side applies to all rows of the batch
+	if len(b.Shape) == 2 {
+		out := NewTensor(outShape...)
+		matmul(a.Data, b.Data, out.Data, size(a.Shape)/inner, inner, columns)
+		return []*Tensor{out}, nil
+	}
+
+	if !equal(a.Shape[:len(a.Shape)-2], b.Shape[:len(b.Shape)-2]) {
+		return nil, fmt.Errorf("broadcast matmul of %v and %v is not supported", a.Shape, b.Shape)
+	}
+
+	out := NewTensor(outShape...)
+	batch := size(a.Shape[:len(a.Shape)-2])
+	for i := 0; i < batch; i++ {
+		matmul(a.Data[i*rows*inner:], b.Data[i*inner*columns:], out.Data[i*rows*columns:], rows, inner, columns)
+	}
+	return []*Tensor{out}, nil
+}
+
+func gemm(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	a, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := input(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Shape) != 2 || len(b.Shape) != 2 {
+		return nil, fmt.Errorf("gemm inputs must be matrices")
+	}
+
+	if n.int("transA", 0) != 0 {
+		a = transpose2d(a)
+	}
+	if n.int("transB", 0) != 0 {
+		b = transpose2d(b)
+	}
+
+	rows, inner, columns := a.Shape[0], a.Shape[1], b.Shape[1]
+	if b.Shape[0] != inner {
+		return nil, fmt.Errorf("shapes %v and %v can't be multiplied", a.Shape, b.Shape)
+	}
+
+	out := NewTensor(rows, columns)
+	matmul(a.Data, b.Data, out.Data, rows, inner, columns)
+
+	alpha, beta := n.float("alpha", 1), n.float("beta", 1)
+	if alpha != 1 {
+		for i := range out.Data {
+			out.Data[i] *= alpha
+		}
+	}
+
+	if c := optional(args, 2); c != nil {
+		out, err = broadcast(out, c, func(x, y float32) float32 { return x + beta*y })
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+func transpose2d(t *Tensor) *Tensor {
+	rows, columns := t.Shape[0], t.Shape[1]
+	out := NewTensor(columns, rows)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < columns; j++ {
+			out.Data[j*rows+i] = t.Data[i*columns+j]
+		}
+	}
+	return out
+}
+
+// window is the geometry of a 2d convolution or pooling over NCHW tensors.
+type window struct {
+	kernelH, kernelW     int
+	strideH, strideW     int
+	dilationH, dilationW int
+	top, left            int
+	outH, outW           int
+}
+
+// newWindow resolves explicit or automatic padding and the output size.
+func newWindow(n node, height, width, kernelH, kernelW int) (window, error) {
+	w := window{kernelH: kernelH, kernelW: kernelW, strideH: 1, strideW: 1, dilationH: 1, dilationW: 1}
+
+	if s := n.ints("strides", nil); len(s) == 2 {
+		w.strideH, w.strideW = s[0], s[1]
+	}
+	if d := n.ints("dilations", nil); len(d) == 2 {
+		w.dilationH, w.dilationW = d[0], d[1]
+	}
+
+	extentH, extentW := (kernelH-1)*w.dilationH+1, (kernelW-1)*w.dilationW+1
+
+	var bottom, right int
+	switch n.string("auto_pad", "NOTSET") {
+	case "SAME_UPPER", "SAME_LOWER":
+		outH, outW := (height+w.strideH-1)/w.strideH, (width+w.strideW-1)/w.strideW
+		padH := maxInt((outH-1)*w.strideH+extentH-height, 0)
+		padW := maxInt((outW-1)*w.strideW+extentW-width, 0)
+		w.top, w.left = padH/2, padW/2
+		if n.string("auto_pad", "") == "SAME_LOWER" {
+			w.top, w.left = padH-padH/2, padW-padW/2
+		}
+		bottom, right = padH-w.top, padW-w.left
+	case "VALID":
+	default:
+		if p := n.ints("pads", nil); len(p) == 4 {
+			w.top, w.left, bottom, right = p[0], p[1], p[2], p[3]
+		}
+	}
+
+	spanH, spanW := height+w.top+bottom-extentH, width+w.left+right-extentW
+	if n.int("ceil_mode", 0) != 0 {
+		w.outH, w.outW = (spanH+w.strideH-1)/w.strideH+1, (spanW+w.strideW-1)/w.strideW+1
+		// the last window must start inside the input or left padding
+		if (w.outH-1)*w.strideH >= height+w.top {
+			w.outH--
+		}
+		if (w.outW-1)*w.strideW >= width+w.left {
+			w.outW--
+		}
+	} else {
+		w.outH, w.outW = spanH/w.strideH+1, spanW/w.strideW+1
+	}
+
+	if w.outH <= 0 || w.outW <= 0 {
+		return w, fmt.Errorf("kernel is larger than the input")
+	}
+
+	return w, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func conv(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	weights, err := input(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(x.Shape) != 4 || len(weights.Shape) != 4 {
+		return nil, fmt.Errorf("only 2d convolutions are supported")
+	}
+
+	batch, channels, height, width := x.Shape[0], x.Shape[1], x.Shape[2], x.Shape[3]
+	filters, groupChannels := weights.Shape[0], weights.Shape[1]
+	groups := n.int("group", 1)
+	if groups <= 0 || channels != groupChannels*groups || filters%groups != 0 {
+		return nil, fmt.Errorf("input channels %d don't match weights %v with %d groups", channels, weights.Shape, groups)
+	}
+
+	w, err := newWindow(n, height, width, weights.Shape[2], weights.Shape[3])
+	if err != nil {
+		return nil, err
+	}
+
+	bias := optional(args, 2)
+	out := NewTensor(batch, filters, w.outH, w.outW)
+	groupFilters := filters / groups
+	plane := w.outH * w.outW
+
+	for b := 0; b < batch; b++ {
+		for f := 0; f < filters; f++ {
+			result := out.Data[(b*filters+f)*plane : (b*filters+f+1)*plane]
+			if bias != nil {
+				for i := range result {
+					result[i] = bias.Data[f]
+				}
+			}
+
+			group := f / groupFilters
+			for c := 0; c < groupChannels; c++ {
+				channel := x.Data[(b*channels+group*groupChannels+c)*height*width:]
+				kernel := weights.Data[(f*groupChannels+c)*w.kernelH*w.kernelW:]
+
+				for ky := 0; ky < w.kernelH; ky++ {
+					for kx := 0; kx < w.kernelW; kx++ {
+						value := kernel[ky*w.kernelW+kx]
+						if value == 0 {
+							continue
+						}
+						w.accumulate(channel, result, value, ky, kx, height, width)
+					}
+				}
+			}
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+// accumulate adds a kernel tap multiplied by the shifted input channel to the output plane.
+func (w window) accumulate(channel, result []float32, value float32, ky, kx, height, width int) {
+	offsetX := kx*w.dilationW - w.left
+	for oy := 0; oy < w.outH; oy++ {
+		iy := oy*w.strideH + ky*w.dilationH - w.top
+		if iy < 0 || iy >= height {
+			continue
+		}
+
+		row := channel[iy*width : (iy+1)*width]
+		out := result[oy*w.outW : (oy+1)*w.outW]
+		for ox := range out {
+			ix := ox*w.strideW + offsetX
+			if ix >= 0 && ix < width {
+				out[ox] += value * row[ix]
+			}
+		}
+	}
+}
+
+// pool return max or average pooling, global pooling reduces the whole spatial dimensions.
+func pool(average, global bool) operator {
+	return func(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+		x, err := input(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(x.Shape) != 4 {
+			return nil, fmt.Errorf("only 2d pooling is supported")
+		}
+
+		batch, channels, height, width := x.Shape[0], x.Shape[1], x.Shape[2], x.Shape[3]
+
+		var w window
+		if global {
+			w = window{kernelH: height, kernelW: width, strideH: 1, strideW: 1, dilationH: 1, dilationW: 1, outH: 1, outW: 1}
+		} else {
+			kernel := n.ints("kernel_shape", nil)
+			if len(kernel) != 2 {
+				return nil, fmt.Errorf("kernel_shape is required")
+			}
+			w, err = newWindow(n, height, width, kernel[0], kernel[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		includePad := n.int("count_include_pad", 0) != 0
+		out := NewTensor(batch, channels, w.outH, w.outW)
+		for p := 0; p < batch*channels; p++ {
+			channel := x.Data[p*height*width : (p+1)*height*width]
+			result := out.Data[p*w.outH*w.outW:]
+
+			for oy := 0; oy < w.outH; oy++ {
+				for ox := 0; ox < w.outW; ox++ {
+					sum, max, count := float32(0), float32(-math.MaxFloat32), 0
+					for ky := 0; ky < w.kernelH; ky++ {
+						iy := oy*w.strideH + ky*w.dilationH - w.top
+						for kx := 0; kx < w.kernelW; kx++ {
+							ix := ox*w.strideW + kx*w.dilationW - w.left
+							if iy < 0 || iy >= height || ix < 0 || ix >= width {
+								if includePad {
+									count++
+								}
+								continue
+							}
+							value := channel[iy*width+ix]
+							sum += value
+							if value > max {
+								max = value
+							}
+							count++
+						}
+					}
+
+					if average {
+						result[oy*w.outW+ox] = sum / float32(maxInt(count, 1))
+					} else {
+						result[oy*w.outW+ox] = max
+					}
+				}
+			}
+		}
+
+		return []*Tensor{out}, nil
+	}
+}
+
+func batchNormalization(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	params := make([]*Tensor, 5)
+	for i := range params {
+		var err error
+		params[i], err = input(args, i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	x, scale, bias, mean, variance := params[0], params[1], params[2], params[3], params[4]
+	if len(x.Shape) < 2 {
+		return nil, fmt.Errorf("input must have a channel dimension")
+	}
+
+	epsilon := float64(n.float("epsilon", 1e-5))
+	channels := x.Shape[1]
+	inner := size(x.Shape[2:])
+
+	out := NewTensor(x.Shape...)
+	for i := 0; i < size(x.Shape)/inner; i++ {
+		c := i % channels
+		factor := scale.Data[c] / float32(math.Sqrt(float64(variance.Data[c])+epsilon))
+		shift := bias.Data[c] - mean.Data[c]*factor
+		for j := i * inner; j < (i+1)*inner; j++ {
+			out.Data[j] = x.Data[j]*factor + shift
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+// reduceMean axes are an attribute before opset 18 and an input after, all axes are reduced if none are given.
+func reduceMean(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	axes := n.ints("axes", nil)
+	if t := optional(args, 1); t != nil {
+		axes = t.ints()
+	}
+
+	reduced := make([]bool, len(x.Shape))
+	for _, a := range axes {
+		a, err = axis(a, len(x.Shape))
+		if err != nil {
+			return nil, err
+		}
+		reduced[a] = true
+	}
+	if len(axes) == 0 {
+		for i := range reduced {
+			reduced[i] = true
+		}
+	}
+
+	keptShape := make([]int, len(x.Shape))
+	outShape := make([]int, 0)
+	for i, dim := range x.Shape {
+		keptShape[i] = dim
+		if reduced[i] {
+			keptShape[i] = 1
+		}
+		if !reduced[i] || n.int("keepdims", 1) != 0 {
+			outShape = append(outShape, keptShape[i])
+		}
+	}
+
+	out := NewTensor(outShape...)
+	outStrides := strides(keptShape)
+	index := make([]int, len(x.Shape))
+	for _, value := range x.Data {
+		offset := 0
+		for d, i := range index {
+			if !reduced[d] {
+				offset += i * outStrides[d]
+			}
+		}
+		out.Data[offset] += value
+
+		for d := len(index) - 1; d >= 0; d-- {
+			index[d]++
+			if index[d] < x.Shape[d] {
+				break
+			}
+			index[d] = 0
+		}
+	}
+
+	count := float32(size(x.Shape) / maxInt(size(keptShape), 1))
+	for i := range out.Data {
+		out.Data[i] /= count
+	}
+
+	return []*Tensor{out}, nil
+}
+
+// softmax is computed along an axis since opset 13, and over the input coerced to 2d at axis before it.
+func softmax(logarithm bool) operator {
+	return func(m *Model, n node, args []*Tensor) ([]*Tensor, error) {
+		x, err := input(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		def := 1
+		if m.opset >= 13 {
+			def = -1
+		}
+		a, err := axis(n.int("axis", def), len(x.Shape))
+		if err != nil {
+			return nil, err
+		}
+
+		outer, dim, inner := size(x.Shape[:a]), x.Shape[a], size(x.Shape[a+1:])
+		if m.opset < 13 {
+			dim, inner = dim*inner, 1
+		}
+
+		out := NewTensor(x.Shape...)
+		for o := 0; o < outer; o++ {
+			for i := 0; i < inner; i++ {
+				base := o*dim*inner + i
+
+				max := float32(-math.MaxFloat32)
+				for d := 0; d < dim; d++ {
+					if value := x.Data[base+d*inner]; value > max {
+						max = value
+					}
+				}
+
+				sum := 0.0
+				for d := 0; d < dim; d++ {
+					sum += math.Exp(float64(x.Data[base+d*inner] - max))
+				}
+
+				for d := 0; d < dim; d++ {
+					shifted := float64(x.Data[base+d*inner] - max)
+					if logarithm {
+						out.Data[base+d*inner] = float32(shifted - math.Log(sum))
+					} else {
+						out.Data[base+d*inner] = float32(math.Exp(shifted) / sum)
+					}
+				}
+			}
+		}
+
+		return []*Tensor{out}, nil
+	}
+}
+
+func flatten(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	a := n.int("axis", 1)
+	if a < 0 {
+		a += len(x.Shape)
+	}
+	if a < 0 || a > len(x.Shape) {
+		return nil, fmt.Errorf("axis %d is out of range", a)
+	}
+
+	return []*Tensor{{Shape: []int{size(x.Shape[:a]), size(x.Shape[a:])}, Data: x.Data}}, nil
+}
+
+// reshape target shape is an attribute before opset 5 and an input after, 0 copies the input dimension and -1 is
+// inferred.
+func reshape(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	target := n.ints("shape", nil)
+	if t := optional(args, 1); t != nil {
+		target = t.ints()
+	}
+
+	outShape := make([]int, len(target))
+	infer, known := -1, 1
+	for i, dim := range target {
+		switch {
+		case dim == 0 && n.int("allowzero", 0) == 0:
+			if i >= len(x.Shape) {
+				return nil, fmt.Errorf("can't copy dimension %d of %v", i, x.Shape)
+			}
+			outShape[i] = x.Shape[i]
+		case dim == -1:
+			infer = i
+			continue
+		default:
+			outShape[i] = dim
+		}
+		known *= outShape[i]
+	}
+
+	if infer >= 0 {
+		if known == 0 || size(x.Shape)%known != 0 {
+			return nil, fmt.Errorf("can't reshape %v to %v", x.Shape, target)
+		}
+		outShape[infer] = size(x.Shape) / known
+	}
+
+	if size(outShape) != size(x.Shape) {
+		return nil, fmt.Errorf("can't reshape %v to %v", x.Shape, target)
+	}
+
+	return []*Tensor{{Shape: outShape, Data: x.Data}}, nil
+}
+
+// axes of squeeze and unsqueeze are an attribute before opset 13 and an input after.
+func axes(n node, args []*Tensor) []int {
+	if t := optional(args, 1); t != nil {
+		return t.ints()
+	}
+	return n.ints("axes", nil)
+}
+
+func squeeze(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	remove := make([]bool, len(x.Shape))
+	list := axes(n, args)
+	for _, a := range list {
+		a, err = axis(a, len(x.Shape))
+		if err != nil {
+			return nil, err
+		}
+		remove[a] = true
+	}
+
+	outShape := make([]int, 0)
+	for i, dim := range x.Shape {
+		if (len(list) == 0 && dim == 1) || remove[i] {
+			continue
+		}
+		outShape = append(outShape, dim)
+	}
+
+	return []*Tensor{{Shape: outShape, Data: x.Data}}, nil
+}
+
+func unsqueeze(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	list := axes(n, args)
+	rank := len(x.Shape) + len(list)
+	insert := make([]bool, rank)
+	for _, a := range list {
+		a, err = axis(a, rank)
+		if err != nil {
+			return nil, err
+		}
+		insert[a] = true
+	}
+
+	outShape := make([]int, rank)
+	j := 0
+	for i := range outShape {
+		if insert[i] {
+			outShape[i] = 1
+			continue
+		}
+		outShape[i] = x.Shape[j]
+		j++
+	}
+
+	return []*Tensor{{Shape: outShape, Data: x.Data}}, nil
+}
+
+func transpose(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := len(x.Shape)
+	perm := n.ints("perm", nil)
+	if perm == nil {
+		perm = make([]int, rank)
+		for i := range perm {
+			perm[i] = rank - 1 - i
+		}
+	}
+	if len(perm) != rank {
+		return nil, fmt.Errorf("perm %v doesn't match rank %d", perm, rank)
+	}
+
+	outShape := make([]int, rank)
+	inStrides := strides(x.Shape)
+	permStrides := make([]int, rank)
+	for i, p := range perm {
+		outShape[i] = x.Shape[p]
+		permStrides[i] = inStrides[p]
+	}
+
+	out := NewTensor(outShape...)
+	index := make([]int, rank)
+	offset := 0
+	for i := range out.Data {
+		out.Data[i] = x.Data[offset]
+
+		for d := rank - 1; d >= 0; d-- {
+			index[d]++
+			offset += permStrides[d]
+			if index[d] < outShape[d] {
+				break
+			}
+			offset -= permStrides[d] * index[d]
+			index[d] = 0
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+func concat(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	first, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := axis(n.int("axis", 0), len(first.Shape))
+	if err != nil {
+		return nil, err
+	}
+
+	outShape := append([]int{}, first.Shape...)
+	outShape[a] = 0
+	for i := range args {
+		x, err := input(args, i)
+		if err != nil {
+			return nil, err
+		}
+		if len(x.Shape) != len(first.Shape) {
+			return nil, fmt.Errorf("can't concat %v and %v", first.Shape, x.Shape)
+		}
+		outShape[a] += x.Shape[a]
+	}
+
+	out := NewTensor(outShape...)
+	outer := size(outShape[:a])
+	offset := 0
+	for o := 0; o < outer; o++ {
+		for _, x := range args {
+			chunk := size(x.Shape[a:])
+			copy(out.Data[offset:], x.Data[o*chunk:(o+1)*chunk])
+			offset += chunk
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+func gather(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := input(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := axis(n.int("axis", 0), len(x.Shape))
+	if err != nil {
+		return nil, err
+	}
+
+	outShape := append(append(append([]int{}, x.Shape[:a]...), indices.Shape...), x.Shape[a+1:]...)
+	out := NewTensor(outShape...)
+
+	outer, dim, inner := size(x.Shape[:a]), x.Shape[a], size(x.Shape[a+1:])
+	offset := 0
+	for o := 0; o < outer; o++ {
+		for _, index := range indices.ints() {
+			if index < 0 {
+				index += dim
+			}
+			if index < 0 || index >= dim {
+				return nil, fmt.Errorf("index %d is out of range", index)
+			}
+			copy(out.Data[offset:], x.Data[(o*dim+index)*inner:(o*dim+index+1)*inner])
+			offset += inner
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
+
+func shape(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := len(x.Shape)
+	start, end := n.int("start", 0), n.int("end", rank)
+	if start < 0 {
+		start += rank
+	}
+	if end < 0 {
+		end += rank
+	}
+	if end > rank {
+		end = rank
+	}
+	if start < 0 || start > end {
+		return nil, fmt.Errorf("invalid range [%d:%d]", start, end)
+	}
+
+	out := NewTensor(end - start)
+	for i := start; i < end; i++ {
+		out.Data[i-start] = float32(x.Shape[i])
+	}
+
+	return []*Tensor{out}, nil
+}
+
+// pad supports constant padding, pads are an attribute before opset 11 and an input after.
+func pad(_ *Model, n node, args []*Tensor) ([]*Tensor, error) {
+	x, err := input(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode := n.string("mode", "constant"); mode != "constant" {
+		return nil, fmt.Errorf("pad mode %s is not supported", mode)
+	}
+
+	pads := n.ints("pads", nil)
+	if t := optional(args, 1); t != nil {
+		pads = t.ints()
+	}
+	value := n.float("value", 0)
+	if t := optional(args, 2); t != nil && len(t.Data) > 0 {
+		value = t.Data[0]
+	}
+
+	rank := len(x.Shape)
+	if len(pads) != 2*rank {
+		return nil, fmt.Errorf("pads %v don't match rank %d", pads, rank)
+	}
+
+	outShape := make([]int, rank)
+	for i, dim := range x.Shape {
+		outShape[i] = dim + pads[i] + pads[rank+i]
+		if pads[i] < 0 || pads[rank+i] < 0 {
+			return nil, fmt.Errorf("negative pads are not supported")
+		}
+	}
+
+	out := NewTensor(outShape...)
+	for i := range out.Data {
+		out.Data[i] = value
+	}
+
+	outStrides := strides(outShape)
+	index := make([]int, rank)
+	for _, v := range x.Data {
+		offset := 0
+		for d, i := range index {
+			offset += (i + pads[d]) * outStrides[d]
+		}
+		out.Data[offset] = v
+
+		for d := rank - 1; d >= 0; d-- {
+			index[d]++
+			if index[d] < x.Shape[d] {
+				break
+			}
+			index[d] = 0
+		}
+	}
+
+	return []*Tensor{out}, nil
+}
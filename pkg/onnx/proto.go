@@ -0,0 +1,128 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errMalformed = errors.New("malformed onnx model")
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// field is a decoded protobuf field, value holds varints and fixed values, bytes holds length-delimited values.
+type field struct {
+	number int
+	wire   int
+	value  uint64
+	bytes  []byte
+}
+
+// fields decodes a protobuf message into its fields in order.
+func fields(buf []byte) ([]field, error) {
+	result := make([]field, 0)
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errMalformed
+		}
+		buf = buf[n:]
+
+		f := field{number: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.value, n = binary.Uvarint(buf)
+			if n <= 0 {
+				return nil, errMalformed
+			}
+			buf = buf[n:]
+		case wireFixed64:
+			if len(buf) < 8 {
+				return nil, errMalformed
+			}
+			f.value = binary.LittleEndian.Uint64(buf)
+			buf = buf[8:]
+		case wireFixed32:
+			if len(buf) < 4 {
+				return nil, errMalformed
+			}
+			f.value = uint64(binary.LittleEndian.Uint32(buf))
+			buf = buf[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(buf)
+			if n <= 0 || uint64(len(buf)-n) < length {
+				return nil, errMalformed
+			}
+			f.bytes = buf[n : n+int(length)]
+			buf = buf[n+int(length):]
+		default:
+			return nil, errMalformed
+		}
+
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+// ints decodes a repeated integer field, packed or not.
+func (f field) ints() ([]int64, error) {
+	if f.wire == wireVarint {
+		return []int64{int64(f.value)}, nil
+	}
+
+	values := make([]int64, 0)
+	buf := f.bytes
+	for len(buf) > 0 {
+		value, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errMalformed
+		}
+		values = append(values, int64(value))
+		buf = buf[n:]
+	}
+
+	return values, nil
+}
+
+// floats decodes a repeated float field, packed or not.
+func (f field) floats() ([]float32, error) {
+	if f.wire == wireFixed32 {
+		return []float32{math.Float32frombits(uint32(f.value))}, nil
+	}
+
+	if len(f.bytes)%4 != 0 {
+		return nil, errMalformed
+	}
+
+	values := make([]float32, len(f.bytes)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(f.bytes[4*i:]))
+	}
+
+	return values, nil
+}
+
+// doubles decodes a repeated double field, packed or not.
+func (f field) doubles() ([]float64, error) {
+	if f.wire == wireFixed64 {
+		return []float64{math.Float64frombits(f.value)}, nil
+	}
+
+	if len(f.bytes)%8 != 0 {
+		return nil, errMalformed
+	}
+
+	values := make([]float64, len(f.bytes)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(f.bytes[8*i:]))
+	}
+
+	return values, nil
+}
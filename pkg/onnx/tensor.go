@@ -0,0 +1,186 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// onnx tensor data types
+const (
+	typeFloat  = 1
+	typeUint8  = 2
+	typeInt8   = 3
+	typeInt32  = 6
+	typeInt64  = 7
+	typeBool   = 9
+	typeDouble = 11
+)
+
+// Tensor is a dense row-major tensor, all data types are held as float32 which is exact for the integer shapes and
+// indices used by models.
+type Tensor struct {
+	Shape []int
+	Data  []float32
+}
+
+// NewTensor return a zeroed tensor of the given shape
+func NewTensor(shape ...int) *Tensor {
+	return &Tensor{Shape: shape, Data: make([]float32, size(shape))}
+}
+
+// size is the number of elements of a shape
+func size(shape []int) int {
+	n := 1
+	for _, dim := range shape {
+		n *= dim
+	}
+	return n
+}
+
+// strides of a row-major shape
+func strides(shape []int) []int {
+	result := make([]int, len(shape))
+	stride := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		result[i] = stride
+		stride *= shape[i]
+	}
+	return result
+}
+
+// axis normalizes a possibly negative axis
+func axis(value, rank int) (int, error) {
+	if value < 0 {
+		value += rank
+	}
+	if value < 0 || value >= rank {
+		return 0, fmt.Errorf("axis %d is out of range for rank %d", value, rank)
+	}
+	return value, nil
+}
+
+// ints return the tensor data as ints, used for shapes and indices
+func (t *Tensor) ints() []int {
+	result := make([]int, len(t.Data))
+	for i, value := range t.Data {
+		result[i] = int(value)
+	}
+	return result
+}
+
+// decodeTensor decodes a TensorProto, returns its name.
+func decodeTensor(buf []byte) (string, *Tensor, error) {
+	message, err := fields(buf)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var name string
+	var dataType int
+	var raw []byte
+	shape := make([]int, 0)
+	data := make([]float32, 0)
+
+	for _, f := range message {
+		switch f.number {
+		case 1:
+			dims, err := f.ints()
+			if err != nil {
+				return "", nil, err
+			}
+			for _, dim := range dims {
+				shape = append(shape, int(dim))
+			}
+		case 2:
+			dataType = int(f.value)
+		case 4:
+			values, err := f.floats()
+			if err != nil {
+				return "", nil, err
+			}
+			data = append(data, values...)
+		case 5, 7:
+			values, err := f.ints()
+			if err != nil {
+				return "", nil, err
+			}
+			for _, value := range values {
+				if f.number == 5 {
+					data = append(data, float32(int32(value)))
+				} else {
+					data = append(data, float32(value))
+				}
+			}
+		case 8:
+			name = string(f.bytes)
+		case 9:
+			raw = f.bytes
+		case 10:
+			values, err := f.doubles()
+			if err != nil {
+				return "", nil, err
+			}
+			for _, value := range values {
+				data = append(data, float32(value))
+			}
+		case 14:
+			if f.value == 1 {
+				return "", nil, fmt.Errorf("tensor %s uses external data which is not supported", name)
+			}
+		}
+	}
+
+	if raw != nil {
+		data, err = decodeRaw(raw, dataType)
+		if err != nil {
+			return "", nil, fmt.Errorf("tensor %s: %s", name, err.Error())
+		}
+	}
+
+	if len(data) != size(shape) {
+		return "", nil, fmt.Errorf("tensor %s has %d values for shape %v", name, len(data), shape)
+	}
+
+	return name, &Tensor{Shape: shape, Data: data}, nil
+}
+
+// decodeRaw decodes little-endian raw tensor data
+func decodeRaw(raw []byte, dataType int) ([]float32, error) {
+	var width int
+	switch dataType {
+	case typeUint8, typeInt8, typeBool:
+		width = 1
+	case typeFloat, typeInt32:
+		width = 4
+	case typeInt64, typeDouble:
+		width = 8
+	default:
+		return nil, fmt.Errorf("data type %d is not supported", dataType)
+	}
+
+	if len(raw)%width != 0 {
+		return nil, errMalformed
+	}
+
+	data := make([]float32, len(raw)/width)
+	for i := range data {
+		value := raw[i*width:]
+		switch dataType {
+		case typeUint8, typeBool:
+			data[i] = float32(value[0])
+		case typeInt8:
+			data[i] = float32(int8(value[0]))
+		case typeFloat:
+			data[i] = math.Float32frombits(binary.LittleEndian.Uint32(value))
+		case typeInt32:
+			data[i] = float32(int32(binary.LittleEndian.Uint32(value)))
+		case typeInt64:
+			data[i] = float32(int64(binary.LittleEndian.Uint64(value)))
+		case typeDouble:
+			data[i] = float32(math.Float64frombits(binary.LittleEndian.Uint64(value)))
+		}
+	}
+
+	return data, nil
+}
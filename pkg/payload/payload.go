@@ -7,6 +7,10 @@ import (
 // Data is a map hold data/metadata about an image that will be read and augmented through the pipeline.
 type Data map[string]interface{}
 
+// OutputsField is the Data field that holds the Data of outputs collected by a pipeline node, keyed by their config
+// name. outputs that don't store images in a location (mysql, stdout) don't report `_location`.
+const OutputsField = "_outputs"
+
 // ---------------------------------------------------------------------------------------------
 
 // Stream holds a reader to image bytes
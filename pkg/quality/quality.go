@@ -0,0 +1,270 @@
+// Package quality measures the sharpness, exposure and noise of images, and combines them into a quality score.
+package quality
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// images are measured downscaled to this many pixels per dimension at most, so metrics don't depend on resolution.
+const maxSize = 1024
+
+// luminance (0-255) at or below which pixels are underexposed, and at or above which they are overexposed
+const (
+	underexposed = 16
+	overexposed  = 240
+)
+
+// metric values scoring 0.5, and the resolution (pixels) scoring 1
+const (
+	sharpnessReference  = 100
+	noiseReference      = 10
+	resolutionReference = 1000000
+)
+
+// fraction of strongest edges excluded from the noise estimate
+const edgeFraction = 0.1
+
+// Metrics of an image, luminance based metrics are on a 0-255 scale.
+type Metrics struct {
+	Width, Height int
+
+	// Sharpness is the variance of the Laplacian of the luminance, blurry images have a low variance.
+	Sharpness float64
+
+	// Brightness is the mean luminance and Contrast its standard deviation, both from 0 to 1.
+	Brightness, Contrast float64
+	// Underexposed and Overexposed are the fractions of pixels with clipped shadows and highlights.
+	Underexposed, Overexposed float64
+	// Histogram is the fraction of pixels in equal ranges of luminance, from dark to bright.
+	Histogram []float64
+
+	// Noise is the estimated standard deviation of the noise in flat areas.
+	Noise float64
+}
+
+// Scores of metrics from 0 (worst) to 1 (best)
+type Scores struct {
+	Sharpness, Exposure, Noise, Resolution float64
+}
+
+// Weights of scores in the combined score
+type Weights struct {
+	Sharpness  float64 `validate:"min=0"`
+	Exposure   float64 `validate:"min=0"`
+	Noise      float64 `validate:"min=0"`
+	Resolution float64 `validate:"min=0"`
+}
+
+// DefaultWeights favors sharpness and exposure
+func DefaultWeights() Weights {
+	return Weights{
+		Sharpness:  0.4,
+		Exposure:   0.3,
+		Noise:      0.15,
+		Resolution: 0.15,
+	}
+}
+
+// Measure return the metrics of an image, with a histogram of bins ranges.
+func Measure(img image.Image, bins int) Metrics {
+	bounds := img.Bounds()
+	metrics := Metrics{Width: bounds.Dx(), Height: bounds.Dy(), Histogram: make([]float64, bins)}
+	if metrics.Width == 0 || metrics.Height == 0 {
+		return metrics
+	}
+
+	gray := luminance(img)
+	exposure(gray, &metrics)
+	metrics.Sharpness = sharpness(gray)
+	metrics.Noise = noise(gray)
+
+	return metrics
+}
+
+// Scores return the scores of the metrics
+func (m Metrics) Scores() Scores {
+	clipped := m.Underexposed + m.Overexposed
+	return Scores{
+		Sharpness:  m.Sharpness / (m.Sharpness + sharpnessReference),
+		Exposure:   (1 - math.Abs(2*m.Brightness-1)) * (1 - clipped),
+		Noise:      noiseReference / (m.Noise + noiseReference),
+		Resolution: math.Min(1, math.Sqrt(float64(m.Width*m.Height)/resolutionReference)),
+	}
+}
+
+// Combine return the weighted geometric mean of scores, so a single bad score lowers it noticeably.
+func (s Scores) Combine(weights Weights) float64 {
+	total := weights.Sharpness + weights.Exposure + weights.Noise + weights.Resolution
+	if total == 0 {
+		return 0
+	}
+
+	return math.Pow(s.Sharpness, weights.Sharpness/total) *
+		math.Pow(s.Exposure, weights.Exposure/total) *
+		math.Pow(s.Noise, weights.Noise/total) *
+		math.Pow(s.Resolution, weights.Resolution/total)
+}
+
+// luminance return the grayscale image, box downscaled to maxSize
+func luminance(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+
+	factor := (max(bounds.Dx(), bounds.Dy()) + maxSize - 1) / maxSize
+	if factor <= 1 {
+		return gray
+	}
+
+	width, height := bounds.Dx()/factor, bounds.Dy()/factor
+	if width == 0 || height == 0 {
+		return gray
+	}
+
+	small := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sum := 0
+			for j := y * factor; j < (y+1)*factor; j++ {
+				row := gray.Pix[j*gray.Stride:]
+				for i := x * factor; i < (x+1)*factor; i++ {
+					sum += int(row[i])
+				}
+			}
+			small.Pix[y*small.Stride+x] = uint8(sum / (factor * factor))
+		}
+	}
+
+	return small
+}
+
+// exposure sets the brightness, contrast, clipping and histogram metrics
+func exposure(gray *image.Gray, metrics *Metrics) {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	count := float64(width * height)
+
+	var histogram [256]int
+	for y := 0; y < height; y++ {
+		for _, value := range gray.Pix[y*gray.Stride : y*gray.Stride+width] {
+			histogram[value]++
+		}
+	}
+
+	mean, under, over := 0.0, 0, 0
+	bins := len(metrics.Histogram)
+	for value, n := range histogram {
+		mean += float64(value * n)
+		if value <= underexposed {
+			under += n
+		}
+		if value >= overexposed {
+			over += n
+		}
+		if bins > 0 {
+			metrics.Histogram[value*bins/256] += float64(n) / count
+		}
+	}
+	mean /= count
+
+	variance := 0.0
+	for value, n := range histogram {
+		variance += float64(n) * (float64(value) - mean) * (float64(value) - mean)
+	}
+	variance /= count
+
+	metrics.Brightness = mean / 255
+	metrics.Contrast = math.Sqrt(variance) / 255
+	metrics.Underexposed = float64(under) / count
+	metrics.Overexposed = float64(over) / count
+}
+
+// sharpness return the variance of the 4-neighbours Laplacian
+func sharpness(gray *image.Gray) float64 {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	sum, squares := 0.0, 0.0
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			i := y*gray.Stride + x
+			laplacian := float64(int(gray.Pix[i-1]) + int(gray.Pix[i+1]) + int(gray.Pix[i-gray.Stride]) +
+				int(gray.Pix[i+gray.Stride]) - 4*int(gray.Pix[i]))
+			sum += laplacian
+			squares += laplacian * laplacian
+		}
+	}
+
+	count := float64((width - 2) * (height - 2))
+	mean := sum / count
+	return squares/count - mean*mean
+}
+
+// noise estimates the noise standard deviation (Immerkær's method) on pixels outside the strongest edges
+func noise(gray *image.Gray) float64 {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	at := func(x, y int) int {
+		return int(gray.Pix[y*gray.Stride+x])
+	}
+
+	count := (width - 2) * (height - 2)
+	gradients := make([]int, count)
+	responses := make([]int, count)
+	var histogram [2048]int
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			gradient := abs(gx) + abs(gy)
+
+			response := at(x-1, y-1) - 2*at(x, y-1) + at(x+1, y-1) -
+				2*at(x-1, y) + 4*at(x, y) - 2*at(x+1, y) +
+				at(x-1, y+1) - 2*at(x, y+1) + at(x+1, y+1)
+
+			i := (y-1)*(width-2) + x - 1
+			gradients[i], responses[i] = gradient, abs(response)
+			histogram[gradient]++
+		}
+	}
+
+	// gradient above which pixels are edges
+	threshold, seen := 0, 0
+	for threshold < len(histogram)-1 && seen+histogram[threshold] <= int(float64(count)*(1-edgeFraction)) {
+		seen += histogram[threshold]
+		threshold++
+	}
+
+	sum, flat := 0, 0
+	for i, gradient := range gradients {
+		if gradient <= threshold {
+			sum += responses[i]
+			flat++
+		}
+	}
+	if flat == 0 {
+		return 0
+	}
+
+	return math.Sqrt(math.Pi/2) * float64(sum) / (6 * float64(flat))
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,166 @@
+package quality
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// pattern return a 4x4 image with the luminance of every pixel
+func pattern(fn func(x, y int) uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: fn(x, y)})
+		}
+	}
+	return img
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		bins int
+		want Metrics
+	}{
+		{
+			name: "uniform",
+			img:  pattern(func(x, y int) uint8 { return 128 }),
+			bins: 4,
+			want: Metrics{Width: 4, Height: 4, Brightness: 128.0 / 255, Histogram: []float64{0, 0, 1, 0}},
+		},
+		{
+			name: "edge",
+			img: pattern(func(x, y int) uint8 {
+				if x < 2 {
+					return 0
+				}
+				return 255
+			}),
+			bins: 2,
+			// edges are excluded from the noise estimate
+			want: Metrics{
+				Width: 4, Height: 4, Sharpness: 255 * 255,
+				Brightness: 0.5, Contrast: 0.5, Underexposed: 0.5, Overexposed: 0.5,
+				Histogram: []float64{0.5, 0.5},
+			},
+		},
+		{
+			name: "checkerboard",
+			img: pattern(func(x, y int) uint8 {
+				return uint8((x + y + 1) % 2 * 255)
+			}),
+			bins: 1,
+			want: Metrics{
+				Width: 4, Height: 4, Sharpness: 1020 * 1020,
+				Brightness: 0.5, Contrast: 0.5, Underexposed: 0.5, Overexposed: 0.5,
+				Histogram: []float64{1},
+				Noise:     math.Sqrt(math.Pi/2) * 2040 / 6,
+			},
+		},
+		{
+			name: "empty",
+			img:  image.NewGray(image.Rectangle{}),
+			bins: 2,
+			want: Metrics{Histogram: []float64{0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Measure(tt.img, tt.bins)
+			if got.Width != tt.want.Width || got.Height != tt.want.Height {
+				t.Errorf("Measure() size = %dx%d, want %dx%d", got.Width, got.Height, tt.want.Width, tt.want.Height)
+			}
+
+			values := map[string][2]float64{
+				"Sharpness":    {got.Sharpness, tt.want.Sharpness},
+				"Brightness":   {got.Brightness, tt.want.Brightness},
+				"Contrast":     {got.Contrast, tt.want.Contrast},
+				"Underexposed": {got.Underexposed, tt.want.Underexposed},
+				"Overexposed":  {got.Overexposed, tt.want.Overexposed},
+				"Noise":        {got.Noise, tt.want.Noise},
+			}
+			for name, value := range values {
+				if math.Abs(value[0]-value[1]) > 1e-9 {
+					t.Errorf("Measure() %s = %v, want %v", name, value[0], value[1])
+				}
+			}
+
+			if len(got.Histogram) != len(tt.want.Histogram) {
+				t.Fatalf("Measure() Histogram = %v, want %v", got.Histogram, tt.want.Histogram)
+			}
+			for i := range got.Histogram {
+				if math.Abs(got.Histogram[i]-tt.want.Histogram[i]) > 1e-9 {
+					t.Errorf("Measure() Histogram = %v, want %v", got.Histogram, tt.want.Histogram)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestScores(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  Metrics
+		weights  Weights
+		want     Scores
+		combined float64
+	}{
+		{
+			name:     "references",
+			metrics:  Metrics{Width: 500, Height: 500, Sharpness: sharpnessReference, Brightness: 0.5, Noise: noiseReference},
+			weights:  DefaultWeights(),
+			want:     Scores{Sharpness: 0.5, Exposure: 1, Noise: 0.5, Resolution: 0.5},
+			combined: math.Pow(0.5, 0.7),
+		},
+		{
+			name:     "clipped and dark",
+			metrics:  Metrics{Width: 2000, Height: 1000, Brightness: 0.25, Underexposed: 0.2, Overexposed: 0.3},
+			weights:  Weights{Exposure: 1},
+			want:     Scores{Sharpness: 0, Exposure: 0.25, Noise: 1, Resolution: 1},
+			combined: 0.25,
+		},
+		{
+			name:    "no weights",
+			metrics: Metrics{Width: 500, Height: 500, Sharpness: sharpnessReference, Brightness: 0.5},
+			want:    Scores{Sharpness: 0.5, Exposure: 1, Noise: 1, Resolution: 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.metrics.Scores()
+			if math.Abs(got.Sharpness-tt.want.Sharpness) > 1e-9 || math.Abs(got.Exposure-tt.want.Exposure) > 1e-9 ||
+				math.Abs(got.Noise-tt.want.Noise) > 1e-9 || math.Abs(got.Resolution-tt.want.Resolution) > 1e-9 {
+				t.Errorf("Scores() = %+v, want %+v", got, tt.want)
+			}
+			if combined := got.Combine(tt.weights); math.Abs(combined-tt.combined) > 1e-9 {
+				t.Errorf("Combine() = %v, want %v", combined, tt.combined)
+			}
+		})
+	}
+}
+
+func TestLuminance(t *testing.T) {
+	// alternating black and white columns average to gray when halved
+	img := image.NewGray(image.Rect(10, 10, 2058, 14))
+	for y := 10; y < 14; y++ {
+		for x := 10; x < 2058; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x % 2 * 255)})
+		}
+	}
+
+	gray := luminance(img)
+	if gray.Rect != image.Rect(0, 0, 1024, 2) {
+		t.Fatalf("luminance() bounds = %v, want 1024x2", gray.Rect)
+	}
+	for i, value := range gray.Pix {
+		if value != 127 {
+			t.Fatalf("luminance() pixel %d = %d, want 127", i, value)
+		}
+	}
+}
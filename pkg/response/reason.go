@@ -0,0 +1,29 @@
+package response
+
+// Reason is a machine-readable reason of a no-ack, Code is a stable identifier (e.g TOO_WIDE) inputs can map to their
+// own messages, and Values are the offending and allowed values (e.g width and max_width).
+type Reason struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Values  map[string]interface{} `json:"values,omitempty"`
+}
+
+// Error return the reason message
+func (r *Reason) Error() string {
+	return r.Message
+}
+
+// Reject Return a no-ack response with a machine-readable reason.
+func Reject(code, message string, values map[string]interface{}) Response {
+	return NoAck(&Reason{
+		Code:    code,
+		Message: message,
+		Values:  values,
+	})
+}
+
+// Reason return the machine-readable reason of a no-ack response, ok is false if it was not-acknowledged without one.
+func (r Response) Reason() (*Reason, bool) {
+	reason, ok := r.AckErr.(*Reason)
+	return reason, ok
+}
@@ -9,7 +9,7 @@ type Response struct {
 	// processing the payload -, it should not be acknowledged.
 	Ack bool
 
-	// AckErr is why the
+	// AckErr is why the payload was not acknowledged, a *Reason if it's machine-readable.
 	AckErr error
 }
 